@@ -7,7 +7,7 @@ import (
 // ComplianceScanSpec defines the desired state of a ComplianceScan.
 type ComplianceScanSpec struct {
 	// ScanType specifies which scan to run.
-	// +kubebuilder:validation:Enum=cis;rbac;network;pss;full
+	// +kubebuilder:validation:Enum=cis;rbac;network;pss;ingress;workload;secrets;certificates;webhooks;versions;governance;controlplane;full
 	// +kubebuilder:default=full
 	ScanType string `json:"scanType,omitempty"`
 
@@ -7,6 +7,7 @@ import (
 	"flag"
 	"log/slog"
 	"os"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -17,8 +18,10 @@ import (
 
 	v1alpha1 "github.com/kubecomply/kubecomply/api/v1alpha1"
 	"github.com/kubecomply/kubecomply/internal/controller"
+	"github.com/kubecomply/kubecomply/pkg/governance"
 	"github.com/kubecomply/kubecomply/pkg/k8s"
 	"github.com/kubecomply/kubecomply/pkg/policies"
+	"github.com/kubecomply/kubecomply/pkg/pss"
 	"github.com/kubecomply/kubecomply/pkg/saas"
 )
 
@@ -43,6 +46,12 @@ func main() {
 		enableLeaderElection bool
 		policyDir            string
 		saasEndpoint         string
+		readTLSSecrets       bool
+		hostRoot             string
+		ignoredRoles         stringList
+		allowedRegistries    stringList
+		namespaceLabels      stringList
+		podTemplateSources   []pss.PodTemplateSource
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -50,8 +59,30 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager, ensuring only one active controller.")
 	flag.StringVar(&policyDir, "policy-dir", "", "Directory containing OPA/Rego policy files.")
 	flag.StringVar(&saasEndpoint, "saas-endpoint", "", "KubeComply SaaS API endpoint (empty disables SaaS integration).")
+	flag.BoolVar(&readTLSSecrets, "read-tls-secrets", false, "Read kubernetes.io/tls Secrets to check their certificates and key pairs.")
+	flag.Var(&ignoredRoles, "ignore-role", "Additional role names to exclude from unused-role detection (trailing * matches a prefix); repeatable or comma-separated.")
+	flag.StringVar(&hostRoot, "host-root", "", "Path the control plane node's filesystem is mounted at, for encryption and audit policy checks.")
+	flag.Var(&allowedRegistries, "allowed-registry", "Registries or repository prefixes images may come from, for workload checks (default: any); repeatable or comma-separated.")
+	flag.Var(&namespaceLabels, "required-namespace-label", "Labels every namespace must carry, for governance checks (default: "+strings.Join(governance.DefaultRequiredLabels, ",")+"; empty disables); repeatable or comma-separated.")
+	flag.Func("pod-template", "Custom resource pod templates for PSS checks and Secret references as resource.version.group=<jsonpath>; repeatable.", func(v string) error {
+		source, err := pss.ParsePodTemplateSource(v)
+		if err != nil {
+			return err
+		}
+		podTemplateSources = append(podTemplateSources, source)
+		return nil
+	})
 	flag.Parse()
 
+	// Without --required-namespace-label the default labels are required; an
+	// empty value disables the check.
+	requiredLabels := governance.DefaultRequiredLabels
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "required-namespace-label" {
+			requiredLabels = namespaceLabels
+		}
+	})
+
 	// Configure structured logging.
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -105,12 +136,18 @@ func main() {
 
 	// Register the ComplianceScan reconciler.
 	reconciler := &controller.ComplianceScanReconciler{
-		Client:       mgr.GetClient(),
-		Scheme:       mgr.GetScheme(),
-		K8sClient:    k8sClient,
-		PolicyEngine: policyEngine,
-		SaaSClient:   saasClient,
-		Logger:       logger,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		K8sClient:          k8sClient,
+		PolicyEngine:       policyEngine,
+		SaaSClient:         saasClient,
+		Logger:             logger,
+		ReadTLSSecrets:     readTLSSecrets,
+		HostRoot:           hostRoot,
+		IgnoredRoles:       ignoredRoles,
+		AllowedRegistries:  allowedRegistries,
+		RequiredLabels:     requiredLabels,
+		PodTemplateSources: podTemplateSources,
 	}
 
 	if err := reconciler.SetupWithManager(mgr); err != nil {
@@ -139,3 +176,17 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// stringList is a repeatable flag whose values may also be comma-separated.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
@@ -8,14 +8,24 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/kubecomply/kubecomply/pkg/certificates"
+	"github.com/kubecomply/kubecomply/pkg/controlplane"
+	"github.com/kubecomply/kubecomply/pkg/governance"
+	"github.com/kubecomply/kubecomply/pkg/graph"
+	"github.com/kubecomply/kubecomply/pkg/ingress"
 	"github.com/kubecomply/kubecomply/pkg/k8s"
 	"github.com/kubecomply/kubecomply/pkg/network"
 	"github.com/kubecomply/kubecomply/pkg/rbac"
 	"github.com/kubecomply/kubecomply/pkg/report"
 	"github.com/kubecomply/kubecomply/pkg/scanner"
+	"github.com/kubecomply/kubecomply/pkg/secrets"
+	"github.com/kubecomply/kubecomply/pkg/versions"
+	"github.com/kubecomply/kubecomply/pkg/webhooks"
+	"github.com/kubecomply/kubecomply/pkg/workload"
 )
 
 func main() {
@@ -41,6 +51,9 @@ areas with the analyze subcommands.`,
 
 	rootCmd.AddCommand(newScanCmd())
 	rootCmd.AddCommand(newAnalyzeCmd())
+	rootCmd.AddCommand(newRBACCmd())
+	rootCmd.AddCommand(newNetworkCmd())
+	rootCmd.AddCommand(newPSSCmd())
 	rootCmd.AddCommand(newReportCmd())
 	rootCmd.AddCommand(newVersionCmd())
 
@@ -52,22 +65,32 @@ func newAnalyzeCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "analyze",
 		Short: "Run focused analysis on specific areas",
-		Long:  "Analyze specific aspects of cluster compliance. Use subcommands for RBAC or network analysis.",
+		Long:  "Analyze specific aspects of cluster compliance. Use subcommands for RBAC, network, ingress, workload, secrets, certificate, webhook, version, governance or control plane analysis.",
 	}
 
 	cmd.AddCommand(newAnalyzeRBACCmd())
 	cmd.AddCommand(newAnalyzeNetworkCmd())
+	cmd.AddCommand(newAnalyzeIngressCmd())
+	cmd.AddCommand(newAnalyzeWorkloadCmd())
+	cmd.AddCommand(newAnalyzeSecretsCmd())
+	cmd.AddCommand(newAnalyzeCertificatesCmd())
+	cmd.AddCommand(newAnalyzeWebhooksCmd())
+	cmd.AddCommand(newAnalyzeVersionsCmd())
+	cmd.AddCommand(newAnalyzeGovernanceCmd())
+	cmd.AddCommand(newAnalyzeControlPlaneCmd())
 
 	return cmd
 }
 
 func newAnalyzeRBACCmd() *cobra.Command {
 	var (
-		kubeconfig string
-		namespace  string
-		format     string
-		output     string
-		verbose    bool
+		kubeconfig   string
+		namespace    string
+		format       string
+		output       string
+		verbose      bool
+		ignoredRoles []string
+		graphFormat  string
 	)
 
 	cmd := &cobra.Command{
@@ -78,7 +101,13 @@ func newAnalyzeRBACCmd() *cobra.Command {
   - Wildcard permissions in roles
   - Unused roles and ClusterRoles
   - Bindings using the default ServiceAccount
-  - Potential privilege escalation paths`,
+  - Potential privilege escalation paths
+  - Multi-step escalation chains to cluster-admin-equivalent power
+  - ServiceAccount hygiene (unused accounts, token automounting, legacy
+    token Secrets, powerful accounts in internet-exposed workloads)
+
+Use --graph to export the subject -> binding -> role -> rule graph instead
+of findings, with nodes involved in RBAC-001/002/005 findings highlighted.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			logLevel := slog.LevelInfo
 			if verbose {
@@ -102,7 +131,16 @@ func newAnalyzeRBACCmd() *cobra.Command {
 				}
 			}
 
-			analyzer := rbac.NewAnalyzer(k8sClient, logger)
+			analyzer := rbac.NewAnalyzer(k8sClient, logger, rbac.WithUnusedRoleAllowlist(ignoredRoles...))
+
+			if graphFormat != "" {
+				g, err := analyzer.Graph(ctx, namespaces)
+				if err != nil {
+					return fmt.Errorf("building RBAC graph: %w", err)
+				}
+				return outputGraph(cmd, g, graphFormat, output)
+			}
+
 			findings, err := analyzer.Analyze(ctx, namespaces)
 			if err != nil {
 				return fmt.Errorf("RBAC analysis failed: %w", err)
@@ -117,17 +155,20 @@ func newAnalyzeRBACCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format: json, html, table")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	cmd.Flags().StringVar(&graphFormat, "graph", "", "Export the RBAC graph instead of findings: dot, mermaid, json")
+	cmd.Flags().StringSliceVar(&ignoredRoles, "ignore-role", nil, "Additional role names to exclude from unused-role detection (trailing * matches a prefix)")
 
 	return cmd
 }
 
 func newAnalyzeNetworkCmd() *cobra.Command {
 	var (
-		kubeconfig string
-		namespace  string
-		format     string
-		output     string
-		verbose    bool
+		kubeconfig  string
+		namespace   string
+		format      string
+		output      string
+		verbose     bool
+		graphFormat string
 	)
 
 	cmd := &cobra.Command{
@@ -137,7 +178,27 @@ func newAnalyzeNetworkCmd() *cobra.Command {
   - Namespaces with no NetworkPolicies
   - Missing default-deny policies
   - Incomplete ingress/egress coverage
-  - Exposed NodePort and LoadBalancer services`,
+  - Exposed NodePort and LoadBalancer services
+  - Pods not selected by any NetworkPolicy in otherwise covered namespaces
+  - Rules allowing 0.0.0.0/0 or ::/0, all peers, or all ports
+  - Workloads whose effective egress includes the public internet
+  - Policies selecting no pods, shadowed by broader policies, or using
+    namespaceSelectors that match no namespace
+  - LoadBalancers without loadBalancerSourceRanges, externalIPs
+    (CVE-2020-8554) and externalTrafficPolicy pitfalls
+  - Services in front of pods without ingress isolation
+  - An inventory of internet-exposed workloads correlating Service,
+    Ingress and HTTPRoute paths with their TLS and NetworkPolicy state
+
+CiliumNetworkPolicy, CiliumClusterwideNetworkPolicy and Calico
+GlobalNetworkPolicy resources are included when their CRDs are installed.
+
+Use --graph to export a namespace/workload segmentation diagram instead of
+findings: edges are connections NetworkPolicies allow, labelled with ports,
+and workloads are coloured by coverage (green: ingress and egress isolated,
+yellow: one direction, red: no policy).
+
+Use "kubecomply network can-reach" to query pod-to-pod reachability.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			logLevel := slog.LevelInfo
 			if verbose {
@@ -162,6 +223,15 @@ func newAnalyzeNetworkCmd() *cobra.Command {
 			}
 
 			analyzer := network.NewAnalyzer(k8sClient, logger)
+
+			if graphFormat != "" {
+				g, err := analyzer.Graph(ctx, namespaces)
+				if err != nil {
+					return fmt.Errorf("building network graph: %w", err)
+				}
+				return outputGraph(cmd, g, graphFormat, output)
+			}
+
 			findings, err := analyzer.Analyze(ctx, namespaces)
 			if err != nil {
 				return fmt.Errorf("network analysis failed: %w", err)
@@ -176,10 +246,373 @@ func newAnalyzeNetworkCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format: json, html, table")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	cmd.Flags().StringVar(&graphFormat, "graph", "", "Export the network segmentation graph instead of findings: dot, mermaid, json")
+
+	return cmd
+}
+
+func newAnalyzeIngressCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: true}
+
+	cmd := &cobra.Command{
+		Use:   "ingress",
+		Short: "Analyze Ingress and Gateway API resources",
+		Long: `Analyze networking.k8s.io Ingresses and Gateway API Gateways and HTTPRoutes
+to identify:
+  - Hosts and HTTPRoutes served without TLS
+  - Wildcard and catch-all hostnames
+  - TLS Secrets that do not exist
+  - Controller annotations that inject raw configuration, such as
+    nginx.ingress.kubernetes.io/configuration-snippet
+  - Gateway listeners accepting routes from all namespaces and HTTPRoutes
+    attaching to Gateways or Services in other namespaces
+
+Gateway API resources are analyzed when the CRDs are installed. Only Secret
+metadata is read, so certificate expiry is not checked here: run
+"analyze certificates --read-tls-secrets" (or "scan --read-tls-secrets") to
+report expired (CERT-001) and expiring (CERT-002) Ingress and Gateway
+certificates.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, true, func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return ingress.NewAnalyzer(client, logger)
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+
+	return cmd
+}
+
+func newAnalyzeWorkloadCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: true}
+	var allowedRegistries []string
+
+	cmd := &cobra.Command{
+		Use:   "workload",
+		Short: "Analyze workloads for resource, probe and image best practices",
+		Long: `Analyze the pod templates of Deployments, DaemonSets, StatefulSets, CronJobs,
+standalone Jobs and unowned pods to identify:
+  - Containers without CPU/memory requests or limits
+  - Long-running containers without liveness or readiness probes
+  - Images using the latest tag or no tag, or not pinned by digest
+  - Images from registries outside --allowed-registry
+  - imagePullPolicy values that contradict the image reference
+
+Examples:
+  kubecomply analyze workload -n payments
+  kubecomply analyze workload --allowed-registry registry.example.com,ghcr.io/example`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, true, func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return workload.NewAnalyzer(client, logger, workload.WithAllowedRegistries(allowedRegistries...))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().StringSliceVar(&allowedRegistries, "allowed-registry", nil, "Registries or repository prefixes images may come from (default: any)")
+
+	return cmd
+}
+
+func newAnalyzeSecretsCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: true}
+	var podTemplates []string
+
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Analyze Secret exposure, ConfigMap credentials and Secret access",
+		Long: `Analyze Secrets, ConfigMaps and RBAC bindings to identify:
+  - Secrets passed to containers as environment variables instead of volumes
+  - ConfigMap values that look like credentials: private keys, cloud and
+    SaaS key formats, and high-entropy strings
+  - Secrets not referenced by any pod, workload, ServiceAccount, Ingress or
+    Gateway; workloads include those scaled to zero and pod templates of
+    custom resources (Argo Rollouts and Knative Services by default, more
+    with --pod-template). Namespaces where a reference source could not be
+    listed are skipped
+  - Secrets readable by broad subjects such as system:authenticated, all
+    ServiceAccounts or default ServiceAccounts
+
+Secret values are never included in findings or reports.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateSources, err := parsePodTemplateSources(podTemplates)
+			if err != nil {
+				return err
+			}
+			return runAnalyzer(cmd, flags, true, func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return secrets.NewAnalyzer(client, logger, secrets.WithPodTemplateSources(templateSources...))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().StringArrayVar(&podTemplates, "pod-template", nil, "Custom resource pod templates as resource.version.group=<jsonpath> (repeatable)")
+
+	return cmd
+}
+
+func newAnalyzeCertificatesCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: true}
+	var expiryWindows []time.Duration
+	var readTLSSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "certificates",
+		Short: "Analyze TLS certificates in Secrets and webhook CA bundles",
+		Long: `Parse the X.509 certificates in the caBundles of mutating and validating
+admission webhooks and, with --read-tls-secrets, in kubernetes.io/tls Secrets
+to identify:
+  - Expired certificates, and certificates expiring within --expiry-window
+    (the shortest window is reported at high severity, the next at medium)
+  - RSA keys under 2048 bits and ECDSA keys under 256 bits
+  - SHA-1 and MD5 signatures
+  - tls.key values that do not belong to the leaf certificate in tls.crt
+
+Reading TLS Secrets is opt-in because it reads Secret data: the full Secrets,
+including tls.key, are fetched, and only tls.crt and the public key derived
+from tls.key are kept. Findings name the Secret together with the
+Ingresses, Gateways and webhook configurations that use it. Only certificate
+metadata is reported.
+
+Examples:
+  kubecomply analyze certificates
+  kubecomply analyze certificates --read-tls-secrets --expiry-window 2160h,720h,168h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, true, func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return certificates.NewAnalyzer(client, logger,
+					certificates.WithExpiryWindows(expiryWindows...),
+					certificates.WithTLSSecrets(readTLSSecrets))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().DurationSliceVar(&expiryWindows, "expiry-window", certificates.DefaultExpiryWindows, "Report certificates expiring within these durations")
+	cmd.Flags().BoolVar(&readTLSSecrets, "read-tls-secrets", false, "Read kubernetes.io/tls Secrets to check their certificates and key pairs")
+
+	return cmd
+}
+
+func newAnalyzeWebhooksCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: false}
+	var maxTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "Analyze admission webhook configurations",
+		Long: `Analyze MutatingWebhookConfigurations and ValidatingWebhookConfigurations to
+identify:
+  - Webhooks on pods, workloads, Secrets or RBAC, and Gatekeeper, Kyverno,
+    Kubewarden or jsPolicy webhooks, that use failurePolicy Ignore and fail open
+  - Fail-closed webhooks intercepting kube-system or their own namespace,
+    which can deadlock the cluster when the webhook is down
+  - Webhooks without a namespaceSelector
+  - Timeouts above --max-timeout
+  - Webhook Services in namespaces no NetworkPolicy, Cilium or Calico policy
+    applies to
+
+Webhook configurations are cluster-scoped, so every webhook is analyzed.
+
+Examples:
+  kubecomply analyze webhooks
+  kubecomply analyze webhooks --max-timeout 5s`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, true, func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return webhooks.NewAnalyzer(client, logger, webhooks.WithMaxTimeout(maxTimeout))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().DurationVar(&maxTimeout, "max-timeout", webhooks.DefaultMaxTimeout, "Report webhooks whose timeout exceeds this duration")
+
+	return cmd
+}
+
+func newAnalyzeVersionsCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: false}
+	var (
+		manifests     []string
+		targetVersion string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "Analyze Kubernetes version support and API deprecations",
+		Long: `Check the cluster's Kubernetes version to identify:
+  - Releases past, or within 60 days of, the end of upstream support
+  - Kubelets newer than the API server or further behind it than the
+    version skew policy allows
+
+With --manifests, check YAML and JSON manifests instead for apiVersions that
+are deprecated or removed in --target-version (default: the cluster's
+version), naming the replacement apiVersion. No cluster is needed when
+--target-version is set.
+
+Examples:
+  kubecomply analyze versions
+  kubecomply analyze versions --manifests ./deploy --target-version 1.32`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, len(manifests) == 0 || targetVersion == "", func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return versions.NewAnalyzer(client, logger,
+					versions.WithManifests(manifests...),
+					versions.WithTargetVersion(targetVersion))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().StringSliceVar(&manifests, "manifests", nil, "Manifest files or directories to check for deprecated API versions")
+	cmd.Flags().StringVar(&targetVersion, "target-version", "", "Kubernetes version to check manifests against, e.g. 1.32 (default: the cluster's version)")
+
+	return cmd
+}
+
+func newAnalyzeGovernanceCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: true}
+	var requiredLabels []string
+
+	cmd := &cobra.Command{
+		Use:   "governance",
+		Short: "Analyze namespace quotas, limits, labels and idle access",
+		Long: `Check every non-system namespace to identify:
+  - Namespaces without a ResourceQuota
+  - Namespaces without a LimitRange
+  - Namespaces missing the labels given by --required-namespace-label
+  - Namespaces with RoleBindings but no workloads
+
+Examples:
+  kubecomply analyze governance
+  kubecomply analyze governance --required-namespace-label team,cost-center`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, true, func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return governance.NewAnalyzer(client, logger, governance.WithRequiredLabels(requiredLabels...))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().StringSliceVar(&requiredLabels, "required-namespace-label", governance.DefaultRequiredLabels, "Labels every namespace must carry")
 
 	return cmd
 }
 
+func newAnalyzeControlPlaneCmd() *cobra.Command {
+	flags := &analyzeFlags{namespaced: false}
+	var (
+		hostRoot         string
+		encryptionConfig string
+		auditPolicy      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "controlplane",
+		Short: "Analyze API server encryption at rest and audit policy",
+		Long: `Read the kube-apiserver's EncryptionConfiguration and audit Policy to identify:
+  - identity as the first provider, or no entry for secrets
+  - aescbc, aesgcm or secretbox keys stored on the node instead of a KMS,
+    and the deprecated KMS v1 API
+  - Secret access audited at None, or at a level that logs Secret values
+  - RBAC changes audited below RequestResponse
+  - Omitted ResponseComplete or Panic stages
+
+The file paths come from the API server's --encryption-provider-config and
+--audit-policy-file flags, read from its static pod, and are resolved under
+--host-root. Run on a control plane node with --host-root /, or in a pod
+with the node's /etc/kubernetes mounted. Without a host root or explicit
+files nothing is reported; unset flags are covered by the CIS checks.
+Managed control planes do not expose these files. No cluster is needed when
+both --encryption-config and --audit-policy are set.
+
+Examples:
+  kubecomply analyze controlplane --host-root /
+  kubecomply analyze controlplane --encryption-config /etc/kubernetes/enc.yaml --audit-policy /etc/kubernetes/audit-policy.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAnalyzer(cmd, flags, encryptionConfig == "" || auditPolicy == "", func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer {
+				return controlplane.NewAnalyzer(client, logger,
+					controlplane.WithHostRoot(hostRoot),
+					controlplane.WithEncryptionConfig(encryptionConfig),
+					controlplane.WithAuditPolicy(auditPolicy))
+			})
+		},
+	}
+
+	addAnalyzeFlags(cmd, flags)
+	cmd.Flags().StringVar(&hostRoot, "host-root", "", "Path the control plane node's filesystem is mounted at")
+	cmd.Flags().StringVar(&encryptionConfig, "encryption-config", "", "EncryptionConfiguration file (default: from --encryption-provider-config)")
+	cmd.Flags().StringVar(&auditPolicy, "audit-policy", "", "Audit Policy file (default: from --audit-policy-file)")
+
+	return cmd
+}
+
+// analyzeFlags are the flags shared by the analyze subcommands that run a
+// single analyzer.
+type analyzeFlags struct {
+	kubeconfig string
+	namespace  string
+	format     string
+	output     string
+	verbose    bool
+	// namespaced is set for analyzers that accept --namespace. The others
+	// analyze cluster-scoped resources and are given no namespaces.
+	namespaced bool
+}
+
+// addAnalyzeFlags registers the shared analyze flags on cmd.
+func addAnalyzeFlags(cmd *cobra.Command, flags *analyzeFlags) {
+	cmd.Flags().StringVar(&flags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	if flags.namespaced {
+		cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace to analyze (default: all)")
+	}
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "table", "Output format: json, html, table")
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "Output file path")
+	cmd.Flags().BoolVarP(&flags.verbose, "verbose", "v", false, "Enable verbose output")
+}
+
+// runAnalyzer runs the analyzer built by newAnalyzer and outputs its
+// findings. When connect is false no Kubernetes client is created and
+// newAnalyzer is given a nil client.
+func runAnalyzer(cmd *cobra.Command, flags *analyzeFlags, connect bool, newAnalyzer func(client *k8s.Client, logger *slog.Logger) scanner.Analyzer) error {
+	logLevel := slog.LevelInfo
+	if flags.verbose {
+		logLevel = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+	ctx := cmd.Context()
+	var (
+		k8sClient   *k8s.Client
+		clusterName string
+		namespaces  []string
+	)
+	if connect {
+		var err error
+		k8sClient, err = k8s.NewClient(resolveKubeconfig(flags.kubeconfig), logger)
+		if err != nil {
+			return fmt.Errorf("creating Kubernetes client: %w", err)
+		}
+		clusterName = k8sClient.ClusterName()
+
+		if flags.namespace != "" {
+			namespaces = []string{flags.namespace}
+		} else if flags.namespaced {
+			namespaces, err = k8sClient.NamespacesForScan(ctx, nil, false)
+			if err != nil {
+				return fmt.Errorf("resolving namespaces: %w", err)
+			}
+		}
+	}
+
+	analyzer := newAnalyzer(k8sClient, logger)
+	findings, err := analyzer.Analyze(ctx, namespaces)
+	if err != nil {
+		return fmt.Errorf("%s analysis failed: %w", analyzer.Name(), err)
+	}
+
+	return outputFindings(cmd, findings, clusterName, analyzer.Name(), flags.format, flags.output)
+}
+
 // newReportCmd creates the `report` command for generating reports from
 // previously saved scan results.
 func newReportCmd() *cobra.Command {
@@ -290,3 +723,22 @@ func outputFindings(cmd *cobra.Command, findings []scanner.Finding, clusterName,
 
 	return reporter.Generate(writer, result)
 }
+
+func outputGraph(cmd *cobra.Command, g *graph.Graph, format, output string) error {
+	graphFormat, err := graph.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	writer := cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		writer = f
+	}
+
+	return graph.Write(writer, g, graphFormat)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/network"
+)
+
+// newNetworkCmd creates the `network` command with NetworkPolicy tooling subcommands.
+func newNetworkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "NetworkPolicy tooling beyond analysis",
+		Long:  "Tools for reasoning about NetworkPolicies, such as pod-to-pod reachability queries and default-deny policy generation.",
+	}
+
+	cmd.AddCommand(newNetworkCanReachCmd())
+	cmd.AddCommand(newNetworkGenerateCmd())
+
+	return cmd
+}
+
+func newNetworkCanReachCmd() *cobra.Command {
+	var (
+		kubeconfig string
+		port       int32
+		protocol   string
+		format     string
+		verbose    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "can-reach <namespace/pod> <namespace/pod>",
+		Short: "Check whether one pod can reach another",
+		Long: `Simulate NetworkPolicy enforcement between two pods. Egress policies selecting
+the source pod and ingress policies selecting the destination pod are
+evaluated, including podSelectors, namespaceSelectors, ipBlocks, ports and
+policyTypes. The policies and rules that allow or deny the connection are
+printed as the explanation.
+
+The command exits with a non-zero status when the connection is denied.
+
+Examples:
+  kubecomply network can-reach frontend/web-0 backend/api-0 --port 443
+  kubecomply network can-reach default/client kube-system/coredns-abc --port 53 --protocol UDP`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcNS, srcName, err := parsePodRef(args[0])
+			if err != nil {
+				return err
+			}
+			dstNS, dstName, err := parsePodRef(args[1])
+			if err != nil {
+				return err
+			}
+
+			proto := corev1.Protocol(strings.ToUpper(protocol))
+			switch proto {
+			case corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP:
+			default:
+				return fmt.Errorf("unsupported protocol: %q (valid: TCP, UDP, SCTP)", protocol)
+			}
+
+			logLevel := slog.LevelWarn
+			if verbose {
+				logLevel = slog.LevelDebug
+			}
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+			k8sClient, err := k8s.NewClient(resolveKubeconfig(kubeconfig), logger)
+			if err != nil {
+				return fmt.Errorf("creating Kubernetes client: %w", err)
+			}
+
+			analyzer := network.NewAnalyzer(k8sClient, logger)
+			engine, err := analyzer.Reachability(cmd.Context(), []string{srcNS, dstNS})
+			if err != nil {
+				return fmt.Errorf("building reachability model: %w", err)
+			}
+
+			src := engine.FindPod(srcNS, srcName)
+			if src == nil {
+				return fmt.Errorf("pod %s/%s not found", srcNS, srcName)
+			}
+			dst := engine.FindPod(dstNS, dstName)
+			if dst == nil {
+				return fmt.Errorf("pod %s/%s not found", dstNS, dstName)
+			}
+
+			verdict := engine.CanReach(src, dst, port, proto)
+
+			switch format {
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(verdict); err != nil {
+					return fmt.Errorf("encoding verdict: %w", err)
+				}
+			case "text":
+				for _, line := range verdict.Explain() {
+					fmt.Fprintln(cmd.OutOrStdout(), line)
+				}
+			default:
+				return fmt.Errorf("unsupported format: %q (valid: text, json)", format)
+			}
+
+			if !verdict.Allowed {
+				return fmt.Errorf("connection from %s to %s is denied", verdict.Source, verdict.Destination)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	cmd.Flags().Int32Var(&port, "port", 0, "Destination port (default: any port)")
+	cmd.Flags().StringVar(&protocol, "protocol", "TCP", "Protocol: TCP, UDP, SCTP")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format: text, json")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+
+	return cmd
+}
+
+func newNetworkGenerateCmd() *cobra.Command {
+	var (
+		kubeconfig string
+		namespace  string
+		flowLog    string
+		output     string
+		verbose    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate default-deny and allow-list NetworkPolicies",
+		Long: `Generate NetworkPolicies that put a namespace under default-deny without
+breaking known traffic. The output contains:
+  - A default-deny policy for ingress and egress
+  - An egress rule allowing cluster DNS
+  - Allow rules inferred from observed connections in --flow-log
+  - Ingress rules for Services whose pods had no observed traffic
+
+The flow log is a JSON array, or one JSON object per line, of connections:
+  {"source": {"namespace": "web", "pod": "frontend-7d9f-abc"},
+   "destination": {"namespace": "api", "labels": {"app": "api"}},
+   "port": 8080, "protocol": "TCP"}
+Endpoints may be identified by pod name, labels or IP; IPs that belong to
+no pod become ipBlock rules.
+
+Examples:
+  kubecomply network generate -n payments
+  kubecomply network generate -n payments --flow-log flows.json -o payments-netpol.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if namespace == "" {
+				return fmt.Errorf("--namespace is required")
+			}
+
+			logLevel := slog.LevelInfo
+			if verbose {
+				logLevel = slog.LevelDebug
+			}
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+			var flows []network.Flow
+			if flowLog != "" {
+				f, err := os.Open(flowLog)
+				if err != nil {
+					return fmt.Errorf("opening flow log: %w", err)
+				}
+				flows, err = network.ParseFlowLog(f)
+				f.Close()
+				if err != nil {
+					return err
+				}
+			}
+
+			k8sClient, err := k8s.NewClient(resolveKubeconfig(kubeconfig), logger)
+			if err != nil {
+				return fmt.Errorf("creating Kubernetes client: %w", err)
+			}
+
+			analyzer := network.NewAnalyzer(k8sClient, logger)
+			generated, err := analyzer.Generate(cmd.Context(), namespace, flows)
+			if err != nil {
+				return fmt.Errorf("generating network policies: %w", err)
+			}
+			for _, skipped := range generated.Skipped {
+				logger.Warn("skipped flow", "reason", skipped)
+			}
+
+			writer := cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("creating output file: %w", err)
+				}
+				defer f.Close()
+				writer = f
+			}
+			return generated.WriteYAML(writer)
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to generate policies for (required)")
+	cmd.Flags().StringVar(&flowLog, "flow-log", "", "Path to a JSON file of observed connections")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file for the generated YAML (default: stdout)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+
+	return cmd
+}
+
+// parsePodRef splits a "namespace/name" argument. A bare name refers to a
+// pod in the default namespace.
+func parsePodRef(ref string) (string, string, error) {
+	ns, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		ns, name = "default", ref
+	}
+	if ns == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid pod reference %q: expected <namespace>/<pod>", ref)
+	}
+	return ns, name, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/pss"
+)
+
+// newPSSCmd creates the `pss` command with Pod Security Standards tooling subcommands.
+func newPSSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pss",
+		Short: "Pod Security Standards tooling beyond analysis",
+		Long:  "Tools for rolling out Pod Security Admission, such as dry-running an enforce level against current workloads.",
+	}
+
+	cmd.AddCommand(newPSSDryRunCmd())
+
+	return cmd
+}
+
+func newPSSDryRunCmd() *cobra.Command {
+	var (
+		kubeconfig   string
+		namespace    string
+		level        string
+		version      string
+		podTemplates []string
+		format       string
+		output       string
+		verbose      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dry-run",
+		Short: "Show what enforcing a Pod Security Standards level would reject",
+		Long: `Evaluate namespaces as if pod-security.kubernetes.io/enforce were set to the
+given level. Pod templates of Deployments, DaemonSets, StatefulSets, Jobs,
+CronJobs, standalone ReplicaSets, ReplicationControllers and custom resources
+(Argo Rollouts and Knative Services by default, more with --pod-template) are
+checked, running pods are attributed to their top-level controller, and every
+violation is listed per workload and container with a fix. With --version
+v1.<minor>, the controls and safe sysctls of that version of the standard are
+used, matching a pinned pod-security.kubernetes.io/enforce-version label.
+
+Each namespace gets a readiness verdict. The command exits with a non-zero
+status when any namespace is not ready.
+
+Examples:
+  kubecomply pss dry-run -n payments
+  kubecomply pss dry-run --level baseline -f json
+  kubecomply pss dry-run --version v1.28`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateSources, err := parsePodTemplateSources(podTemplates)
+			if err != nil {
+				return err
+			}
+
+			logLevel := slog.LevelWarn
+			if verbose {
+				logLevel = slog.LevelDebug
+			}
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+			k8sClient, err := k8s.NewClient(resolveKubeconfig(kubeconfig), logger)
+			if err != nil {
+				return fmt.Errorf("creating Kubernetes client: %w", err)
+			}
+
+			ctx := cmd.Context()
+			var namespaces []string
+			if namespace != "" {
+				namespaces = []string{namespace}
+			} else {
+				namespaces, err = k8sClient.NamespacesForScan(ctx, nil, false)
+				if err != nil {
+					return fmt.Errorf("resolving namespaces: %w", err)
+				}
+			}
+
+			checker := pss.NewChecker(k8sClient, logger, pss.WithPodTemplateSources(templateSources...))
+			report, err := checker.DryRun(ctx, namespaces, pss.Profile(strings.ToLower(level)), version)
+			if err != nil {
+				return err
+			}
+
+			writer := cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("creating output file: %w", err)
+				}
+				defer f.Close()
+				writer = f
+			}
+
+			switch format {
+			case "json":
+				enc := json.NewEncoder(writer)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return fmt.Errorf("encoding report: %w", err)
+				}
+			case "text":
+				if err := report.WriteText(writer); err != nil {
+					return fmt.Errorf("writing report: %w", err)
+				}
+			default:
+				return fmt.Errorf("unsupported format: %q (valid: text, json)", format)
+			}
+
+			if notReady := report.NotReady(); len(notReady) > 0 {
+				return fmt.Errorf("%d namespace(s) not ready for enforce=%s: %s", len(notReady), report.Level, strings.Join(notReady, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to evaluate (default: all)")
+	cmd.Flags().StringVar(&level, "level", string(pss.ProfileRestricted), "Level to dry-run: baseline, restricted")
+	cmd.Flags().StringVar(&version, "version", "latest", "Pod Security Standards version: latest or v1.<minor>")
+	cmd.Flags().StringArrayVar(&podTemplates, "pod-template", nil, "Custom resource pod templates as resource.version.group=<jsonpath> (repeatable)")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format: text, json")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+
+	return cmd
+}
+
+// parsePodTemplateSources parses --pod-template flag values.
+func parsePodTemplateSources(values []string) ([]pss.PodTemplateSource, error) {
+	sources := make([]pss.PodTemplateSource, 0, len(values))
+	for _, v := range values {
+		source, err := pss.ParsePodTemplateSource(v)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
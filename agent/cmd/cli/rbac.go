@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/rbac"
+	"github.com/kubecomply/kubecomply/pkg/report"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// newRBACCmd creates the `rbac` command with RBAC tooling subcommands.
+func newRBACCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rbac",
+		Short: "RBAC tooling beyond analysis",
+		Long:  "Tools that help tighten RBAC, such as deriving least-privilege roles from audit logs.",
+	}
+
+	cmd.AddCommand(newRBACSuggestCmd())
+
+	return cmd
+}
+
+func newRBACSuggestCmd() *cobra.Command {
+	var (
+		kubeconfig string
+		namespace  string
+		auditLog   string
+		subject    string
+		format     string
+		output     string
+		reportPath string
+		verbose    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Suggest a least-privilege Role from audit logs",
+		Long: `Compute the verbs and resources a subject actually used from a local
+Kubernetes audit log (JSON lines), compare them with the subject's effective
+RBAC permissions, report unused grants, and emit a minimal Role/ClusterRole.
+
+The generated YAML is written to stdout (or --output); the unused-grant report
+is written to stderr (or --report).
+
+Examples:
+  kubecomply rbac suggest --audit-log audit.json --subject system:serviceaccount:ci:deployer
+  kubecomply rbac suggest --audit-log audit.json --subject alice@example.com -o role.yaml --report unused.json --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if auditLog == "" {
+				return fmt.Errorf("--audit-log is required: provide a path to a JSON-lines audit log")
+			}
+			if subject == "" {
+				return fmt.Errorf("--subject is required (e.g. system:serviceaccount:<namespace>:<name>)")
+			}
+
+			logLevel := slog.LevelInfo
+			if verbose {
+				logLevel = slog.LevelDebug
+			}
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+			reportFormat, err := report.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(auditLog)
+			if err != nil {
+				return fmt.Errorf("opening audit log: %w", err)
+			}
+			usage, err := rbac.ParseAuditLog(f, subject)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			if usage.Events == 0 {
+				logger.Warn("no audit events found for subject", "subject", subject)
+			}
+
+			k8sClient, err := k8s.NewClient(resolveKubeconfig(kubeconfig), logger)
+			if err != nil {
+				return fmt.Errorf("creating Kubernetes client: %w", err)
+			}
+
+			ctx := cmd.Context()
+			var namespaces []string
+			if namespace != "" {
+				namespaces = []string{namespace}
+			} else {
+				namespaces, err = k8sClient.NamespacesForScan(ctx, nil, true)
+				if err != nil {
+					return fmt.Errorf("resolving namespaces: %w", err)
+				}
+			}
+
+			analyzer := rbac.NewAnalyzer(k8sClient, logger)
+			suggestion, err := analyzer.SuggestLeastPrivilege(ctx, namespaces, usage)
+			if err != nil {
+				return fmt.Errorf("computing least-privilege suggestion: %w", err)
+			}
+
+			// Write the unused-grant report.
+			reporter, err := report.NewReporter(reportFormat)
+			if err != nil {
+				return err
+			}
+			result := &scanner.ScanResult{
+				ScanType:    "rbac",
+				ClusterName: k8sClient.ClusterName(),
+				Findings:    suggestion.Findings,
+			}
+			result.ComputeSummary()
+
+			reportWriter := cmd.ErrOrStderr()
+			if reportPath != "" {
+				rf, err := os.Create(reportPath)
+				if err != nil {
+					return fmt.Errorf("creating report file: %w", err)
+				}
+				defer rf.Close()
+				reportWriter = rf
+			}
+			if err := reporter.Generate(reportWriter, result); err != nil {
+				return err
+			}
+
+			// Write the suggested roles.
+			writer := cmd.OutOrStdout()
+			if output != "" {
+				of, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("creating output file: %w", err)
+				}
+				defer of.Close()
+				writer = of
+			}
+			return suggestion.WriteYAML(writer)
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Only consider RoleBindings in this namespace (default: all)")
+	cmd.Flags().StringVar(&auditLog, "audit-log", "", "Path to a Kubernetes audit log in JSON-lines format")
+	cmd.Flags().StringVar(&subject, "subject", "", "Username to analyze, e.g. system:serviceaccount:<namespace>:<name>")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Report format: json, html, table")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file for the generated Role/ClusterRole YAML (default: stdout)")
+	cmd.Flags().StringVar(&reportPath, "report", "", "Output file for the unused-grant report (default: stderr)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+
+	return cmd
+}
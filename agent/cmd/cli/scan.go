@@ -5,9 +5,14 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/kubecomply/kubecomply/pkg/certificates"
+	"github.com/kubecomply/kubecomply/pkg/controlplane"
+	"github.com/kubecomply/kubecomply/pkg/governance"
+	"github.com/kubecomply/kubecomply/pkg/ingress"
 	"github.com/kubecomply/kubecomply/pkg/k8s"
 	"github.com/kubecomply/kubecomply/pkg/network"
 	"github.com/kubecomply/kubecomply/pkg/policies"
@@ -15,6 +20,10 @@ import (
 	"github.com/kubecomply/kubecomply/pkg/rbac"
 	"github.com/kubecomply/kubecomply/pkg/report"
 	"github.com/kubecomply/kubecomply/pkg/scanner"
+	"github.com/kubecomply/kubecomply/pkg/secrets"
+	"github.com/kubecomply/kubecomply/pkg/versions"
+	"github.com/kubecomply/kubecomply/pkg/webhooks"
+	"github.com/kubecomply/kubecomply/pkg/workload"
 )
 
 type scanFlags struct {
@@ -25,6 +34,13 @@ type scanFlags struct {
 	severityThreshold string
 	kubeconfig        string
 	policyPaths       []string
+	ignoredRoles      []string
+	podTemplates      []string
+	allowedRegistries []string
+	requiredLabels    []string
+	expiryWindows     []time.Duration
+	readTLSSecrets    bool
+	hostRoot          string
 	verbose           bool
 }
 
@@ -37,11 +53,19 @@ func newScanCmd() *cobra.Command {
 		Long: `Run a compliance scan against the connected Kubernetes cluster.
 
 Scan types:
-  full      Run all checks (CIS, RBAC, Network, PSS)
-  cis       CIS Kubernetes Benchmark checks via OPA policies
-  rbac      RBAC security analysis
-  network   NetworkPolicy coverage analysis
-  pss       Pod Security Standards evaluation
+  full         Run all checks: CIS and every analyzer below
+  cis          CIS Kubernetes Benchmark checks via OPA policies
+  rbac         RBAC security analysis
+  network      NetworkPolicy coverage analysis
+  pss          Pod Security Standards evaluation
+  ingress      Ingress and Gateway API security analysis
+  workload     Resource, probe and image best practices
+  secrets      Secret exposure, ConfigMap credentials and Secret access
+  certificates TLS Secret and webhook CA certificate checks
+  webhooks     Admission webhook configuration checks
+  versions     Kubernetes version support and kubelet skew checks
+  governance   Namespace quotas, limits, ownership labels and idle access
+  controlplane API server encryption at rest and audit policy configuration
 
 Examples:
   kubecomply scan
@@ -55,11 +79,18 @@ Examples:
 
 	cmd.Flags().StringVarP(&flags.format, "format", "f", "table", "Output format: json, html, table")
 	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "Output file path (default: stdout)")
-	cmd.Flags().StringVar(&flags.scanType, "scan-type", "full", "Scan type: cis, rbac, network, pss, full")
+	cmd.Flags().StringVar(&flags.scanType, "scan-type", "full", "Scan type: cis, rbac, network, pss, ingress, workload, secrets, certificates, webhooks, versions, governance, controlplane, full")
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace to scan (default: all namespaces)")
 	cmd.Flags().StringVar(&flags.severityThreshold, "severity-threshold", "info", "Minimum severity to report: critical, high, medium, low, info")
 	cmd.Flags().StringVar(&flags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
 	cmd.Flags().StringSliceVar(&flags.policyPaths, "policy-path", nil, "Additional policy directory paths")
+	cmd.Flags().StringSliceVar(&flags.ignoredRoles, "ignore-role", nil, "Additional role names to exclude from unused-role detection, for RBAC checks (trailing * matches a prefix)")
+	cmd.Flags().StringArrayVar(&flags.podTemplates, "pod-template", nil, "Custom resource pod templates for PSS checks and Secret references as resource.version.group=<jsonpath>, e.g. rollouts.v1alpha1.argoproj.io={.spec.template} (repeatable)")
+	cmd.Flags().StringSliceVar(&flags.allowedRegistries, "allowed-registry", nil, "Registries or repository prefixes images may come from, for workload checks (default: any)")
+	cmd.Flags().StringSliceVar(&flags.requiredLabels, "required-namespace-label", governance.DefaultRequiredLabels, "Labels every namespace must carry, for governance checks")
+	cmd.Flags().DurationSliceVar(&flags.expiryWindows, "expiry-window", certificates.DefaultExpiryWindows, "Report certificates expiring within these durations, for certificate checks")
+	cmd.Flags().BoolVar(&flags.readTLSSecrets, "read-tls-secrets", false, "Read kubernetes.io/tls Secrets, for certificate and key pair checks (webhook caBundles are always checked)")
+	cmd.Flags().StringVar(&flags.hostRoot, "host-root", "", "Path the control plane node's filesystem is mounted at, for encryption and audit policy checks")
 	cmd.Flags().BoolVarP(&flags.verbose, "verbose", "v", false, "Enable verbose output")
 
 	return cmd
@@ -102,10 +133,15 @@ func runScan(cmd *cobra.Command, flags *scanFlags) error {
 
 	// Validate scan type.
 	validScanTypes := map[string]bool{
-		"full": true, "cis": true, "rbac": true, "network": true, "pss": true,
+		"full": true, "cis": true, "rbac": true, "network": true, "pss": true, "ingress": true, "workload": true, "secrets": true, "certificates": true, "webhooks": true, "versions": true, "governance": true, "controlplane": true,
 	}
 	if !validScanTypes[flags.scanType] {
-		return fmt.Errorf("invalid scan type: %q (valid: full, cis, rbac, network, pss)", flags.scanType)
+		return fmt.Errorf("invalid scan type: %q (valid: full, cis, rbac, network, pss, ingress, workload, secrets, certificates, webhooks, versions, governance, controlplane)", flags.scanType)
+	}
+
+	templateSources, err := parsePodTemplateSources(flags.podTemplates)
+	if err != nil {
+		return err
 	}
 
 	// Create Kubernetes client.
@@ -140,9 +176,19 @@ func runScan(cmd *cobra.Command, flags *scanFlags) error {
 	ctx := cmd.Context()
 	s := scanner.New(k8sClient, logger)
 	s.SetPolicyEvaluator(engine)
-	s.RegisterAnalyzer(rbac.NewAnalyzer(k8sClient, logger))
+	s.RegisterAnalyzer(rbac.NewAnalyzer(k8sClient, logger, rbac.WithUnusedRoleAllowlist(flags.ignoredRoles...)))
 	s.RegisterAnalyzer(network.NewAnalyzer(k8sClient, logger))
-	s.RegisterAnalyzer(pss.NewChecker(k8sClient, logger))
+	s.RegisterAnalyzer(pss.NewChecker(k8sClient, logger, pss.WithPodTemplateSources(templateSources...)))
+	s.RegisterAnalyzer(ingress.NewAnalyzer(k8sClient, logger))
+	s.RegisterAnalyzer(workload.NewAnalyzer(k8sClient, logger, workload.WithAllowedRegistries(flags.allowedRegistries...)))
+	s.RegisterAnalyzer(secrets.NewAnalyzer(k8sClient, logger, secrets.WithPodTemplateSources(templateSources...)))
+	s.RegisterAnalyzer(certificates.NewAnalyzer(k8sClient, logger,
+		certificates.WithExpiryWindows(flags.expiryWindows...),
+		certificates.WithTLSSecrets(flags.readTLSSecrets)))
+	s.RegisterAnalyzer(webhooks.NewAnalyzer(k8sClient, logger))
+	s.RegisterAnalyzer(versions.NewAnalyzer(k8sClient, logger))
+	s.RegisterAnalyzer(governance.NewAnalyzer(k8sClient, logger, governance.WithRequiredLabels(flags.requiredLabels...)))
+	s.RegisterAnalyzer(controlplane.NewAnalyzer(k8sClient, logger, controlplane.WithHostRoot(flags.hostRoot)))
 
 	// Run scan.
 	result, err := s.Run(ctx, config)
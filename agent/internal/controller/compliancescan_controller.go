@@ -14,6 +14,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	v1alpha1 "github.com/kubecomply/kubecomply/api/v1alpha1"
+	"github.com/kubecomply/kubecomply/pkg/certificates"
+	"github.com/kubecomply/kubecomply/pkg/controlplane"
+	"github.com/kubecomply/kubecomply/pkg/governance"
+	"github.com/kubecomply/kubecomply/pkg/ingress"
 	"github.com/kubecomply/kubecomply/pkg/k8s"
 	"github.com/kubecomply/kubecomply/pkg/metrics"
 	"github.com/kubecomply/kubecomply/pkg/network"
@@ -22,6 +26,10 @@ import (
 	"github.com/kubecomply/kubecomply/pkg/rbac"
 	"github.com/kubecomply/kubecomply/pkg/saas"
 	"github.com/kubecomply/kubecomply/pkg/scanner"
+	"github.com/kubecomply/kubecomply/pkg/secrets"
+	"github.com/kubecomply/kubecomply/pkg/versions"
+	"github.com/kubecomply/kubecomply/pkg/webhooks"
+	"github.com/kubecomply/kubecomply/pkg/workload"
 )
 
 const (
@@ -36,15 +44,41 @@ type ComplianceScanReconciler struct {
 	PolicyEngine *policies.Engine
 	SaaSClient   *saas.Client
 	Logger       *slog.Logger
+	// ReadTLSSecrets lets the certificate analyzer read tls.crt from
+	// kubernetes.io/tls Secrets.
+	ReadTLSSecrets bool
+	// HostRoot is where the control plane node's filesystem is mounted, for
+	// the control plane analyzer to read the API server's configuration files.
+	HostRoot string
+	// IgnoredRoles are role name patterns excluded from unused-role
+	// detection, in addition to the bootstrap roles.
+	IgnoredRoles []string
+	// AllowedRegistries are the registries or repository prefixes workload
+	// images may come from. Empty allows any registry.
+	AllowedRegistries []string
+	// RequiredLabels are the label keys every namespace must carry. Empty
+	// disables the governance label check.
+	RequiredLabels []string
+	// PodTemplateSources are the custom resources whose pod templates are
+	// evaluated by the PSS checks and counted as Secret references, in
+	// addition to the defaults.
+	PodTemplateSources []pss.PodTemplateSource
 }
 
 // +kubebuilder:rbac:groups=compliance.kubecomply.io,resources=compliancescans,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=compliance.kubecomply.io,resources=compliancescans/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=compliance.kubecomply.io,resources=compliancescans/finalizers,verbs=update
-// +kubebuilder:rbac:groups="",resources=pods;namespaces;services;nodes;secrets,verbs=get;list;watch
-// +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods;namespaces;services;nodes;secrets;serviceaccounts;configmaps;replicationcontrollers;resourcequotas;limitranges,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;statefulsets;replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=services;configurations;revisions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch
-// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies;ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cilium.io,resources=ciliumnetworkpolicies;ciliumclusterwidenetworkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=crd.projectcalico.org,resources=globalnetworkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways;httproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list
 
 // Reconcile handles ComplianceScan create/update/delete events.
 func (r *ComplianceScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -142,9 +176,17 @@ func (r *ComplianceScanReconciler) executeScan(ctx context.Context, scan *v1alph
 	// Build the scanner with analyzers.
 	s := scanner.New(r.K8sClient, logger)
 	s.SetPolicyEvaluator(r.PolicyEngine)
-	s.RegisterAnalyzer(rbac.NewAnalyzer(r.K8sClient, logger))
+	s.RegisterAnalyzer(rbac.NewAnalyzer(r.K8sClient, logger, rbac.WithUnusedRoleAllowlist(r.IgnoredRoles...)))
 	s.RegisterAnalyzer(network.NewAnalyzer(r.K8sClient, logger))
-	s.RegisterAnalyzer(pss.NewChecker(r.K8sClient, logger))
+	s.RegisterAnalyzer(pss.NewChecker(r.K8sClient, logger, pss.WithPodTemplateSources(r.PodTemplateSources...)))
+	s.RegisterAnalyzer(ingress.NewAnalyzer(r.K8sClient, logger))
+	s.RegisterAnalyzer(workload.NewAnalyzer(r.K8sClient, logger, workload.WithAllowedRegistries(r.AllowedRegistries...)))
+	s.RegisterAnalyzer(secrets.NewAnalyzer(r.K8sClient, logger, secrets.WithPodTemplateSources(r.PodTemplateSources...)))
+	s.RegisterAnalyzer(certificates.NewAnalyzer(r.K8sClient, logger, certificates.WithTLSSecrets(r.ReadTLSSecrets)))
+	s.RegisterAnalyzer(webhooks.NewAnalyzer(r.K8sClient, logger))
+	s.RegisterAnalyzer(versions.NewAnalyzer(r.K8sClient, logger))
+	s.RegisterAnalyzer(governance.NewAnalyzer(r.K8sClient, logger, governance.WithRequiredLabels(r.RequiredLabels...)))
+	s.RegisterAnalyzer(controlplane.NewAnalyzer(r.K8sClient, logger, controlplane.WithHostRoot(r.HostRoot)))
 
 	return s.Run(ctx, config)
 }
@@ -0,0 +1,202 @@
+// Package certificates checks the X.509 certificates in admission webhook
+// caBundles and, when enabled with WithTLSSecrets, in kubernetes.io/tls
+// Secrets for expiry, weak keys and weak signature algorithms, and checks
+// that each Secret's tls.key belongs to its certificate. Private keys are
+// reduced to their public key by the client and only certificate metadata is
+// reported.
+package certificates
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// DefaultExpiryWindows are the expiry warning windows used when none are
+// configured: certificates expiring within 7 days are reported at high
+// severity and within 30 days at medium severity.
+var DefaultExpiryWindows = []time.Duration{30 * 24 * time.Hour, 7 * 24 * time.Hour}
+
+// injectCASecretAnnotation is set by cert-manager's CA injector on webhook
+// configurations whose caBundle is copied from a Secret.
+const injectCASecretAnnotation = "cert-manager.io/inject-ca-from-secret"
+
+// Analyzer evaluates TLS certificates stored in the cluster.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client        *k8s.Client
+	logger        *slog.Logger
+	expiryWindows []time.Duration
+	tlsSecrets    bool
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithExpiryWindows sets the windows within which an expiring certificate
+// is reported (CERT-002). The shortest window maps to high severity, the
+// next to medium and any others to low. Non-positive windows are ignored;
+// with none left the defaults apply.
+func WithExpiryWindows(windows ...time.Duration) Option {
+	return func(a *Analyzer) {
+		var valid []time.Duration
+		for _, w := range windows {
+			if w > 0 {
+				valid = append(valid, w)
+			}
+		}
+		if len(valid) > 0 {
+			a.expiryWindows = valid
+		}
+	}
+}
+
+// WithTLSSecrets enables reading kubernetes.io/tls Secrets: their tls.crt
+// and the public key derived from their tls.key. It is off by default because
+// it is the one place the analyzers read Secret data; without it only webhook
+// caBundles are checked.
+func WithTLSSecrets(enabled bool) Option {
+	return func(a *Analyzer) {
+		a.tlsSecrets = enabled
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "certificates" }
+
+// NewAnalyzer creates a new TLS certificate analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client:        client,
+		logger:        logger,
+		expiryWindows: append([]time.Duration(nil), DefaultExpiryWindows...),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	// Shortest window first, so the tightest match determines severity.
+	sort.Slice(a.expiryWindows, func(i, j int) bool { return a.expiryWindows[i] < a.expiryWindows[j] })
+	return a
+}
+
+// inventory holds the objects fetched for a single analysis run.
+type inventory struct {
+	certificates       []k8s.TLSCertificate
+	mutatingWebhooks   []admissionregistrationv1.MutatingWebhookConfiguration
+	validatingWebhooks []admissionregistrationv1.ValidatingWebhookConfiguration
+	// references maps "namespace/name" of a Secret to the Ingresses,
+	// Gateways and webhook configurations that use it.
+	references map[string][]string
+}
+
+// Analyze runs all certificate checks and returns findings.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting certificate analysis")
+
+	inv, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	// Check 1: Certificates in kubernetes.io/tls Secrets, when enabled.
+	findings = append(findings, a.checkSecrets(inv, now)...)
+
+	// Check 2: CA bundles of admission webhooks.
+	findings = append(findings, a.checkWebhookBundles(inv, now)...)
+
+	a.logger.Info("certificate analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect lists the TLS certificates, if enabled, and Ingresses of the given
+// namespaces, all Gateways and all admission webhook configurations.
+func (a *Analyzer) collect(ctx context.Context, namespaces []string) (*inventory, error) {
+	inv := &inventory{references: make(map[string][]string)}
+	addRef := func(key, by string) {
+		for _, existing := range inv.references[key] {
+			if existing == by {
+				return
+			}
+		}
+		inv.references[key] = append(inv.references[key], by)
+	}
+
+	for _, ns := range namespaces {
+		if a.tlsSecrets {
+			certificates, err := a.client.ListTLSCertificates(ctx, ns)
+			if err != nil {
+				a.logger.Warn("failed to list TLS certificates", "namespace", ns, "error", err)
+			}
+			inv.certificates = append(inv.certificates, certificates...)
+		}
+
+		ingresses, err := a.client.ListIngresses(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list ingresses", "namespace", ns, "error", err)
+		}
+		for _, ing := range ingresses {
+			for _, tls := range ing.Spec.TLS {
+				if tls.SecretName != "" {
+					addRef(ns+"/"+tls.SecretName, fmt.Sprintf("Ingress/%s/%s", ns, ing.Name))
+				}
+			}
+		}
+	}
+
+	gateways, err := a.client.ListGateways(ctx, "")
+	if err != nil {
+		a.logger.Warn("failed to list gateways", "error", err)
+	}
+	for _, gw := range gateways {
+		for _, l := range gw.Spec.Listeners {
+			if l.TLS == nil {
+				continue
+			}
+			for _, ref := range l.TLS.CertificateRefs {
+				if (ref.Group != "" && ref.Group != "core") || (ref.Kind != "" && ref.Kind != "Secret") {
+					continue
+				}
+				ns := ref.Namespace
+				if ns == "" {
+					ns = gw.Namespace
+				}
+				addRef(ns+"/"+ref.Name, fmt.Sprintf("Gateway/%s/%s", gw.Namespace, gw.Name))
+			}
+		}
+	}
+
+	inv.mutatingWebhooks, err = a.client.ListMutatingWebhookConfigurations(ctx)
+	if err != nil {
+		a.logger.Warn("failed to list mutating webhook configurations", "error", err)
+	}
+	for _, cfg := range inv.mutatingWebhooks {
+		if key := cfg.Annotations[injectCASecretAnnotation]; key != "" {
+			addRef(key, "MutatingWebhookConfiguration/"+cfg.Name)
+		}
+	}
+
+	inv.validatingWebhooks, err = a.client.ListValidatingWebhookConfigurations(ctx)
+	if err != nil {
+		a.logger.Warn("failed to list validating webhook configurations", "error", err)
+	}
+	for _, cfg := range inv.validatingWebhooks {
+		if key := cfg.Annotations[injectCASecretAnnotation]; key != "" {
+			addRef(key, "ValidatingWebhookConfiguration/"+cfg.Name)
+		}
+	}
+
+	return inv, nil
+}
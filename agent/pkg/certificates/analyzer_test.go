@@ -0,0 +1,117 @@
+package certificates
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// selfSignedPEM returns a PEM-encoded self-signed certificate valid until
+// notAfter and its PEM-encoded PKCS #8 private key.
+func selfSignedPEM(t *testing.T, notAfter time.Time) (crt, key []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "shop.example.com"},
+		DNSNames:     []string{"shop.example.com"},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestAnalyzeTLSSecrets(t *testing.T) {
+	now := time.Now()
+	tlsSecret := func(name string, crt, key []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: crt, corev1.TLSPrivateKeyKey: key},
+		}
+	}
+	expired, expiredKey := selfSignedPEM(t, now.Add(-24*time.Hour))
+	expiring, expiringKey := selfSignedPEM(t, now.Add(3*24*time.Hour))
+	valid, validKey := selfSignedPEM(t, now.Add(365*24*time.Hour))
+	// A SEC1 "EC PRIVATE KEY" block for the valid certificate.
+	block, _ := pem.Decode(validKey)
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(parsed.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := k8s.NewClientFromInterface(fake.NewSimpleClientset(
+		tlsSecret("expired", expired, expiredKey),
+		tlsSecret("expiring", expiring, expiringKey),
+		tlsSecret("valid", valid, validKey),
+		tlsSecret("valid-sec1", valid, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})),
+		tlsSecret("mismatched", valid, expiredKey),
+		tlsSecret("bad-key", valid, []byte("not a key")),
+		tlsSecret("garbage", []byte("not a certificate"), validKey),
+	), "test", nil)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    []string
+	}{
+		{name: "disabled", enabled: false},
+		{
+			name:    "enabled",
+			enabled: true,
+			want: []string{
+				"CERT-001 Secret/shop/expired",
+				"CERT-002 Secret/shop/expiring",
+				"CERT-005 Secret/shop/bad-key",
+				"CERT-005 Secret/shop/mismatched",
+				"CERT-006 Secret/shop/garbage",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := NewAnalyzer(client, nil, WithTLSSecrets(tt.enabled)).Analyze(context.Background(), []string{"shop"})
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.ID+" "+f.Resource)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
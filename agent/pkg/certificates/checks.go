@@ -0,0 +1,379 @@
+package certificates
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/certs"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// minRSABits and minECDSABits are the smallest acceptable key sizes.
+const (
+	minRSABits   = 2048
+	minECDSABits = 256
+)
+
+// weakSignatureAlgorithms are signature algorithms with practical collision
+// attacks.
+var weakSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.MD2WithRSA:    true,
+	x509.MD5WithRSA:    true,
+	x509.SHA1WithRSA:   true,
+	x509.DSAWithSHA1:   true,
+	x509.ECDSAWithSHA1: true,
+}
+
+// target is the object a certificate was found in.
+type target struct {
+	resource  string
+	namespace string
+	// label names the certificate's location in descriptions, e.g.
+	// "Secret shop/web-tls".
+	label string
+	// usedBy lists the objects relying on the certificate, if known.
+	usedBy  []string
+	details map[string]string
+}
+
+// describe returns the target label followed by its users.
+func (t target) describe() string {
+	if len(t.usedBy) == 0 {
+		return t.label
+	}
+	return fmt.Sprintf("%s, used by %s,", t.label, strings.Join(t.usedBy, ", "))
+}
+
+// findingDetails returns the target's details with the certificate's
+// metadata added.
+func (t target) findingDetails(cert *x509.Certificate, role string) map[string]string {
+	details := make(map[string]string, len(t.details)+6)
+	for k, v := range t.details {
+		details[k] = v
+	}
+	if len(t.usedBy) > 0 {
+		details["referenced_by"] = strings.Join(t.usedBy, ",")
+	}
+	if cert != nil {
+		details["certificate"] = role
+		details["subject"] = certs.Describe(cert)
+		details["dns_names"] = certs.Names(cert)
+		details["not_after"] = cert.NotAfter.UTC().Format(time.RFC3339)
+	}
+	return details
+}
+
+// selfSigned reports whether a certificate is a self-signed trust anchor,
+// whose own signature is never verified by clients.
+func selfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// keyWeakness describes why a certificate's public key is too weak, or
+// returns "" when it is acceptable.
+func keyWeakness(cert *x509.Certificate) string {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := pub.N.BitLen(); bits < minRSABits {
+			return fmt.Sprintf("a %d-bit RSA key", bits)
+		}
+	case *ecdsa.PublicKey:
+		if bits := pub.Curve.Params().BitSize; bits < minECDSABits {
+			return fmt.Sprintf("a %d-bit ECDSA key", bits)
+		}
+	}
+	return ""
+}
+
+// checkCertificate reports an expired certificate (CERT-001), one expiring
+// within a configured window (CERT-002), a weak key (CERT-003) and a weak
+// signature algorithm (CERT-004). role is "leaf", "intermediate" or "CA".
+func (a *Analyzer) checkCertificate(t target, cert *x509.Certificate, role string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+	what := fmt.Sprintf("%s certificate %s in %s", role, certs.Describe(cert), t.describe())
+
+	switch remaining := cert.NotAfter.Sub(now); {
+	case remaining <= 0:
+		findings = append(findings, scanner.Finding{
+			ID:          "CERT-001",
+			Title:       "Certificate expired",
+			Description: fmt.Sprintf("The %s expired on %s", what, cert.NotAfter.UTC().Format("2006-01-02")),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "certificates",
+			Resource:    t.resource,
+			Namespace:   t.namespace,
+			Remediation: "Renew the certificate and update the object holding it. Automate renewal with cert-manager or your PKI.",
+			Details:     t.findingDetails(cert, role),
+			Timestamp:   now,
+		})
+	default:
+		for i, window := range a.expiryWindows {
+			if remaining >= window {
+				continue
+			}
+			severity := scanner.SeverityLow
+			switch i {
+			case 0:
+				severity = scanner.SeverityHigh
+			case 1:
+				severity = scanner.SeverityMedium
+			}
+			days := int(remaining.Hours() / 24)
+			details := t.findingDetails(cert, role)
+			details["days_remaining"] = fmt.Sprintf("%d", days)
+			details["window_days"] = fmt.Sprintf("%d", int(window.Hours()/24))
+			findings = append(findings, scanner.Finding{
+				ID:          "CERT-002",
+				Title:       "Certificate expiring soon",
+				Description: fmt.Sprintf("The %s expires in %d days", what, days),
+				Severity:    severity,
+				Status:      scanner.StatusWarning,
+				Category:    "certificates",
+				Resource:    t.resource,
+				Namespace:   t.namespace,
+				Remediation: "Renew the certificate before it expires, and check that automated renewal (e.g. cert-manager) is working.",
+				Details:     details,
+				Timestamp:   now,
+			})
+			break
+		}
+	}
+
+	if weakness := keyWeakness(cert); weakness != "" {
+		findings = append(findings, scanner.Finding{
+			ID:          "CERT-003",
+			Title:       "Certificate with weak key",
+			Description: fmt.Sprintf("The %s uses %s", what, weakness),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "certificates",
+			Resource:    t.resource,
+			Namespace:   t.namespace,
+			Remediation: fmt.Sprintf("Reissue the certificate with an RSA key of at least %d bits or an ECDSA P-256 or stronger key.", minRSABits),
+			Details:     t.findingDetails(cert, role),
+			Timestamp:   now,
+		})
+	}
+
+	if weakSignatureAlgorithms[cert.SignatureAlgorithm] && !selfSigned(cert) {
+		details := t.findingDetails(cert, role)
+		details["signature_algorithm"] = cert.SignatureAlgorithm.String()
+		findings = append(findings, scanner.Finding{
+			ID:          "CERT-004",
+			Title:       "Certificate signed with weak algorithm",
+			Description: fmt.Sprintf("The %s is signed with %s, which clients reject and which is open to collision attacks", what, cert.SignatureAlgorithm),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "certificates",
+			Resource:    t.resource,
+			Namespace:   t.namespace,
+			Remediation: "Reissue the certificate with a SHA-256 or stronger signature algorithm.",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
+
+// chainRole names a certificate's position in a chain.
+func chainRole(i int, cert *x509.Certificate) string {
+	switch {
+	case i == 0:
+		return "leaf"
+	case selfSigned(cert):
+		return "CA"
+	default:
+		return "intermediate"
+	}
+}
+
+// checkSecrets evaluates the certificate chain of every kubernetes.io/tls
+// Secret listed, and reports a tls.key that does not belong to the leaf
+// certificate or cannot be parsed (CERT-005). Unparseable certificates are
+// CERT-006.
+func (a *Analyzer) checkSecrets(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, c := range inv.certificates {
+		key := c.Namespace + "/" + c.Name
+		t := target{
+			resource:  "Secret/" + key,
+			namespace: c.Namespace,
+			label:     "Secret " + key,
+			usedBy:    inv.references[key],
+			details:   map[string]string{"secret": key},
+		}
+
+		chain, err := certs.ParsePEM(c.Chain)
+		if err != nil {
+			findings = append(findings, scanner.Finding{
+				ID:          "CERT-006",
+				Title:       "Unparseable certificate",
+				Description: fmt.Sprintf("%s does not contain a parseable PEM certificate in %s", t.describe(), corev1.TLSCertKey),
+				Severity:    scanner.SeverityMedium,
+				Status:      scanner.StatusFail,
+				Category:    "certificates",
+				Resource:    t.resource,
+				Namespace:   t.namespace,
+				Remediation: "Store a PEM-encoded certificate chain, leaf first, in tls.crt.",
+				Details:     t.findingDetails(nil, ""),
+				Timestamp:   now,
+			})
+			continue
+		}
+
+		for j, cert := range chain {
+			findings = append(findings, a.checkCertificate(t, cert, chainRole(j, cert), now)...)
+		}
+
+		leaf := chain[0]
+		switch {
+		case c.KeyErr != nil:
+			findings = append(findings, scanner.Finding{
+				ID:          "CERT-005",
+				Title:       "TLS key does not match certificate",
+				Description: fmt.Sprintf("The %s of %s cannot be parsed (%v), so clients cannot complete a TLS handshake with it", corev1.TLSPrivateKeyKey, t.describe(), c.KeyErr),
+				Severity:    scanner.SeverityHigh,
+				Status:      scanner.StatusFail,
+				Category:    "certificates",
+				Resource:    t.resource,
+				Namespace:   t.namespace,
+				Remediation: "Store the PEM-encoded private key of the leaf certificate in tls.key.",
+				Details:     t.findingDetails(leaf, "leaf"),
+				Timestamp:   now,
+			})
+		case !certs.MatchesKey(leaf, c.PublicKey):
+			findings = append(findings, scanner.Finding{
+				ID:          "CERT-005",
+				Title:       "TLS key does not match certificate",
+				Description: fmt.Sprintf("The %s of %s is not the private key of the leaf certificate %s, so clients cannot complete a TLS handshake with it", corev1.TLSPrivateKeyKey, t.describe(), certs.Describe(leaf)),
+				Severity:    scanner.SeverityHigh,
+				Status:      scanner.StatusFail,
+				Category:    "certificates",
+				Resource:    t.resource,
+				Namespace:   t.namespace,
+				Remediation: "Store the private key belonging to the leaf certificate in tls.key, or reissue the certificate for the stored key. Check that the leaf certificate comes first in tls.crt.",
+				Details:     t.findingDetails(leaf, "leaf"),
+				Timestamp:   now,
+			})
+		}
+	}
+
+	return findings
+}
+
+// webhookClient is the part of a webhook's clientConfig relevant here.
+type webhookClient struct {
+	webhook  string
+	caBundle []byte
+	service  string
+}
+
+// clientOf extracts the caBundle and service of a webhook.
+func clientOf(webhook string, cc admissionregistrationv1.WebhookClientConfig) webhookClient {
+	c := webhookClient{webhook: webhook, caBundle: cc.CABundle}
+	if cc.Service != nil {
+		c.service = cc.Service.Namespace + "/" + cc.Service.Name
+	}
+	return c
+}
+
+// bundleUse groups the webhooks of a configuration that share a caBundle.
+type bundleUse struct {
+	bundle   []byte
+	webhooks []string
+	services []string
+}
+
+// groupBundles groups webhooks by caBundle, in first-seen order. Webhooks
+// without a caBundle use the API server's trust store and are skipped.
+func groupBundles(clients []webhookClient) []*bundleUse {
+	var groups []*bundleUse
+	index := make(map[string]*bundleUse)
+	for _, c := range clients {
+		if len(c.caBundle) == 0 {
+			continue
+		}
+		g, ok := index[string(c.caBundle)]
+		if !ok {
+			g = &bundleUse{bundle: c.caBundle}
+			index[string(c.caBundle)] = g
+			groups = append(groups, g)
+		}
+		g.webhooks = append(g.webhooks, c.webhook)
+		if c.service != "" && !slices.Contains(g.services, c.service) {
+			g.services = append(g.services, c.service)
+		}
+	}
+	return groups
+}
+
+// checkWebhookBundles evaluates every CA certificate in the caBundles of
+// mutating and validating webhooks. Webhooks of a configuration sharing a
+// bundle are reported together.
+func (a *Analyzer) checkWebhookBundles(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	check := func(kind, name string, clients []webhookClient) {
+		resource := fmt.Sprintf("%s/%s", kind, name)
+		for _, g := range groupBundles(clients) {
+			t := target{
+				resource: resource,
+				label:    fmt.Sprintf("the caBundle of %s (webhooks %s)", resource, strings.Join(g.webhooks, ", ")),
+				details:  map[string]string{"webhooks": strings.Join(g.webhooks, ",")},
+			}
+			if len(g.services) > 0 {
+				t.details["services"] = strings.Join(g.services, ",")
+			}
+
+			bundle, err := certs.ParsePEM(g.bundle)
+			if err != nil {
+				findings = append(findings, scanner.Finding{
+					ID:          "CERT-006",
+					Title:       "Unparseable certificate",
+					Description: fmt.Sprintf("The caBundle of %s (webhooks %s) does not contain a parseable PEM certificate, so the API server cannot verify the webhook", resource, strings.Join(g.webhooks, ", ")),
+					Severity:    scanner.SeverityMedium,
+					Status:      scanner.StatusFail,
+					Category:    "certificates",
+					Resource:    t.resource,
+					Namespace:   t.namespace,
+					Remediation: "Set clientConfig.caBundle to the PEM-encoded CA that issued the webhook's serving certificate, or let cert-manager's CA injector manage it.",
+					Details:     t.findingDetails(nil, ""),
+					Timestamp:   now,
+				})
+				continue
+			}
+			for _, cert := range bundle {
+				findings = append(findings, a.checkCertificate(t, cert, "CA", now)...)
+			}
+		}
+	}
+
+	for _, cfg := range inv.mutatingWebhooks {
+		clients := make([]webhookClient, 0, len(cfg.Webhooks))
+		for _, wh := range cfg.Webhooks {
+			clients = append(clients, clientOf(wh.Name, wh.ClientConfig))
+		}
+		check("MutatingWebhookConfiguration", cfg.Name, clients)
+	}
+	for _, cfg := range inv.validatingWebhooks {
+		clients := make([]webhookClient, 0, len(cfg.Webhooks))
+		for _, wh := range cfg.Webhooks {
+			clients = append(clients, clientOf(wh.Name, wh.ClientConfig))
+		}
+		check("ValidatingWebhookConfiguration", cfg.Name, clients)
+	}
+
+	return findings
+}
@@ -0,0 +1,101 @@
+// Package certs parses X.509 certificates stored in Kubernetes objects. Only
+// certificate metadata is ever surfaced; private keys are parsed only to
+// derive their public key.
+package certs
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// ParsePEM returns the certificates in PEM data in the order they appear.
+// Blocks other than CERTIFICATE, such as private keys, are skipped.
+func ParsePEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// Describe returns a short, non-sensitive description of a certificate:
+// its subject common name (or first DNS name) and issuer common name.
+func Describe(cert *x509.Certificate) string {
+	subject := cert.Subject.CommonName
+	if subject == "" && len(cert.DNSNames) > 0 {
+		subject = cert.DNSNames[0]
+	}
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	if issuer := cert.Issuer.CommonName; issuer != "" && issuer != subject {
+		return fmt.Sprintf("%s issued by %s", subject, issuer)
+	}
+	return subject
+}
+
+// Names returns the DNS names a certificate is valid for, joined by commas.
+func Names(cert *x509.Certificate) string {
+	if len(cert.DNSNames) > 0 {
+		return strings.Join(cert.DNSNames, ",")
+	}
+	return cert.Subject.CommonName
+}
+
+// PublicKey returns the public half of the first private key in PEM data.
+// The private key is parsed only to derive it and is not returned.
+func PublicKey(data []byte) (crypto.PublicKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM private key found")
+		}
+
+		var key any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", strings.ToLower(block.Type), err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer.Public(), nil
+	}
+}
+
+// MatchesKey reports whether a certificate was issued for the given public
+// key.
+func MatchesKey(cert *x509.Certificate, pub crypto.PublicKey) bool {
+	key, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(pub)
+}
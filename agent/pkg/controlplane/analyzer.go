@@ -0,0 +1,220 @@
+// Package controlplane analyzes the kube-apiserver's encryption-at-rest and
+// audit configuration files. The CIS checks only verify that
+// --encryption-provider-config and --audit-policy-file are set; this package
+// reads the files they point to, which requires access to the control plane
+// node's filesystem through a host root.
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// apiServerManifest is where kubeadm writes the kube-apiserver static pod,
+// relative to the host root.
+const apiServerManifest = "/etc/kubernetes/manifests/kube-apiserver.yaml"
+
+// apiServerResource is the resource control plane findings are reported
+// against, matching the CIS API server checks.
+const apiServerResource = "Pod/kube-system/kube-apiserver"
+
+// Analyzer evaluates the API server's encryption and audit configuration.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client           *k8s.Client
+	logger           *slog.Logger
+	hostRoot         string
+	encryptionConfig string
+	auditPolicy      string
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithHostRoot sets the directory the control plane node's root filesystem
+// is mounted at, e.g. "/host" in a pod or "/" on the node itself. Paths from
+// the API server's flags are resolved beneath it.
+func WithHostRoot(dir string) Option {
+	return func(a *Analyzer) {
+		a.hostRoot = dir
+	}
+}
+
+// WithEncryptionConfig sets the EncryptionConfiguration file to analyze,
+// overriding --encryption-provider-config.
+func WithEncryptionConfig(path string) Option {
+	return func(a *Analyzer) {
+		a.encryptionConfig = path
+	}
+}
+
+// WithAuditPolicy sets the audit Policy file to analyze, overriding
+// --audit-policy-file.
+func WithAuditPolicy(path string) Option {
+	return func(a *Analyzer) {
+		a.auditPolicy = path
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "controlplane" }
+
+// NewAnalyzer creates a new control plane configuration analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client: client,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Analyze runs all control plane configuration checks and returns findings.
+// Findings come only from reading the configuration files, so without a host
+// root or explicit file paths nothing is reported. Unset flags are left to the
+// CIS checks.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting control plane analysis")
+
+	if a.hostRoot == "" && a.encryptionConfig == "" && a.auditPolicy == "" {
+		a.logger.Info("no host root or configuration files given, skipping control plane analysis")
+		return nil, nil
+	}
+
+	encryptionPath, auditPath := a.encryptionConfig, a.auditPolicy
+	if encryptionPath == "" || auditPath == "" {
+		flags, found := a.apiServerFlags(ctx)
+		if !found {
+			a.logger.Info("kube-apiserver flags not found, analyzing only the files given")
+		}
+		if encryptionPath == "" {
+			encryptionPath = flags["encryption-provider-config"]
+		}
+		if auditPath == "" {
+			auditPath = flags["audit-policy-file"]
+		}
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	// Check 1: Encryption at rest configuration.
+	if encryptionPath != "" {
+		findings = append(findings, a.checkEncryption(encryptionPath, now)...)
+	}
+
+	// Check 2: Audit policy.
+	if auditPath != "" {
+		findings = append(findings, a.checkAudit(auditPath, now)...)
+	}
+
+	a.logger.Info("control plane analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// fileFinding builds a finding about a configuration file.
+func fileFinding(id, title, description string, severity scanner.Severity, status scanner.FindingStatus, remediation, path string, now time.Time) scanner.Finding {
+	return scanner.Finding{
+		ID:          id,
+		Title:       title,
+		Description: description,
+		Severity:    severity,
+		Status:      status,
+		Category:    "controlplane",
+		Resource:    apiServerResource,
+		Namespace:   "kube-system",
+		Remediation: remediation,
+		Details: map[string]string{
+			"file": path,
+		},
+		Timestamp: now,
+	}
+}
+
+// readFile reads a control plane file, beneath the host root if one is set.
+func (a *Analyzer) readFile(path string) ([]byte, error) {
+	if a.hostRoot != "" {
+		path = filepath.Join(a.hostRoot, path)
+	}
+	return os.ReadFile(path)
+}
+
+// apiServerFlags returns the kube-apiserver's flags, from its static pod in
+// kube-system or, failing that, from its manifest beneath the host root.
+// found is false when neither is available, as on managed control planes.
+func (a *Analyzer) apiServerFlags(ctx context.Context) (flags map[string]string, found bool) {
+	if a.client != nil {
+		pods, err := a.client.ListPods(ctx, "kube-system")
+		if err != nil {
+			a.logger.Warn("failed to list pods", "namespace", "kube-system", "error", err)
+		}
+		for i := range pods {
+			if pods[i].Labels["component"] == "kube-apiserver" || strings.HasPrefix(pods[i].Name, "kube-apiserver-") {
+				return parseFlags(&pods[i]), true
+			}
+		}
+	}
+
+	if a.hostRoot == "" {
+		return nil, false
+	}
+	data, err := a.readFile(apiServerManifest)
+	if err != nil {
+		a.logger.Debug("kube-apiserver manifest not readable", "path", apiServerManifest, "error", err)
+		return nil, false
+	}
+	var pod corev1.Pod
+	if err := utilyaml.Unmarshal(data, &pod); err != nil {
+		a.logger.Warn("failed to parse kube-apiserver manifest", "path", apiServerManifest, "error", err)
+		return nil, false
+	}
+	return parseFlags(&pod), true
+}
+
+// parseFlags collects the --name=value flags of the pod's kube-apiserver
+// container. Flags without a value are recorded as "true".
+func parseFlags(pod *corev1.Pod) map[string]string {
+	flags := make(map[string]string)
+	for _, c := range pod.Spec.Containers {
+		args := append(append([]string(nil), c.Command...), c.Args...)
+		if len(pod.Spec.Containers) > 1 && !strings.Contains(strings.Join(c.Command, " "), "kube-apiserver") && c.Name != "kube-apiserver" {
+			continue
+		}
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "--") {
+				continue
+			}
+			name, value, ok := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			if !ok {
+				value = "true"
+			}
+			flags[name] = value
+		}
+	}
+	return flags
+}
+
+// describePath returns a path as written in the configuration, noting the
+// host root it was read beneath.
+func (a *Analyzer) describePath(path string) string {
+	if a.hostRoot == "" || a.hostRoot == "/" {
+		return path
+	}
+	return fmt.Sprintf("%s (under %s)", path, a.hostRoot)
+}
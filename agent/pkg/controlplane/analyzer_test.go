@@ -0,0 +1,112 @@
+package controlplane
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// apiServerPod returns a kube-apiserver static pod with the given flags.
+func apiServerPod(flags ...string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kube-apiserver-cp-1",
+			Namespace: "kube-system",
+			Labels:    map[string]string{"component": "kube-apiserver"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "kube-apiserver",
+				Command: append([]string{"kube-apiserver"}, flags...),
+			}},
+		},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	hostRoot := t.TempDir()
+	files := map[string]string{
+		"etc/kubernetes/enc.yaml": `kind: EncryptionConfiguration
+resources:
+  - resources: ["secrets"]
+    providers:
+      - identity: {}
+`,
+		"etc/kubernetes/audit-policy.yaml": `kind: Policy
+rules:
+  - level: Metadata
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(hostRoot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configured := apiServerPod("--encryption-provider-config=/etc/kubernetes/enc.yaml", "--audit-policy-file=/etc/kubernetes/audit-policy.yaml")
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		opts []Option
+		want []string
+	}{
+		{
+			name: "no host root",
+			pod:  configured,
+		},
+		{
+			// Unset flags are left to the CIS checks.
+			name: "flags unset",
+			pod:  apiServerPod("--secure-port=6443"),
+			opts: []Option{WithHostRoot(hostRoot)},
+		},
+		{
+			name: "files read under host root",
+			pod:  configured,
+			opts: []Option{WithHostRoot(hostRoot)},
+			want: []string{"AUD-004", "ENC-004"},
+		},
+		{
+			name: "explicit file without api server",
+			opts: []Option{WithHostRoot(hostRoot), WithAuditPolicy("/etc/kubernetes/audit-policy.yaml")},
+			want: []string{"AUD-004"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset()
+			if tt.pod != nil {
+				cs = fake.NewSimpleClientset(tt.pod)
+			}
+			a := NewAnalyzer(k8s.NewClientFromInterface(cs, "test", nil), nil, tt.opts...)
+
+			findings, err := a.Analyze(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.ID)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package controlplane
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Audit levels, in increasing order of detail.
+const (
+	levelNone            = "None"
+	levelMetadata        = "Metadata"
+	levelRequest         = "Request"
+	levelRequestResponse = "RequestResponse"
+)
+
+// levelRank orders audit levels by detail.
+var levelRank = map[string]int{
+	levelNone:            0,
+	levelMetadata:        1,
+	levelRequest:         2,
+	levelRequestResponse: 3,
+}
+
+// rbacResources are the RBAC resources whose changes should be audited in
+// full.
+var rbacResources = []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"}
+
+// auditPolicy is the part of an audit.k8s.io Policy the checks need.
+type auditPolicy struct {
+	Kind       string      `json:"kind"`
+	OmitStages []string    `json:"omitStages"`
+	Rules      []auditRule `json:"rules"`
+}
+
+// auditRule is a single rule of an audit Policy.
+type auditRule struct {
+	Level           string   `json:"level"`
+	Users           []string `json:"users"`
+	UserGroups      []string `json:"userGroups"`
+	Verbs           []string `json:"verbs"`
+	Namespaces      []string `json:"namespaces"`
+	NonResourceURLs []string `json:"nonResourceURLs"`
+	Resources       []struct {
+		Group         string   `json:"group"`
+		Resources     []string `json:"resources"`
+		ResourceNames []string `json:"resourceNames"`
+	} `json:"resources"`
+	OmitStages []string `json:"omitStages"`
+}
+
+// matches reports whether the rule applies to requests for a resource.
+// conditional is set when it only applies to some users, verbs, namespaces
+// or object names.
+func (r auditRule) matches(group, resource string) (matches, conditional bool) {
+	if len(r.NonResourceURLs) > 0 && len(r.Resources) == 0 {
+		return false, false
+	}
+	conditional = len(r.Users) > 0 || len(r.UserGroups) > 0 || len(r.Verbs) > 0 || len(r.Namespaces) > 0
+	if len(r.Resources) == 0 {
+		return true, conditional
+	}
+	for _, gr := range r.Resources {
+		if gr.Group != group && gr.Group != "*" {
+			continue
+		}
+		if len(gr.Resources) == 0 || slices.Contains(gr.Resources, resource) || slices.Contains(gr.Resources, "*") {
+			return true, conditional || len(gr.ResourceNames) > 0
+		}
+	}
+	return false, false
+}
+
+// effectiveLevel returns the level the policy applies to requests for a
+// resource: that of the first rule matching every such request. Narrower
+// rules before it, which only apply to some requests, are skipped. Requests
+// matching no rule are not audited.
+func (p *auditPolicy) effectiveLevel(group, resource string) (level string, rule int) {
+	for i, r := range p.Rules {
+		if matches, conditional := r.matches(group, resource); matches && !conditional {
+			return r.Level, i
+		}
+	}
+	return levelNone, -1
+}
+
+// checkAudit parses the audit Policy and reports an unreadable file
+// (AUD-002), Secret access audited at None or at a level that logs Secret
+// values (AUD-003), RBAC changes audited below RequestResponse (AUD-004) and
+// omitted stages that drop completed requests (AUD-005).
+//
+// AUD-003 deliberately does not ask for RequestResponse on Secrets, unlike
+// AUD-004 for RBAC: at Request or RequestResponse the API server writes the
+// Secret values themselves to the audit log, so the expected level is
+// Metadata and anything more detailed is reported.
+func (a *Analyzer) checkAudit(path string, now time.Time) []scanner.Finding {
+	where := a.describePath(path)
+
+	var policy auditPolicy
+	data, err := a.readFile(path)
+	if err == nil {
+		err = utilyaml.Unmarshal(data, &policy)
+	}
+	if err != nil {
+		a.logger.Warn("failed to read audit policy", "path", path, "error", err)
+		return []scanner.Finding{fileFinding("AUD-002", "Audit policy unreadable",
+			fmt.Sprintf("The audit Policy %s could not be read or parsed: %v", where, err),
+			scanner.SeverityMedium, scanner.StatusWarning,
+			"Check that the host root is mounted and the file is a valid audit.k8s.io Policy.",
+			path, now)}
+	}
+
+	var findings []scanner.Finding
+
+	// Secrets: Metadata records who accessed which Secret; Request and
+	// RequestResponse would also copy the Secret values into the audit log.
+	level, rule := policy.effectiveLevel("", "secrets")
+	ruleResources := map[int][]string{rule: {"secrets"}}
+	switch levelRank[level] {
+	case levelRank[levelNone]:
+		f := fileFinding("AUD-003", "Secret access not audited",
+			fmt.Sprintf("The audit Policy %s logs Secret requests at level None, so reads and changes of Secrets leave no audit trail", where),
+			scanner.SeverityHigh, scanner.StatusFail,
+			"Add a rule near the top of the policy that logs secrets (and configmaps and tokenreviews) at level Metadata.",
+			path, now)
+		f.Details["resource"] = "secrets"
+		f.Details["level"] = level
+		findings = append(findings, f)
+	case levelRank[levelRequest], levelRank[levelRequestResponse]:
+		f := fileFinding("AUD-003", "Secret values written to audit log",
+			fmt.Sprintf("The audit Policy %s logs Secret requests at level %s (rule %d), which copies Secret values into the audit log", where, level, rule),
+			scanner.SeverityMedium, scanner.StatusFail,
+			"Log secrets at level Metadata, which records who accessed which Secret without its contents.",
+			path, now)
+		f.Details["resource"] = "secrets"
+		f.Details["level"] = level
+		findings = append(findings, f)
+	}
+
+	// RBAC: changes should be recorded in full to reconstruct who granted
+	// what.
+	var weak []string
+	for _, resource := range rbacResources {
+		level, rule := policy.effectiveLevel("rbac.authorization.k8s.io", resource)
+		ruleResources[rule] = append(ruleResources[rule], resource)
+		if levelRank[level] < levelRank[levelRequestResponse] {
+			weak = append(weak, fmt.Sprintf("%s=%s", resource, level))
+		}
+	}
+	if len(weak) > 0 {
+		f := fileFinding("AUD-004", "RBAC changes not fully audited",
+			fmt.Sprintf("The audit Policy %s logs RBAC resources below RequestResponse (%s), so the permissions granted or removed by a change cannot be reconstructed", where, strings.Join(weak, ", ")),
+			scanner.SeverityMedium, scanner.StatusFail,
+			"Add a rule that logs roles, rolebindings, clusterroles and clusterrolebindings in group rbac.authorization.k8s.io at level RequestResponse, placed before any broader rule.",
+			path, now)
+		f.Details["levels"] = strings.Join(weak, ",")
+		findings = append(findings, f)
+	}
+
+	// Stages: omitting RequestReceived is common to reduce volume, but
+	// ResponseComplete is the stage most events are recorded at, whether
+	// omitted for the whole policy or by the rules applying to Secrets and
+	// RBAC.
+	if slices.Contains(policy.OmitStages, "ResponseComplete") {
+		f := fileFinding("AUD-005", "Audit stages omitted",
+			fmt.Sprintf("The audit Policy %s omits the ResponseComplete stage for every rule, so completed requests are never recorded", where),
+			scanner.SeverityHigh, scanner.StatusFail,
+			"Remove ResponseComplete from omitStages; omit only RequestReceived if audit volume is a concern.",
+			path, now)
+		f.Details["omit_stages"] = strings.Join(policy.OmitStages, ",")
+		findings = append(findings, f)
+	} else if slices.Contains(policy.OmitStages, "Panic") {
+		f := fileFinding("AUD-005", "Audit stages omitted",
+			fmt.Sprintf("The audit Policy %s omits the Panic stage, so requests that crash the API server handler are not recorded", where),
+			scanner.SeverityLow, scanner.StatusWarning,
+			"Remove Panic from omitStages; it is rare and often the only trace of an attack on the API server.",
+			path, now)
+		f.Details["omit_stages"] = strings.Join(policy.OmitStages, ",")
+		findings = append(findings, f)
+	}
+	for i, r := range policy.Rules {
+		resources, ok := ruleResources[i]
+		if !ok || !slices.Contains(r.OmitStages, "ResponseComplete") || slices.Contains(policy.OmitStages, "ResponseComplete") {
+			continue
+		}
+		f := fileFinding("AUD-005", "Audit stages omitted",
+			fmt.Sprintf("In the audit Policy %s, rule %d, which applies to %s, omits the ResponseComplete stage, so completed requests for them are never recorded", where, i, strings.Join(resources, ", ")),
+			scanner.SeverityHigh, scanner.StatusFail,
+			"Remove ResponseComplete from the rule's omitStages.",
+			path, now)
+		f.Details["omit_stages"] = strings.Join(r.OmitStages, ",")
+		f.Details["resources"] = strings.Join(resources, ",")
+		findings = append(findings, f)
+	}
+
+	return findings
+}
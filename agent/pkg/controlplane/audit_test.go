@@ -0,0 +1,178 @@
+package controlplane
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestEffectiveLevel(t *testing.T) {
+	policy := `apiVersion: audit.k8s.io/v1
+kind: Policy
+rules:
+  - level: None
+    users: ["system:kube-proxy"]
+  - level: None
+    nonResourceURLs: ["/healthz*"]
+  - level: RequestResponse
+    resources:
+      - group: ""
+        resources: ["secrets"]
+        resourceNames: ["admin-token"]
+  - level: Metadata
+    resources:
+      - group: ""
+        resources: ["secrets", "configmaps"]
+  - level: RequestResponse
+    resources:
+      - group: rbac.authorization.k8s.io
+  - level: Request
+    resources:
+      - group: "*"
+        resources: ["*"]
+`
+	var p auditPolicy
+	if err := utilyaml.Unmarshal([]byte(policy), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		group, resource string
+		level           string
+		rule            int
+	}{
+		{"", "secrets", levelMetadata, 3},
+		{"", "configmaps", levelMetadata, 3},
+		{"rbac.authorization.k8s.io", "clusterrolebindings", levelRequestResponse, 4},
+		{"apps", "deployments", levelRequest, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.group+"/"+tt.resource, func(t *testing.T) {
+			level, rule := p.effectiveLevel(tt.group, tt.resource)
+			if level != tt.level || rule != tt.rule {
+				t.Errorf("effectiveLevel() = %s (rule %d), want %s (rule %d)", level, rule, tt.level, tt.rule)
+			}
+		})
+	}
+
+	if level, rule := (&auditPolicy{}).effectiveLevel("", "secrets"); level != levelNone || rule != -1 {
+		t.Errorf("effectiveLevel() of empty policy = %s (rule %d), want None (rule -1)", level, rule)
+	}
+}
+
+func TestCheckAudit(t *testing.T) {
+	const rbacFull = `
+  - level: RequestResponse
+    resources:
+      - group: rbac.authorization.k8s.io
+        resources: ["roles", "rolebindings", "clusterroles", "clusterrolebindings"]`
+
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{
+			name: "recommended policy",
+			policy: `apiVersion: audit.k8s.io/v1
+kind: Policy
+omitStages: ["RequestReceived"]
+rules:
+  - level: Metadata
+    resources:
+      - group: ""
+        resources: ["secrets"]` + rbacFull,
+		},
+		{
+			name: "secrets not audited",
+			policy: `kind: Policy
+rules:` + rbacFull,
+			want: []string{"AUD-003 None"},
+		},
+		{
+			// RequestResponse on Secrets copies their values into the log.
+			name: "secret values logged",
+			policy: `kind: Policy
+rules:` + rbacFull + `
+  - level: RequestResponse`,
+			want: []string{"AUD-003 RequestResponse"},
+		},
+		{
+			name: "rbac at metadata",
+			policy: `kind: Policy
+rules:
+  - level: Metadata`,
+			want: []string{"AUD-004 roles=Metadata,rolebindings=Metadata,clusterroles=Metadata,clusterrolebindings=Metadata"},
+		},
+		{
+			name: "response complete omitted",
+			policy: `kind: Policy
+omitStages: ["ResponseComplete"]
+rules:
+  - level: Metadata
+    resources:
+      - group: ""
+        resources: ["secrets"]` + rbacFull,
+			want: []string{"AUD-005 ResponseComplete"},
+		},
+		{
+			name: "panic omitted",
+			policy: `kind: Policy
+omitStages: ["RequestReceived", "Panic"]
+rules:
+  - level: Metadata
+    resources:
+      - group: ""
+        resources: ["secrets"]` + rbacFull,
+			want: []string{"AUD-005 RequestReceived,Panic"},
+		},
+		{
+			name: "rule omits response complete",
+			policy: `kind: Policy
+rules:
+  - level: Metadata
+    omitStages: ["ResponseComplete"]
+    resources:
+      - group: ""
+        resources: ["secrets"]` + rbacFull,
+			want: []string{"AUD-005 ResponseComplete"},
+		},
+		{
+			name:   "unparseable",
+			policy: "rules: [",
+			want:   []string{"AUD-002 "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit-policy.yaml")
+			if err := os.WriteFile(path, []byte(tt.policy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, f := range NewAnalyzer(nil, nil).checkAudit(path, time.Now()) {
+				detail := f.Details["level"] + f.Details["levels"] + f.Details["omit_stages"]
+				got = append(got, f.ID+" "+detail)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkAudit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		findings := NewAnalyzer(nil, nil).checkAudit(filepath.Join(t.TempDir(), "absent.yaml"), time.Now())
+		if len(findings) != 1 || findings[0].ID != "AUD-002" {
+			t.Errorf("checkAudit() = %v, want a single AUD-002", findings)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package controlplane
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// encryptionConfiguration is the part of an apiserver.config.k8s.io
+// EncryptionConfiguration the checks need.
+type encryptionConfiguration struct {
+	Kind      string `json:"kind"`
+	Resources []struct {
+		Resources []string             `json:"resources"`
+		Providers []encryptionProvider `json:"providers"`
+	} `json:"resources"`
+}
+
+// encryptionProvider holds exactly one provider configuration.
+type encryptionProvider struct {
+	Identity  *struct{} `json:"identity"`
+	AESCBC    *struct{} `json:"aescbc"`
+	AESGCM    *struct{} `json:"aesgcm"`
+	Secretbox *struct{} `json:"secretbox"`
+	KMS       *struct {
+		Name       string `json:"name"`
+		APIVersion string `json:"apiVersion"`
+	} `json:"kms"`
+}
+
+// name returns the provider type, with the KMS plugin API version.
+func (p encryptionProvider) name() string {
+	switch {
+	case p.Identity != nil:
+		return "identity"
+	case p.AESCBC != nil:
+		return "aescbc"
+	case p.AESGCM != nil:
+		return "aesgcm"
+	case p.Secretbox != nil:
+		return "secretbox"
+	case p.KMS != nil:
+		if p.KMS.APIVersion == "v2" {
+			return "kms v2"
+		}
+		return "kms v1"
+	}
+	return "unknown"
+}
+
+// coversSecrets reports whether a resources entry applies to Secrets.
+func coversSecrets(resources []string) bool {
+	for _, r := range resources {
+		if r == "secrets" || r == "*." || r == "*.*" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEncryption parses the EncryptionConfiguration and reports an
+// unreadable file (ENC-002), Secrets missing from it (ENC-003), identity as
+// the first, writing, provider (ENC-004), local-key providers where a KMS
+// should be used (ENC-005) and the deprecated KMS v1 API (ENC-006).
+func (a *Analyzer) checkEncryption(path string, now time.Time) []scanner.Finding {
+	where := a.describePath(path)
+
+	var cfg encryptionConfiguration
+	data, err := a.readFile(path)
+	if err == nil {
+		err = utilyaml.Unmarshal(data, &cfg)
+	}
+	if err != nil {
+		a.logger.Warn("failed to read encryption configuration", "path", path, "error", err)
+		return []scanner.Finding{fileFinding("ENC-002", "Encryption configuration unreadable",
+			fmt.Sprintf("The EncryptionConfiguration %s could not be read or parsed: %v", where, err),
+			scanner.SeverityMedium, scanner.StatusWarning,
+			"Check that the host root is mounted and the file is a valid apiserver.config.k8s.io EncryptionConfiguration.",
+			path, now)}
+	}
+
+	var findings []scanner.Finding
+	secretsCovered := false
+	for i, entry := range cfg.Resources {
+		if len(entry.Providers) == 0 {
+			continue
+		}
+		// Only the first entry matching a resource applies to it.
+		secrets := !secretsCovered && coversSecrets(entry.Resources)
+		if secrets {
+			secretsCovered = true
+		}
+		resources := strings.Join(entry.Resources, ", ")
+		first := entry.Providers[0]
+
+		switch name := first.name(); {
+		case name == "identity":
+			severity := scanner.SeverityMedium
+			if secrets {
+				severity = scanner.SeverityHigh
+			}
+			f := fileFinding("ENC-004", "Encryption disabled by identity provider",
+				fmt.Sprintf("In %s, resources entry %d (%s) lists identity as its first provider, so new and updated objects are written to etcd unencrypted", where, i, resources),
+				severity, scanner.StatusFail,
+				"Move identity to the end of the providers list, after a kms (v2), aesgcm or aescbc provider, and rewrite the affected objects so they are re-encrypted.",
+				path, now)
+			f.Details["resources"] = strings.Join(entry.Resources, ",")
+			findings = append(findings, f)
+
+		case name == "aescbc" || name == "aesgcm" || name == "secretbox":
+			severity := scanner.SeverityLow
+			if name == "aescbc" {
+				severity = scanner.SeverityMedium
+			}
+			f := fileFinding("ENC-005", "Encryption keys stored on control plane",
+				fmt.Sprintf("In %s, resources entry %d (%s) encrypts with %s, whose key is stored in the configuration file on every control plane node; anyone who obtains the file and an etcd backup can decrypt the data", where, i, resources, name),
+				severity, scanner.StatusWarning,
+				"Use a kms provider with apiVersion v2 backed by an external key management service, so the key-encryption key never leaves the KMS. aescbc is also vulnerable to padding oracle attacks and is no longer recommended.",
+				path, now)
+			f.Details["resources"] = strings.Join(entry.Resources, ",")
+			f.Details["provider"] = name
+			findings = append(findings, f)
+
+		case name == "kms v1":
+			f := fileFinding("ENC-006", "Deprecated KMS v1 provider",
+				fmt.Sprintf("In %s, resources entry %d (%s) encrypts with KMS plugin %q using the deprecated KMS v1 API", where, i, resources, first.KMS.Name),
+				scanner.SeverityLow, scanner.StatusWarning,
+				"Migrate the KMS plugin to apiVersion v2, which is faster, supports key rotation without re-encrypting every object, and is the only KMS API still developed.",
+				path, now)
+			f.Details["resources"] = strings.Join(entry.Resources, ",")
+			findings = append(findings, f)
+		}
+	}
+
+	if !secretsCovered {
+		findings = append(findings, fileFinding("ENC-003", "Secrets not encrypted at rest",
+			fmt.Sprintf("The EncryptionConfiguration %s has no resources entry for secrets, so Secrets are stored in etcd in plaintext", where),
+			scanner.SeverityHigh, scanner.StatusFail,
+			"Add a resources entry for secrets with a kms (v2) provider first and identity last, then rewrite existing Secrets so they are encrypted.",
+			path, now))
+	}
+
+	return findings
+}
@@ -0,0 +1,185 @@
+// Package governance checks that namespaces carry the guard rails and
+// metadata expected of a multi-tenant cluster: resource quotas, default
+// limits, ownership labels, and no access granted to namespaces that run
+// nothing.
+package governance
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// DefaultRequiredLabels are the namespace labels required when none are
+// configured.
+var DefaultRequiredLabels = []string{"owner", "cost-center"}
+
+// Analyzer evaluates namespace governance.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client         *k8s.Client
+	logger         *slog.Logger
+	requiredLabels []string
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithRequiredLabels sets the label keys every namespace must carry with a
+// non-empty value (GOV-003). With no keys the check is disabled.
+func WithRequiredLabels(keys ...string) Option {
+	return func(a *Analyzer) {
+		a.requiredLabels = nil
+		for _, k := range keys {
+			if k != "" {
+				a.requiredLabels = append(a.requiredLabels, k)
+			}
+		}
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "governance" }
+
+// NewAnalyzer creates a new namespace governance analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client:         client,
+		logger:         logger,
+		requiredLabels: append([]string(nil), DefaultRequiredLabels...),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// namespaceState holds what the checks need to know about one namespace.
+type namespaceState struct {
+	namespace    *corev1.Namespace
+	quotas       int
+	limitRanges  int
+	workloads    int
+	roleBindings []rbacv1.RoleBinding
+}
+
+// Analyze runs all governance checks and returns findings.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting governance analysis")
+
+	states, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	for _, st := range states {
+		// Check 1: ResourceQuota present.
+		findings = append(findings, a.checkQuota(st, now)...)
+
+		// Check 2: LimitRange present.
+		findings = append(findings, a.checkLimitRange(st, now)...)
+
+		// Check 3: Required ownership labels present.
+		findings = append(findings, a.checkLabels(st, now)...)
+
+		// Check 4: RoleBindings in a namespace without workloads.
+		findings = append(findings, a.checkIdleAccess(st, now)...)
+	}
+
+	a.logger.Info("governance analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect gathers the quotas, limit ranges, workloads and RoleBindings of
+// each namespace. Namespaces that do not exist are skipped.
+func (a *Analyzer) collect(ctx context.Context, namespaces []string) ([]*namespaceState, error) {
+	all, err := a.client.ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*corev1.Namespace, len(all))
+	for i := range all {
+		byName[all[i].Name] = &all[i]
+	}
+
+	var states []*namespaceState
+	for _, ns := range namespaces {
+		namespace, ok := byName[ns]
+		if !ok {
+			a.logger.Warn("namespace not found", "namespace", ns)
+			continue
+		}
+		st := &namespaceState{namespace: namespace}
+
+		quotas, err := a.client.ListResourceQuotas(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list resource quotas", "namespace", ns, "error", err)
+			continue
+		}
+		st.quotas = len(quotas)
+
+		limitRanges, err := a.client.ListLimitRanges(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list limit ranges", "namespace", ns, "error", err)
+			continue
+		}
+		st.limitRanges = len(limitRanges)
+
+		st.workloads, err = a.countWorkloads(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list workloads", "namespace", ns, "error", err)
+			continue
+		}
+
+		st.roleBindings, err = a.client.ListRoleBindings(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list role bindings", "namespace", ns, "error", err)
+			continue
+		}
+
+		states = append(states, st)
+	}
+	return states, nil
+}
+
+// countWorkloads counts the pods and workload controllers of a namespace.
+// Controllers scaled to zero still count: the namespace is in use.
+func (a *Analyzer) countWorkloads(ctx context.Context, ns string) (int, error) {
+	pods, err := a.client.ListPods(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	deployments, err := a.client.ListDeployments(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	statefulsets, err := a.client.ListStatefulSets(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	daemonsets, err := a.client.ListDaemonSets(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	cronjobs, err := a.client.ListCronJobs(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	jobs, err := a.client.ListJobs(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	return len(pods) + len(deployments) + len(statefulsets) + len(daemonsets) + len(cronjobs) + len(jobs), nil
+}
@@ -0,0 +1,129 @@
+package governance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// privilegedClusterRoles are the default ClusterRoles that grant write
+// access to most of a namespace.
+var privilegedClusterRoles = map[string]bool{
+	"cluster-admin": true,
+	"admin":         true,
+	"edit":          true,
+}
+
+// checkQuota reports namespaces without a ResourceQuota (GOV-001), whose
+// workloads can consume the cluster's capacity without bound.
+func (a *Analyzer) checkQuota(st *namespaceState, now time.Time) []scanner.Finding {
+	if st.quotas > 0 {
+		return nil
+	}
+	return []scanner.Finding{{
+		ID:          "GOV-001",
+		Title:       "Namespace without ResourceQuota",
+		Description: fmt.Sprintf("Namespace %s has no ResourceQuota, so its workloads can consume CPU, memory and object counts without limit", st.namespace.Name),
+		Severity:    scanner.SeverityMedium,
+		Status:      scanner.StatusFail,
+		Category:    "governance",
+		Resource:    "Namespace/" + st.namespace.Name,
+		Namespace:   st.namespace.Name,
+		Remediation: "Add a ResourceQuota capping requests.cpu, requests.memory, limits.memory and object counts such as pods and services for the namespace.",
+		Timestamp:   now,
+	}}
+}
+
+// checkLimitRange reports namespaces without a LimitRange (GOV-002), where
+// containers that omit requests and limits get none.
+func (a *Analyzer) checkLimitRange(st *namespaceState, now time.Time) []scanner.Finding {
+	if st.limitRanges > 0 {
+		return nil
+	}
+	return []scanner.Finding{{
+		ID:          "GOV-002",
+		Title:       "Namespace without LimitRange",
+		Description: fmt.Sprintf("Namespace %s has no LimitRange, so containers that omit resource requests and limits get no defaults and run unbounded", st.namespace.Name),
+		Severity:    scanner.SeverityLow,
+		Status:      scanner.StatusFail,
+		Category:    "governance",
+		Resource:    "Namespace/" + st.namespace.Name,
+		Namespace:   st.namespace.Name,
+		Remediation: "Add a LimitRange with default and defaultRequest values for containers, and max values matching the namespace's quota.",
+		Timestamp:   now,
+	}}
+}
+
+// checkLabels reports namespaces missing a required label or carrying it
+// with an empty value (GOV-003).
+func (a *Analyzer) checkLabels(st *namespaceState, now time.Time) []scanner.Finding {
+	var missing []string
+	for _, key := range a.requiredLabels {
+		if st.namespace.Labels[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []scanner.Finding{{
+		ID:          "GOV-003",
+		Title:       "Namespace missing ownership labels",
+		Description: fmt.Sprintf("Namespace %s is missing the required label(s) %s, so it cannot be attributed to an owner", st.namespace.Name, strings.Join(missing, ", ")),
+		Severity:    scanner.SeverityLow,
+		Status:      scanner.StatusWarning,
+		Category:    "governance",
+		Resource:    "Namespace/" + st.namespace.Name,
+		Namespace:   st.namespace.Name,
+		Remediation: fmt.Sprintf("Label the namespace, e.g. kubectl label namespace %s %s=<value>, and enforce the labels at creation with an admission policy.", st.namespace.Name, missing[0]),
+		Details: map[string]string{
+			"missing_labels": strings.Join(missing, ","),
+		},
+		Timestamp: now,
+	}}
+}
+
+// checkIdleAccess reports namespaces with RoleBindings but no workloads
+// (GOV-004). Access granted to an unused namespace is standing privilege
+// nobody needs, often left behind after a team or application moved on.
+// Bindings named system:* are created by Kubernetes and ignored.
+func (a *Analyzer) checkIdleAccess(st *namespaceState, now time.Time) []scanner.Finding {
+	if st.workloads > 0 {
+		return nil
+	}
+
+	var bindings, roles []string
+	severity := scanner.SeverityMedium
+	for _, rb := range st.roleBindings {
+		if strings.HasPrefix(rb.Name, "system:") {
+			continue
+		}
+		bindings = append(bindings, rb.Name)
+		roles = append(roles, rb.RoleRef.Kind+"/"+rb.RoleRef.Name)
+		if rb.RoleRef.Kind == "ClusterRole" && privilegedClusterRoles[rb.RoleRef.Name] {
+			severity = scanner.SeverityHigh
+		}
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	return []scanner.Finding{{
+		ID:          "GOV-004",
+		Title:       "Privileged namespace without workloads",
+		Description: fmt.Sprintf("Namespace %s runs no workloads but has %d RoleBinding(s) (%s) granting access to it", st.namespace.Name, len(bindings), strings.Join(bindings, ", ")),
+		Severity:    severity,
+		Status:      scanner.StatusWarning,
+		Category:    "governance",
+		Resource:    "Namespace/" + st.namespace.Name,
+		Namespace:   st.namespace.Name,
+		Remediation: "Delete the namespace if it is no longer used, or remove the RoleBindings until workloads are deployed to it.",
+		Details: map[string]string{
+			"role_bindings": strings.Join(bindings, ","),
+			"roles":         strings.Join(roles, ","),
+		},
+		Timestamp: now,
+	}}
+}
@@ -0,0 +1,151 @@
+package governance
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+func TestAnalyze(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "guard", Namespace: "shop"}
+	labeled := map[string]string{"owner": "payments", "cost-center": "cc-42"}
+	namespace := func(labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: labels}}
+	}
+	guarded := func(labels map[string]string, objs ...runtime.Object) []runtime.Object {
+		return append([]runtime.Object{
+			namespace(labels),
+			&corev1.ResourceQuota{ObjectMeta: meta},
+			&corev1.LimitRange{ObjectMeta: meta},
+		}, objs...)
+	}
+	binding := func(name, kind, role string) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{Kind: kind, Name: role},
+		}
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+
+	tests := []struct {
+		name    string
+		objs    []runtime.Object
+		opts    []Option
+		want    map[string]scanner.Severity
+		details map[string]string
+	}{
+		{
+			name: "compliant",
+			objs: guarded(labeled, deployment, binding("devs", "ClusterRole", "edit")),
+			want: map[string]scanner.Severity{},
+		},
+		{
+			name: "no quota or limit range",
+			objs: []runtime.Object{namespace(labeled), deployment},
+			want: map[string]scanner.Severity{"GOV-001": scanner.SeverityMedium, "GOV-002": scanner.SeverityLow},
+		},
+		{
+			name:    "missing label",
+			objs:    guarded(map[string]string{"owner": "payments", "cost-center": ""}, deployment),
+			want:    map[string]scanner.Severity{"GOV-003": scanner.SeverityLow},
+			details: map[string]string{"missing_labels": "cost-center"},
+		},
+		{
+			name: "custom required labels",
+			objs: guarded(map[string]string{"team": "payments"}, deployment),
+			opts: []Option{WithRequiredLabels("team")},
+			want: map[string]scanner.Severity{},
+		},
+		{
+			name: "label check disabled",
+			objs: guarded(nil, deployment),
+			opts: []Option{WithRequiredLabels()},
+			want: map[string]scanner.Severity{},
+		},
+		{
+			name:    "idle namespace with a custom role",
+			objs:    guarded(labeled, binding("readers", "Role", "viewer")),
+			want:    map[string]scanner.Severity{"GOV-004": scanner.SeverityMedium},
+			details: map[string]string{"role_bindings": "readers", "roles": "Role/viewer"},
+		},
+		{
+			name: "idle namespace with view",
+			objs: guarded(labeled, binding("readers", "ClusterRole", "view")),
+			want: map[string]scanner.Severity{"GOV-004": scanner.SeverityMedium},
+		},
+		{
+			name: "idle namespace with admin",
+			objs: guarded(labeled, binding("owners", "ClusterRole", "admin")),
+			want: map[string]scanner.Severity{"GOV-004": scanner.SeverityHigh},
+		},
+		{
+			name: "idle namespace with edit",
+			objs: guarded(labeled, binding("devs", "ClusterRole", "edit")),
+			want: map[string]scanner.Severity{"GOV-004": scanner.SeverityHigh},
+		},
+		{
+			name:    "idle namespace with cluster-admin",
+			objs:    guarded(labeled, binding("readers", "ClusterRole", "view"), binding("ops", "ClusterRole", "cluster-admin")),
+			want:    map[string]scanner.Severity{"GOV-004": scanner.SeverityHigh},
+			details: map[string]string{"role_bindings": "ops,readers", "roles": "ClusterRole/cluster-admin,ClusterRole/view"},
+		},
+		{
+			name: "local Role named admin",
+			objs: guarded(labeled, binding("owners", "Role", "admin")),
+			want: map[string]scanner.Severity{"GOV-004": scanner.SeverityMedium},
+		},
+		{
+			name: "system bindings only",
+			objs: guarded(labeled, binding("system:controller:bootstrap-signer", "Role", "system:controller:bootstrap-signer")),
+			want: map[string]scanner.Severity{},
+		},
+		{
+			name:    "system bindings ignored",
+			objs:    guarded(labeled, binding("system:deployers", "ClusterRole", "admin"), binding("readers", "ClusterRole", "view")),
+			want:    map[string]scanner.Severity{"GOV-004": scanner.SeverityMedium},
+			details: map[string]string{"role_bindings": "readers", "roles": "ClusterRole/view"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8s.NewClientFromInterface(fake.NewSimpleClientset(tt.objs...), "test", nil)
+			findings, err := NewAnalyzer(client, nil, tt.opts...).Analyze(context.Background(), []string{"shop", "missing"})
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+
+			got := make(map[string]scanner.Severity, len(findings))
+			for _, f := range findings {
+				got[f.ID] = f.Severity
+				if f.Category != "governance" || f.Resource != "Namespace/shop" || f.Namespace != "shop" {
+					t.Errorf("%s: category/resource/namespace = %q/%q/%q", f.ID, f.Category, f.Resource, f.Namespace)
+				}
+				for key, want := range tt.details {
+					if v := f.Details[key]; v != want {
+						t.Errorf("%s: details[%s] = %q, want %q", f.ID, key, v, want)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				ids := make([]string, 0, len(findings))
+				for _, f := range findings {
+					ids = append(ids, f.ID+"/"+string(f.Severity))
+				}
+				sort.Strings(ids)
+				t.Errorf("findings = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Package graph provides a small directed-graph model with DOT, Mermaid and
+// JSON renderers, used to export RBAC and network topologies for review.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Format represents a supported graph output format.
+type Format string
+
+const (
+	FormatDOT     Format = "dot"
+	FormatMermaid Format = "mermaid"
+	FormatJSON    Format = "json"
+)
+
+// ParseFormat converts a string to a Format, returning an error for invalid values.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatDOT:
+		return FormatDOT, nil
+	case FormatMermaid:
+		return FormatMermaid, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported graph format: %q (valid: dot, mermaid, json)", s)
+	}
+}
+
+// Node is a vertex in the graph.
+type Node struct {
+	// ID uniquely identifies the node within the graph.
+	ID string `json:"id"`
+
+	// Label is the human-readable text rendered for the node.
+	Label string `json:"label"`
+
+	// Kind classifies the node (e.g. "subject", "role", "namespace").
+	Kind string `json:"kind"`
+
+	// Group clusters related nodes together (e.g. by namespace).
+	Group string `json:"group,omitempty"`
+
+	// Shape is a rendering hint: "box" (default), "ellipse" or "note".
+	Shape string `json:"shape,omitempty"`
+
+	// Color is the fill color as a hex string. Empty means the renderer default.
+	Color string `json:"color,omitempty"`
+
+	// Highlight marks nodes that are involved in findings.
+	Highlight bool `json:"highlight,omitempty"`
+
+	// Details carries additional attributes such as related finding IDs.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Edge is a directed connection between two nodes.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+}
+
+// Graph is a directed graph of nodes and edges. Nodes keep insertion order.
+type Graph struct {
+	Name  string  `json:"name"`
+	Nodes []*Node `json:"nodes"`
+	Edges []Edge  `json:"edges"`
+
+	index map[string]*Node
+	edges map[Edge]bool
+}
+
+// New creates an empty graph with the given name.
+func New(name string) *Graph {
+	return &Graph{
+		Name:  name,
+		Nodes: []*Node{},
+		Edges: []Edge{},
+		index: make(map[string]*Node),
+		edges: make(map[Edge]bool),
+	}
+}
+
+// AddNode adds a node unless a node with the same ID exists, and returns the
+// node stored in the graph.
+func (g *Graph) AddNode(n Node) *Node {
+	if existing, ok := g.index[n.ID]; ok {
+		return existing
+	}
+	node := n
+	g.index[n.ID] = &node
+	g.Nodes = append(g.Nodes, &node)
+	return &node
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.index[id]
+}
+
+// AddEdge adds a directed edge. Duplicate edges are ignored.
+func (g *Graph) AddEdge(from, to, label string) {
+	e := Edge{From: from, To: to, Label: label}
+	if g.edges[e] {
+		return
+	}
+	g.edges[e] = true
+	g.Edges = append(g.Edges, e)
+}
+
+// Write renders the graph in the requested format.
+func Write(w io.Writer, g *Graph, format Format) error {
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, g)
+	case FormatMermaid:
+		return writeMermaid(w, g)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(g); err != nil {
+			return fmt.Errorf("encoding graph JSON: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported graph format: %q", format)
+	}
+}
+
+// groups returns the distinct non-empty node groups in sorted order.
+func (g *Graph) groups() []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, n := range g.Nodes {
+		if n.Group != "" && !seen[n.Group] {
+			seen[n.Group] = true
+			result = append(result, n.Group)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// writeDOT renders the graph in Graphviz DOT format.
+func writeDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	writeNode := func(indent string, n *Node) {
+		attrs := []string{"label=" + dotQuote(n.Label)}
+		switch n.Shape {
+		case "ellipse":
+			attrs = append(attrs, "shape=ellipse")
+		case "note":
+			attrs = append(attrs, "shape=note")
+		}
+		if n.Color != "" {
+			attrs = append(attrs, "fillcolor="+dotQuote(n.Color))
+		}
+		if n.Highlight {
+			attrs = append(attrs, "color=\"#dc2626\"", "penwidth=3")
+		}
+		fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+
+	for i, group := range g.groups() {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(group))
+		for _, n := range g.Nodes {
+			if n.Group == group {
+				writeNode("    ", n)
+			}
+		}
+		b.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		if n.Group == "" {
+			writeNode("  ", n)
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Label))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote returns s as a double-quoted DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// writeMermaid renders the graph as a Mermaid flowchart.
+func writeMermaid(w io.Writer, g *Graph) error {
+	var b strings.Builder
+
+	// Mermaid IDs must be simple identifiers, so nodes are renumbered.
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	writeNode := func(indent string, n *Node) {
+		label := mermaidEscape(n.Label)
+		switch n.Shape {
+		case "ellipse":
+			fmt.Fprintf(&b, "%s%s([\"%s\"])\n", indent, ids[n.ID], label)
+		case "note":
+			fmt.Fprintf(&b, "%s%s[/\"%s\"/]\n", indent, ids[n.ID], label)
+		default:
+			fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, ids[n.ID], label)
+		}
+	}
+
+	b.WriteString("flowchart LR\n")
+	for i, group := range g.groups() {
+		fmt.Fprintf(&b, "  subgraph g%d[\"%s\"]\n", i, mermaidEscape(group))
+		for _, n := range g.Nodes {
+			if n.Group == group {
+				writeNode("    ", n)
+			}
+		}
+		b.WriteString("  end\n")
+	}
+	for _, n := range g.Nodes {
+		if n.Group == "" {
+			writeNode("  ", n)
+		}
+	}
+
+	for _, e := range g.Edges {
+		from, okFrom := ids[e.From]
+		to, okTo := ids[e.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", from, mermaidEscape(e.Label), to)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", from, to)
+		}
+	}
+
+	for _, n := range g.Nodes {
+		var styles []string
+		if n.Color != "" {
+			styles = append(styles, "fill:"+n.Color)
+		}
+		if n.Highlight {
+			styles = append(styles, "stroke:#dc2626", "stroke-width:3px")
+		}
+		if len(styles) > 0 {
+			fmt.Fprintf(&b, "  style %s %s\n", ids[n.ID], strings.Join(styles, ","))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape replaces characters that break Mermaid label syntax.
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "\n", "<br/>")
+	return s
+}
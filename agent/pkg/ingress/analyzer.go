@@ -0,0 +1,186 @@
+// Package ingress analyzes networking.k8s.io Ingresses and Gateway API
+// Gateways and HTTPRoutes for TLS, hostname, TLS Secret, annotation and
+// cross-namespace attachment issues.
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Analyzer evaluates Ingress and Gateway API resources.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client               *k8s.Client
+	logger               *slog.Logger
+	dangerousAnnotations map[string]string
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithDangerousAnnotations adds Ingress annotations to report (ING-005),
+// keyed by annotation name with the reason as value.
+func WithDangerousAnnotations(annotations map[string]string) Option {
+	return func(a *Analyzer) {
+		for k, v := range annotations {
+			a.dangerousAnnotations[k] = v
+		}
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "ingress" }
+
+// NewAnalyzer creates a new Ingress and Gateway API analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client:               client,
+		logger:               logger,
+		dangerousAnnotations: make(map[string]string, len(defaultDangerousAnnotations)),
+	}
+	for k, v := range defaultDangerousAnnotations {
+		a.dangerousAnnotations[k] = v
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// inventory holds the objects fetched for a single analysis run.
+type inventory struct {
+	ingresses []networkingv1.Ingress
+	routes    []k8s.HTTPRoute
+	// gateways are the Gateways in the scanned namespaces.
+	gateways []k8s.Gateway
+	// gatewaysByKey holds every Gateway in the cluster keyed by
+	// "namespace/name", since routes may attach across namespaces.
+	gatewaysByKey   map[string]*k8s.Gateway
+	namespaceLabels map[string]map[string]string
+}
+
+// Analyze runs all Ingress and Gateway API checks and returns findings.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting ingress analysis")
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	inv, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check 1: Ingress hosts and HTTPRoutes served without TLS.
+	findings = append(findings, a.checkIngressTLS(inv.ingresses, now)...)
+	findings = append(findings, a.checkRouteTLS(inv, now)...)
+
+	// Check 2: Wildcard and catch-all hostnames.
+	findings = append(findings, a.checkWildcardHosts(inv, now)...)
+
+	// Check 3: TLS Secrets that do not exist.
+	findings = append(findings, a.checkTLSSecrets(ctx, inv, now)...)
+
+	// Check 4: Controller annotations that inject raw configuration.
+	findings = append(findings, a.checkAnnotations(inv.ingresses, now)...)
+
+	// Check 5: Listeners open to every namespace and cross-namespace routes.
+	findings = append(findings, a.checkCrossNamespace(inv, now)...)
+
+	a.logger.Info("ingress analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect lists the Ingresses and HTTPRoutes of the given namespaces and all
+// Gateways. The Gateway API is optional; without its CRDs only Ingresses are
+// analyzed.
+func (a *Analyzer) collect(ctx context.Context, namespaces []string) (*inventory, error) {
+	inv := &inventory{
+		gatewaysByKey:   make(map[string]*k8s.Gateway),
+		namespaceLabels: make(map[string]map[string]string),
+	}
+
+	allNamespaces, err := a.client.ListNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing namespaces: %w", err)
+	}
+	for _, ns := range allNamespaces {
+		set := make(map[string]string, len(ns.Labels)+1)
+		for k, v := range ns.Labels {
+			set[k] = v
+		}
+		set["kubernetes.io/metadata.name"] = ns.Name
+		inv.namespaceLabels[ns.Name] = set
+	}
+
+	scanNS := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		scanNS[ns] = true
+
+		ingresses, err := a.client.ListIngresses(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list ingresses", "namespace", ns, "error", err)
+		}
+		inv.ingresses = append(inv.ingresses, ingresses...)
+
+		routes, err := a.client.ListHTTPRoutes(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list HTTPRoutes", "namespace", ns, "error", err)
+		}
+		inv.routes = append(inv.routes, routes...)
+	}
+
+	gateways, err := a.client.ListGateways(ctx, "")
+	if err != nil {
+		a.logger.Warn("failed to list gateways", "error", err)
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].Namespace+"/"+gateways[i].Name < gateways[j].Namespace+"/"+gateways[j].Name
+	})
+	for i := range gateways {
+		inv.gatewaysByKey[gateways[i].Namespace+"/"+gateways[i].Name] = &gateways[i]
+		if scanNS[gateways[i].Namespace] {
+			inv.gateways = append(inv.gateways, gateways[i])
+		}
+	}
+
+	return inv, nil
+}
+
+// parentGateways resolves the Gateways a route is attached to and the
+// listeners that accept it, keyed by "namespace/name" in parent order.
+func (inv *inventory) parentGateways(route *k8s.HTTPRoute) ([]string, map[string][]k8s.GatewayListener) {
+	var keys []string
+	listeners := make(map[string][]k8s.GatewayListener)
+	for _, ref := range route.Spec.ParentRefs {
+		if !ref.IsGateway() {
+			continue
+		}
+		key := route.ParentNamespace(ref) + "/" + ref.Name
+		gw := inv.gatewaysByKey[key]
+		if gw == nil {
+			continue
+		}
+		attached := AttachedListeners(route, ref, gw, inv.namespaceLabels[route.Namespace])
+		if len(attached) == 0 {
+			continue
+		}
+		if _, ok := listeners[key]; !ok {
+			keys = append(keys, key)
+		}
+		listeners[key] = append(listeners[key], attached...)
+	}
+	return keys, listeners
+}
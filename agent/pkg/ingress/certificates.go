@@ -0,0 +1,113 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// tlsSecretRefs returns the TLS Secrets referenced by Ingresses and by
+// terminating Gateway listeners, keyed by "namespace/name", with the objects
+// that reference each Secret.
+func tlsSecretRefs(inv *inventory) map[string][]string {
+	refs := make(map[string][]string)
+	add := func(key, by string) {
+		for _, existing := range refs[key] {
+			if existing == by {
+				return
+			}
+		}
+		refs[key] = append(refs[key], by)
+	}
+
+	for i := range inv.ingresses {
+		ing := &inv.ingresses[i]
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName != "" {
+				add(ing.Namespace+"/"+tls.SecretName, fmt.Sprintf("Ingress/%s/%s", ing.Namespace, ing.Name))
+			}
+		}
+	}
+
+	for i := range inv.gateways {
+		gw := &inv.gateways[i]
+		for _, l := range gw.Spec.Listeners {
+			if l.TLS == nil || (l.TLS.Mode != "" && l.TLS.Mode != "Terminate") {
+				continue
+			}
+			for _, ref := range l.TLS.CertificateRefs {
+				if (ref.Group != "" && ref.Group != "core") || (ref.Kind != "" && ref.Kind != "Secret") {
+					continue
+				}
+				ns := ref.Namespace
+				if ns == "" {
+					ns = gw.Namespace
+				}
+				add(ns+"/"+ref.Name, fmt.Sprintf("Gateway/%s/%s", gw.Namespace, gw.Name))
+			}
+		}
+	}
+
+	return refs
+}
+
+// checkTLSSecrets reports TLS Secrets referenced by Ingresses and Gateway
+// listeners that do not exist (ING-004). Only Secret metadata is read; expired
+// and expiring certificates in existing Secrets are CERT-001 and CERT-002 of
+// the certificates analyzer, which reads tls.crt only with WithTLSSecrets.
+func (a *Analyzer) checkTLSSecrets(ctx context.Context, inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	refs := tlsSecretRefs(inv)
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Secret names per namespace, nil where they could not be listed.
+	existing := make(map[string]map[string]bool)
+	for _, key := range keys {
+		ns, name, _ := strings.Cut(key, "/")
+		names, listed := existing[ns]
+		if !listed {
+			secrets, err := a.client.ListSecretMetadata(ctx, ns, "")
+			if err != nil {
+				a.logger.Warn("failed to list secret metadata", "namespace", ns, "error", err)
+			} else {
+				names = make(map[string]bool, len(secrets))
+				for _, s := range secrets {
+					names[s.Name] = true
+				}
+			}
+			existing[ns] = names
+		}
+		if names == nil || names[name] {
+			continue
+		}
+
+		referencedBy := strings.Join(refs[key], ",")
+		findings = append(findings, scanner.Finding{
+			ID:          "ING-004",
+			Title:       "TLS Secret missing",
+			Description: fmt.Sprintf("TLS Secret %s referenced by %s does not exist", key, referencedBy),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "ingress",
+			Resource:    "Secret/" + key,
+			Namespace:   ns,
+			Remediation: "Create the Secret as type kubernetes.io/tls with a PEM certificate chain in tls.crt, or fix the reference. Controllers fall back to a default certificate that clients will reject.",
+			Details: map[string]string{
+				"secret":        key,
+				"referenced_by": referencedBy,
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,343 @@
+package ingress
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// defaultDangerousAnnotations are controller annotations that inject raw
+// proxy configuration or have been used to break out of the controller.
+// Their values are never copied into findings.
+var defaultDangerousAnnotations = map[string]string{
+	"nginx.ingress.kubernetes.io/configuration-snippet": "injects raw NGINX configuration into the location block; it can read the controller's ServiceAccount token and every Secret it can see (CVE-2021-25742)",
+	"nginx.ingress.kubernetes.io/server-snippet":        "injects raw NGINX configuration into the server block (CVE-2021-25742)",
+	"nginx.ingress.kubernetes.io/auth-snippet":          "injects raw NGINX configuration into the auth location (CVE-2021-25742)",
+	"nginx.ingress.kubernetes.io/stream-snippet":        "injects raw NGINX stream configuration (CVE-2021-25742)",
+	"nginx.ingress.kubernetes.io/modsecurity-snippet":   "injects raw ModSecurity configuration",
+	"nginx.ingress.kubernetes.io/auth-url":              "is templated into NGINX configuration and allowed configuration injection on unpatched controllers (CVE-2025-24514)",
+	"nginx.ingress.kubernetes.io/auth-tls-match-cn":     "is templated into NGINX configuration and allowed configuration injection on unpatched controllers (CVE-2025-1097)",
+	"nginx.ingress.kubernetes.io/mirror-target":         "is templated into NGINX configuration and allowed configuration injection on unpatched controllers (CVE-2025-1098)",
+	"nginx.ingress.kubernetes.io/mirror-host":           "is templated into NGINX configuration and allowed configuration injection on unpatched controllers (CVE-2025-1098)",
+	"nginx.org/server-snippets":                         "injects raw NGINX configuration into the server block",
+	"nginx.org/location-snippets":                       "injects raw NGINX configuration into location blocks",
+	"haproxy.org/backend-config-snippet":                "injects raw HAProxy configuration into the backend section",
+}
+
+// ingressHosts returns the hosts of an Ingress's HTTP rules, with "" for
+// rules and a default backend that match any host.
+func ingressHosts(ing *networkingv1.Ingress) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	add := func(host string) {
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	if ing.Spec.DefaultBackend != nil {
+		add("")
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP != nil {
+			add(rule.Host)
+		}
+	}
+	return hosts
+}
+
+// displayHost renders the catch-all host as "*".
+func displayHost(host string) string {
+	if host == "" {
+		return "*"
+	}
+	return host
+}
+
+// checkIngressTLS reports Ingresses that serve hosts over plain HTTP (ING-001).
+func (a *Analyzer) checkIngressTLS(ingresses []networkingv1.Ingress, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		var plain []string
+		for _, host := range ingressHosts(ing) {
+			if !HostHasTLS(ing, host) {
+				plain = append(plain, displayHost(host))
+			}
+		}
+		if len(plain) == 0 {
+			continue
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "ING-001",
+			Title:       "Ingress serves hosts without TLS",
+			Description: fmt.Sprintf("Ingress %s/%s serves hosts %s over plain HTTP", ing.Namespace, ing.Name, strings.Join(plain, ",")),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "ingress",
+			Resource:    fmt.Sprintf("Ingress/%s/%s", ing.Namespace, ing.Name),
+			Namespace:   ing.Namespace,
+			Remediation: "Add a spec.tls entry with a certificate Secret covering every host, for example issued by cert-manager.",
+			Details: map[string]string{
+				"hosts": strings.Join(plain, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// checkRouteTLS reports HTTPRoutes that are only attached to plain HTTP
+// Gateway listeners (ING-002).
+func (a *Analyzer) checkRouteTLS(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for i := range inv.routes {
+		route := &inv.routes[i]
+		keys, listeners := inv.parentGateways(route)
+		if len(keys) == 0 {
+			continue
+		}
+
+		hasHTTPS := false
+		for _, key := range keys {
+			for _, l := range listeners[key] {
+				hasHTTPS = hasHTTPS || l.Protocol == protocolHTTPS
+			}
+		}
+		if hasHTTPS {
+			continue
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "ING-002",
+			Title:       "HTTPRoute served without TLS",
+			Description: fmt.Sprintf("HTTPRoute %s/%s is only attached to plain HTTP listeners on Gateway %s", route.Namespace, route.Name, strings.Join(keys, ",")),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "ingress",
+			Resource:    fmt.Sprintf("HTTPRoute/%s/%s", route.Namespace, route.Name),
+			Namespace:   route.Namespace,
+			Remediation: "Attach the route to an HTTPS listener with certificateRefs, and limit the HTTP listener to a redirect route.",
+			Details: map[string]string{
+				"gateways":  strings.Join(keys, ","),
+				"hostnames": strings.Join(route.Spec.Hostnames, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// checkWildcardHosts reports wildcard and catch-all hostnames on Ingresses,
+// Gateway listeners and HTTPRoutes (ING-003). They route every matching name
+// to one backend and make certificate and hostname ownership hard to audit.
+func (a *Analyzer) checkWildcardHosts(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	wildcard := func(kind, namespace, name string, hosts []string) {
+		if len(hosts) == 0 {
+			return
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "ING-003",
+			Title:       "Wildcard or catch-all hostname",
+			Description: fmt.Sprintf("%s %s/%s matches wildcard hostnames %s", kind, namespace, name, strings.Join(hosts, ",")),
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusWarning,
+			Category:    "ingress",
+			Resource:    fmt.Sprintf("%s/%s/%s", kind, namespace, name),
+			Namespace:   namespace,
+			Remediation: "List the exact hostnames the application serves instead of wildcards or rules without a host.",
+			Details: map[string]string{
+				"hosts": strings.Join(hosts, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	for i := range inv.ingresses {
+		ing := &inv.ingresses[i]
+		var hosts []string
+		for _, host := range ingressHosts(ing) {
+			if host == "" || strings.HasPrefix(host, "*") {
+				hosts = append(hosts, displayHost(host))
+			}
+		}
+		wildcard("Ingress", ing.Namespace, ing.Name, hosts)
+	}
+
+	for i := range inv.gateways {
+		gw := &inv.gateways[i]
+		var hosts []string
+		for _, l := range gw.Spec.Listeners {
+			if strings.HasPrefix(l.Hostname, "*") {
+				hosts = append(hosts, fmt.Sprintf("%s (listener %s)", l.Hostname, l.Name))
+			}
+		}
+		wildcard("Gateway", gw.Namespace, gw.Name, hosts)
+	}
+
+	for i := range inv.routes {
+		route := &inv.routes[i]
+		var hosts []string
+		for _, h := range route.Spec.Hostnames {
+			if strings.HasPrefix(h, "*") {
+				hosts = append(hosts, h)
+			}
+		}
+		wildcard("HTTPRoute", route.Namespace, route.Name, hosts)
+	}
+
+	return findings
+}
+
+// checkAnnotations reports Ingress annotations that inject raw controller
+// configuration (ING-005). Only the annotation name is reported.
+func (a *Analyzer) checkAnnotations(ingresses []networkingv1.Ingress, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		keys := make([]string, 0, len(ing.Annotations))
+		for key := range ing.Annotations {
+			if _, ok := a.dangerousAnnotations[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			findings = append(findings, scanner.Finding{
+				ID:          "ING-005",
+				Title:       "Dangerous ingress controller annotation",
+				Description: fmt.Sprintf("Ingress %s/%s sets %s, which %s", ing.Namespace, ing.Name, key, a.dangerousAnnotations[key]),
+				Severity:    scanner.SeverityHigh,
+				Status:      scanner.StatusFail,
+				Category:    "ingress",
+				Resource:    fmt.Sprintf("Ingress/%s/%s", ing.Namespace, ing.Name),
+				Namespace:   ing.Namespace,
+				Remediation: "Remove the annotation and use the controller's structured settings instead. For ingress-nginx, set allow-snippet-annotations: false and annotations-risk-level in the controller ConfigMap, and keep the controller patched.",
+				Details: map[string]string{
+					"annotation": key,
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	return findings
+}
+
+// checkCrossNamespace reports Gateway listeners that accept routes from every
+// namespace (ING-006) and HTTPRoutes that attach to Gateways or forward to
+// Services in other namespaces (ING-007).
+func (a *Analyzer) checkCrossNamespace(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for i := range inv.gateways {
+		gw := &inv.gateways[i]
+		for _, l := range gw.Spec.Listeners {
+			if allowedFrom(l) != "All" {
+				continue
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "ING-006",
+				Title:       "Gateway listener accepts routes from all namespaces",
+				Description: fmt.Sprintf("Listener %s on Gateway %s/%s accepts routes from every namespace, so any namespace can claim its hostnames", l.Name, gw.Namespace, gw.Name),
+				Severity:    scanner.SeverityMedium,
+				Status:      scanner.StatusWarning,
+				Category:    "ingress",
+				Resource:    fmt.Sprintf("Gateway/%s/%s", gw.Namespace, gw.Name),
+				Namespace:   gw.Namespace,
+				Remediation: "Set allowedRoutes.namespaces.from to Same, or to Selector with a label that only approved namespaces carry.",
+				Details: map[string]string{
+					"listener": l.Name,
+					"hostname": l.Hostname,
+					"protocol": l.Protocol,
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	for i := range inv.routes {
+		route := &inv.routes[i]
+		keys, listeners := inv.parentGateways(route)
+
+		var parents []string
+		openParent := false
+		for _, key := range keys {
+			if strings.HasPrefix(key, route.Namespace+"/") {
+				continue
+			}
+			modes := make(map[string]bool)
+			for _, l := range listeners[key] {
+				modes[allowedFrom(l)] = true
+			}
+			openParent = openParent || modes["All"]
+			parents = append(parents, "Gateway "+key)
+		}
+
+		var backends []string
+		seen := make(map[string]bool)
+		for _, rule := range route.Spec.Rules {
+			for _, backend := range rule.BackendRefs {
+				ns := route.BackendNamespace(backend)
+				ref := fmt.Sprintf("%s %s/%s", backendKind(backend.Kind), ns, backend.Name)
+				if ns == route.Namespace || seen[ref] {
+					continue
+				}
+				seen[ref] = true
+				backends = append(backends, ref)
+			}
+		}
+
+		if len(parents) == 0 && len(backends) == 0 {
+			continue
+		}
+
+		severity := scanner.SeverityLow
+		if openParent || len(backends) > 0 {
+			severity = scanner.SeverityMedium
+		}
+		var targets []string
+		targets = append(targets, parents...)
+		targets = append(targets, backends...)
+
+		findings = append(findings, scanner.Finding{
+			ID:          "ING-007",
+			Title:       "Cross-namespace route attachment",
+			Description: fmt.Sprintf("HTTPRoute %s/%s references objects in other namespaces: %s", route.Namespace, route.Name, strings.Join(targets, ", ")),
+			Severity:    severity,
+			Status:      scanner.StatusWarning,
+			Category:    "ingress",
+			Resource:    fmt.Sprintf("HTTPRoute/%s/%s", route.Namespace, route.Name),
+			Namespace:   route.Namespace,
+			Remediation: "Confirm the attachment is intended. Restrict Gateway listeners with allowedRoutes, and grant cross-namespace backends only through narrowly scoped ReferenceGrants.",
+			Details: map[string]string{
+				"parents":  strings.Join(parents, ","),
+				"backends": strings.Join(backends, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+func backendKind(kind string) string {
+	if kind == "" {
+		return "Service"
+	}
+	return kind
+}
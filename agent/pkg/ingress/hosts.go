@@ -0,0 +1,94 @@
+package ingress
+
+import (
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// Gateway listener protocols that accept HTTPRoutes.
+const (
+	protocolHTTP  = "HTTP"
+	protocolHTTPS = "HTTPS"
+)
+
+// HostMatches reports whether a host pattern, which may be a single-label
+// wildcard such as "*.example.com", covers host.
+func HostMatches(pattern, host string) bool {
+	if pattern == host {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(pattern, "*")
+	if !ok || !strings.HasPrefix(suffix, ".") {
+		return false
+	}
+	label, ok := strings.CutSuffix(host, suffix)
+	return ok && label != "" && !strings.Contains(label, ".")
+}
+
+// HostHasTLS reports whether an Ingress terminates TLS for host. A TLS entry
+// without hosts applies to every host. The empty host (a rule or default
+// backend matching any host) is only covered by such an entry.
+func HostHasTLS(ing *networkingv1.Ingress, host string) bool {
+	for _, tls := range ing.Spec.TLS {
+		if len(tls.Hosts) == 0 {
+			return true
+		}
+		if host == "" {
+			continue
+		}
+		for _, h := range tls.Hosts {
+			if HostMatches(h, host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AttachedListeners returns the HTTP and HTTPS listeners of gw that accept
+// the route through parent reference ref. The listener's sectionName and
+// allowedRoutes.namespaces are honoured; routeNamespaceLabels are the labels
+// of the route's namespace, used for the Selector mode.
+func AttachedListeners(route *k8s.HTTPRoute, ref k8s.HTTPRouteParentRef, gw *k8s.Gateway, routeNamespaceLabels map[string]string) []k8s.GatewayListener {
+	var result []k8s.GatewayListener
+	for _, l := range gw.Spec.Listeners {
+		if ref.SectionName != "" && l.Name != ref.SectionName {
+			continue
+		}
+		if l.Protocol != protocolHTTP && l.Protocol != protocolHTTPS {
+			continue
+		}
+		if !routeAllowed(l, gw.Namespace, route.Namespace, routeNamespaceLabels) {
+			continue
+		}
+		result = append(result, l)
+	}
+	return result
+}
+
+// allowedFrom returns the listener's allowedRoutes.namespaces.from mode,
+// defaulting to Same.
+func allowedFrom(l k8s.GatewayListener) string {
+	if l.AllowedRoutes == nil || l.AllowedRoutes.Namespaces == nil || l.AllowedRoutes.Namespaces.From == "" {
+		return "Same"
+	}
+	return l.AllowedRoutes.Namespaces.From
+}
+
+// routeAllowed reports whether a listener accepts routes from routeNamespace.
+func routeAllowed(l k8s.GatewayListener, gatewayNamespace, routeNamespace string, routeNamespaceLabels map[string]string) bool {
+	switch allowedFrom(l) {
+	case "All":
+		return true
+	case "Selector":
+		selector, err := metav1.LabelSelectorAsSelector(l.AllowedRoutes.Namespaces.Selector)
+		return err == nil && selector.Matches(labels.Set(routeNamespaceLabels))
+	default:
+		return gatewayNamespace == routeNamespace
+	}
+}
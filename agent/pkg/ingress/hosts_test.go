@@ -0,0 +1,108 @@
+package ingress
+
+import (
+	"reflect"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"shop.example.com", "shop.example.com", true},
+		{"shop.example.com", "api.example.com", false},
+		{"*.example.com", "shop.example.com", true},
+		{"*.example.com", "a.shop.example.com", false},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", ".example.com", false},
+		{"*example.com", "shopexample.com", false},
+		{"*", "shop.example.com", false},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.host, func(t *testing.T) {
+			if got := HostMatches(tt.pattern, tt.host); got != tt.want {
+				t.Errorf("HostMatches(%q, %q) = %t, want %t", tt.pattern, tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostHasTLS(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  []networkingv1.IngressTLS
+		host string
+		want bool
+	}{
+		{name: "no TLS", host: "shop.example.com", want: false},
+		{name: "listed host", tls: []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}}}, host: "shop.example.com", want: true},
+		{name: "other host", tls: []networkingv1.IngressTLS{{Hosts: []string{"api.example.com"}}}, host: "shop.example.com", want: false},
+		{name: "wildcard host", tls: []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}}, host: "shop.example.com", want: true},
+		{name: "entry without hosts", tls: []networkingv1.IngressTLS{{SecretName: "default-cert"}}, host: "shop.example.com", want: true},
+		{name: "any host with listed hosts", tls: []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}}}, host: "", want: false},
+		{name: "any host with entry without hosts", tls: []networkingv1.IngressTLS{{SecretName: "default-cert"}}, host: "", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{Spec: networkingv1.IngressSpec{TLS: tt.tls}}
+			if got := HostHasTLS(ing, tt.host); got != tt.want {
+				t.Errorf("HostHasTLS(%q) = %t, want %t", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachedListeners(t *testing.T) {
+	gw := &k8s.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "gateways"},
+		Spec: k8s.GatewaySpec{Listeners: []k8s.GatewayListener{
+			{Name: "same", Protocol: "HTTP"},
+			{Name: "all", Protocol: "HTTPS", AllowedRoutes: &k8s.GatewayAllowedRoutes{Namespaces: &k8s.GatewayRouteNamespaces{From: "All"}}},
+			{Name: "selected", Protocol: "HTTP", AllowedRoutes: &k8s.GatewayAllowedRoutes{Namespaces: &k8s.GatewayRouteNamespaces{
+				From:     "Selector",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"expose": "true"}},
+			}}},
+			{Name: "tcp", Protocol: "TCP", AllowedRoutes: &k8s.GatewayAllowedRoutes{Namespaces: &k8s.GatewayRouteNamespaces{From: "All"}}},
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		labels    map[string]string
+		section   string
+		want      []string
+	}{
+		{name: "same namespace", namespace: "gateways", want: []string{"same", "all"}},
+		{name: "other namespace", namespace: "shop", want: []string{"all"}},
+		{name: "selected namespace", namespace: "shop", labels: map[string]string{"expose": "true"}, want: []string{"all", "selected"}},
+		{name: "section name", namespace: "shop", labels: map[string]string{"expose": "true"}, section: "selected", want: []string{"selected"}},
+		{name: "section not allowed", namespace: "shop", section: "same"},
+		{name: "non-HTTP section", namespace: "shop", section: "tcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &k8s.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: tt.namespace}}
+			ref := k8s.HTTPRouteParentRef{Name: gw.Name, Namespace: gw.Namespace, SectionName: tt.section}
+
+			var got []string
+			for _, l := range AttachedListeners(route, ref, gw, tt.labels) {
+				got = append(got, l.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AttachedListeners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,23 +4,37 @@ package k8s
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"log/slog"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubecomply/kubecomply/pkg/certs"
 )
 
 // Client wraps the Kubernetes client-go with convenience methods for
 // listing common resources. All operations are read-only.
 type Client struct {
 	clientset   kubernetes.Interface
+	dynamic     dynamic.Interface
+	metadata    metadata.Interface
 	clusterName string
 	logger      *slog.Logger
 }
@@ -63,8 +77,20 @@ func NewClient(kubeconfigPath string, logger *slog.Logger) (*Client, error) {
 		return nil, fmt.Errorf("creating kubernetes clientset: %w", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating dynamic client: %w", err)
+	}
+
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating metadata client: %w", err)
+	}
+
 	return &Client{
 		clientset:   clientset,
+		dynamic:     dynamicClient,
+		metadata:    metadataClient,
 		clusterName: clusterName,
 		logger:      logger,
 	}, nil
@@ -83,6 +109,20 @@ func NewClientFromInterface(cs kubernetes.Interface, clusterName string, logger
 	}
 }
 
+// WithDynamicClient sets the dynamic client used for custom resources and
+// returns the Client. Useful for testing with fake dynamic clients.
+func (c *Client) WithDynamicClient(d dynamic.Interface) *Client {
+	c.dynamic = d
+	return c
+}
+
+// WithMetadataClient sets the metadata-only client used to list Secrets and
+// returns the Client. Useful for testing with fake metadata clients.
+func (c *Client) WithMetadataClient(m metadata.Interface) *Client {
+	c.metadata = m
+	return c
+}
+
 // Clientset returns the underlying kubernetes.Interface.
 func (c *Client) Clientset() kubernetes.Interface {
 	return c.clientset
@@ -93,6 +133,37 @@ func (c *Client) ClusterName() string {
 	return c.clusterName
 }
 
+// ListCustomResources returns the objects of a custom resource in the given
+// namespace. Empty namespace means all namespaces (or the cluster scope for
+// cluster-scoped resources). When the resource is not served by the cluster,
+// for example because the CRD is not installed, it returns no objects and no
+// error.
+func (c *Client) ListCustomResources(ctx context.Context, gvr schema.GroupVersionResource, namespace string) ([]unstructured.Unstructured, error) {
+	if c.dynamic == nil {
+		return nil, nil
+	}
+	list, err := c.dynamic.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			c.logger.Debug("custom resource not served", "resource", gvr.String())
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing %s in namespace %q: %w", gvr.Resource, namespace, err)
+	}
+	c.logger.Debug("listed custom resources", "resource", gvr.String(), "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ServerVersion returns the version reported by the API server.
+func (c *Client) ServerVersion(ctx context.Context) (*version.Info, error) {
+	info, err := c.clientset.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("getting server version: %w", err)
+	}
+	c.logger.Debug("got server version", "version", info.GitVersion)
+	return info, nil
+}
+
 // ListNamespaces returns all namespaces in the cluster.
 func (c *Client) ListNamespaces(ctx context.Context) ([]corev1.Namespace, error) {
 	list, err := c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
@@ -123,6 +194,16 @@ func (c *Client) ListServices(ctx context.Context, namespace string) ([]corev1.S
 	return list.Items, nil
 }
 
+// ListServiceAccounts returns ServiceAccounts in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListServiceAccounts(ctx context.Context, namespace string) ([]corev1.ServiceAccount, error) {
+	list, err := c.clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing service accounts in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed service accounts", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
 // ListNodes returns all nodes in the cluster.
 func (c *Client) ListNodes(ctx context.Context) ([]corev1.Node, error) {
 	list, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
@@ -183,6 +264,16 @@ func (c *Client) ListNetworkPolicies(ctx context.Context, namespace string) ([]n
 	return list.Items, nil
 }
 
+// ListIngresses returns Ingresses in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListIngresses(ctx context.Context, namespace string) ([]networkingv1.Ingress, error) {
+	list, err := c.clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing ingresses in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed ingresses", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
 // ListDeployments returns Deployments in the given namespace. Empty namespace means all namespaces.
 func (c *Client) ListDeployments(ctx context.Context, namespace string) ([]appsv1.Deployment, error) {
 	list, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
@@ -213,7 +304,156 @@ func (c *Client) ListStatefulSets(ctx context.Context, namespace string) ([]apps
 	return list.Items, nil
 }
 
+// ListReplicaSets returns ReplicaSets in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListReplicaSets(ctx context.Context, namespace string) ([]appsv1.ReplicaSet, error) {
+	list, err := c.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing replicasets in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed replicasets", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListJobs returns Jobs in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListJobs(ctx context.Context, namespace string) ([]batchv1.Job, error) {
+	list, err := c.clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing jobs in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed jobs", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListCronJobs returns CronJobs in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListCronJobs(ctx context.Context, namespace string) ([]batchv1.CronJob, error) {
+	list, err := c.clientset.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing cronjobs in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed cronjobs", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListReplicationControllers returns ReplicationControllers in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListReplicationControllers(ctx context.Context, namespace string) ([]corev1.ReplicationController, error) {
+	list, err := c.clientset.CoreV1().ReplicationControllers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing replicationcontrollers in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed replicationcontrollers", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListSecretMetadata returns the metadata of Secrets in the given namespace,
+// optionally filtered by a field selector such as
+// "type=kubernetes.io/service-account-token". Empty namespace means all
+// namespaces. Only object metadata is requested from the API server, so
+// Secret data is never transferred.
+func (c *Client) ListSecretMetadata(ctx context.Context, namespace, fieldSelector string) ([]metav1.PartialObjectMetadata, error) {
+	if c.metadata == nil {
+		return nil, fmt.Errorf("listing secret metadata in namespace %q: no metadata client configured", namespace)
+	}
+	list, err := c.metadata.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace(namespace).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, fmt.Errorf("listing secret metadata in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed secret metadata", "namespace", namespace, "selector", fieldSelector, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// TLSCertificate is the certificate chain stored in a kubernetes.io/tls
+// Secret, with the public half of its private key.
+type TLSCertificate struct {
+	Namespace string
+	Name      string
+	// Chain is the PEM-encoded tls.crt value.
+	Chain []byte
+	// PublicKey is derived from the tls.key value, or nil when KeyErr is set.
+	PublicKey crypto.PublicKey
+	// KeyErr is set when tls.key is missing or cannot be parsed.
+	KeyErr error
+}
+
+// ListTLSCertificates returns the tls.crt values of the kubernetes.io/tls
+// Secrets in the given namespace, together with the public key derived from
+// each tls.key. Empty namespace means all namespaces. The full Secrets are
+// fetched, but only tls.crt and that public key are kept: the private key
+// and any other keys are dropped as soon as each Secret is decoded.
+func (c *Client) ListTLSCertificates(ctx context.Context, namespace string) ([]TLSCertificate, error) {
+	list, err := c.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=" + string(corev1.SecretTypeTLS)})
+	if err != nil {
+		return nil, fmt.Errorf("listing TLS secrets in namespace %q: %w", namespace, err)
+	}
+	certificates := make([]TLSCertificate, 0, len(list.Items))
+	for i := range list.Items {
+		secret := &list.Items[i]
+		cert := TLSCertificate{
+			Namespace: secret.Namespace,
+			Name:      secret.Name,
+			Chain:     secret.Data[corev1.TLSCertKey],
+		}
+		cert.PublicKey, cert.KeyErr = certs.PublicKey(secret.Data[corev1.TLSPrivateKeyKey])
+		secret.Data = nil
+		certificates = append(certificates, cert)
+	}
+	c.logger.Debug("listed TLS certificates", "namespace", namespace, "count", len(certificates))
+	return certificates, nil
+}
+
+// ListConfigMaps returns ConfigMaps in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListConfigMaps(ctx context.Context, namespace string) ([]corev1.ConfigMap, error) {
+	list, err := c.clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing configmaps in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed configmaps", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListResourceQuotas returns ResourceQuotas in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListResourceQuotas(ctx context.Context, namespace string) ([]corev1.ResourceQuota, error) {
+	list, err := c.clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing resource quotas in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed resource quotas", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListLimitRanges returns LimitRanges in the given namespace. Empty namespace means all namespaces.
+func (c *Client) ListLimitRanges(ctx context.Context, namespace string) ([]corev1.LimitRange, error) {
+	list, err := c.clientset.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing limit ranges in namespace %q: %w", namespace, err)
+	}
+	c.logger.Debug("listed limit ranges", "namespace", namespace, "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListMutatingWebhookConfigurations returns all MutatingWebhookConfigurations.
+func (c *Client) ListMutatingWebhookConfigurations(ctx context.Context) ([]admissionregistrationv1.MutatingWebhookConfiguration, error) {
+	list, err := c.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing mutating webhook configurations: %w", err)
+	}
+	c.logger.Debug("listed mutating webhook configurations", "count", len(list.Items))
+	return list.Items, nil
+}
+
+// ListValidatingWebhookConfigurations returns all ValidatingWebhookConfigurations.
+func (c *Client) ListValidatingWebhookConfigurations(ctx context.Context) ([]admissionregistrationv1.ValidatingWebhookConfiguration, error) {
+	list, err := c.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing validating webhook configurations: %w", err)
+	}
+	c.logger.Debug("listed validating webhook configurations", "count", len(list.Items))
+	return list.Items, nil
+}
+
 // ListSecrets returns Secrets in the given namespace. Empty namespace means all namespaces.
+//
+// Deprecated: analyzers must not read Secret data (see SECURITY.md). Use
+// ListSecretMetadata for names, labels and annotations.
 func (c *Client) ListSecrets(ctx context.Context, namespace string) ([]corev1.Secret, error) {
 	list, err := c.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
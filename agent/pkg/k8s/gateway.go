@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Gateway API resources. They are read through the dynamic client so the
+// agent does not depend on the Gateway API Go module; only the fields used
+// by the analyzers are decoded.
+var (
+	GatewayGVR   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	HTTPRouteGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+)
+
+// Gateway is the subset of a gateway.networking.k8s.io/v1 Gateway used for
+// compliance analysis.
+type Gateway struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              GatewaySpec `json:"spec"`
+}
+
+// GatewaySpec holds the listeners of a Gateway.
+type GatewaySpec struct {
+	GatewayClassName string            `json:"gatewayClassName"`
+	Listeners        []GatewayListener `json:"listeners"`
+}
+
+// GatewayListener is a single port/protocol/hostname a Gateway accepts
+// traffic on.
+type GatewayListener struct {
+	Name          string                `json:"name"`
+	Hostname      string                `json:"hostname,omitempty"`
+	Port          int32                 `json:"port"`
+	Protocol      string                `json:"protocol"`
+	TLS           *GatewayTLSConfig     `json:"tls,omitempty"`
+	AllowedRoutes *GatewayAllowedRoutes `json:"allowedRoutes,omitempty"`
+}
+
+// GatewayTLSConfig is the TLS configuration of a listener.
+type GatewayTLSConfig struct {
+	Mode            string                   `json:"mode,omitempty"`
+	CertificateRefs []GatewayObjectReference `json:"certificateRefs,omitempty"`
+}
+
+// GatewayAllowedRoutes restricts which routes may attach to a listener.
+type GatewayAllowedRoutes struct {
+	Namespaces *GatewayRouteNamespaces `json:"namespaces,omitempty"`
+}
+
+// GatewayRouteNamespaces selects the namespaces routes may attach from.
+// From is one of All, Same (the default) or Selector.
+type GatewayRouteNamespaces struct {
+	From     string                `json:"from,omitempty"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// GatewayObjectReference references another object, such as a TLS Secret or
+// a backend Service. Empty Kind and Namespace take the API defaults.
+type GatewayObjectReference struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Port      *int32 `json:"port,omitempty"`
+}
+
+// HTTPRoute is the subset of a gateway.networking.k8s.io/v1 HTTPRoute used
+// for compliance analysis.
+type HTTPRoute struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              HTTPRouteSpec `json:"spec"`
+}
+
+// HTTPRouteSpec holds the parents, hostnames and backends of an HTTPRoute.
+type HTTPRouteSpec struct {
+	ParentRefs []HTTPRouteParentRef `json:"parentRefs,omitempty"`
+	Hostnames  []string             `json:"hostnames,omitempty"`
+	Rules      []HTTPRouteRule      `json:"rules,omitempty"`
+}
+
+// HTTPRouteParentRef identifies the Gateway (and optionally the listener)
+// an HTTPRoute attaches to.
+type HTTPRouteParentRef struct {
+	Group       string `json:"group,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// HTTPRouteRule lists the backends a set of matches is forwarded to.
+type HTTPRouteRule struct {
+	BackendRefs []GatewayObjectReference `json:"backendRefs,omitempty"`
+}
+
+// ListGateways returns Gateways in the given namespace. Empty namespace means
+// all namespaces. It returns no objects when the Gateway API is not installed.
+func (c *Client) ListGateways(ctx context.Context, namespace string) ([]Gateway, error) {
+	items, err := c.ListCustomResources(ctx, GatewayGVR, namespace)
+	if err != nil {
+		return nil, err
+	}
+	gateways := make([]Gateway, 0, len(items))
+	for i := range items {
+		var gw Gateway
+		if err := fromUnstructured(&items[i], &gw); err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, gw)
+	}
+	return gateways, nil
+}
+
+// ListHTTPRoutes returns HTTPRoutes in the given namespace. Empty namespace
+// means all namespaces. It returns no objects when the Gateway API is not
+// installed.
+func (c *Client) ListHTTPRoutes(ctx context.Context, namespace string) ([]HTTPRoute, error) {
+	items, err := c.ListCustomResources(ctx, HTTPRouteGVR, namespace)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]HTTPRoute, 0, len(items))
+	for i := range items {
+		var route HTTPRoute
+		if err := fromUnstructured(&items[i], &route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func fromUnstructured(u *unstructured.Unstructured, into interface{}) error {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into); err != nil {
+		return fmt.Errorf("decoding %s %s/%s: %w", u.GetKind(), u.GetNamespace(), u.GetName(), err)
+	}
+	return nil
+}
+
+// ParentNamespace returns the namespace of the referenced Gateway, which
+// defaults to the route's own namespace.
+func (r *HTTPRoute) ParentNamespace(ref HTTPRouteParentRef) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return r.Namespace
+}
+
+// BackendNamespace returns the namespace of a backend reference, which
+// defaults to the route's own namespace.
+func (r *HTTPRoute) BackendNamespace(ref GatewayObjectReference) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return r.Namespace
+}
+
+// IsService reports whether a backend reference points at a core Service.
+func (ref GatewayObjectReference) IsService() bool {
+	return (ref.Group == "" || ref.Group == "core") && (ref.Kind == "" || ref.Kind == "Service")
+}
+
+// IsGateway reports whether a parent reference points at a Gateway.
+func (ref HTTPRouteParentRef) IsGateway() bool {
+	return (ref.Group == "" || ref.Group == "gateway.networking.k8s.io") && (ref.Kind == "" || ref.Kind == "Gateway")
+}
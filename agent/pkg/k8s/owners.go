@@ -0,0 +1,67 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxOwnerDepth bounds ownerReference walks in case of cycles.
+const maxOwnerDepth = 8
+
+// OwnerIndex maps controlled objects such as ReplicaSets, Jobs and the
+// Deployments of Knative Revisions, keyed by "Kind/namespace/name", to their
+// own controller reference. It lets analyzers attribute pods and controlled
+// templates to their top-level controller. A nil index resolves only a pod's
+// direct controller.
+type OwnerIndex map[string]metav1.OwnerReference
+
+// Add records the controller of an object, if it has one, and reports
+// whether it did.
+func (idx OwnerIndex) Add(kind string, obj metav1.Object) bool {
+	ref := metav1.GetControllerOfNoCopy(obj)
+	if ref == nil {
+		return false
+	}
+	idx[fmt.Sprintf("%s/%s/%s", kind, obj.GetNamespace(), obj.GetName())] = *ref
+	return true
+}
+
+// TopLevelOwner resolves a pod's ownerReferences to its top-level controller,
+// e.g. Pod -> ReplicaSet -> Deployment or Pod -> Job -> CronJob, and returns
+// it as "Kind/namespace/name". A ReplicaSet that is not indexed is attributed
+// to its Deployment through the pod-template-hash naming convention.
+// Unowned pods return "".
+func (idx OwnerIndex) TopLevelOwner(pod *corev1.Pod) string {
+	ref := metav1.GetControllerOfNoCopy(pod)
+	if ref == nil {
+		return ""
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", ref.Kind, pod.Namespace, ref.Name)
+	if _, ok := idx[key]; !ok && ref.Kind == "ReplicaSet" {
+		if hash := pod.Labels["pod-template-hash"]; hash != "" {
+			if name, ok := strings.CutSuffix(ref.Name, "-"+hash); ok {
+				return fmt.Sprintf("Deployment/%s/%s", pod.Namespace, name)
+			}
+		}
+	}
+
+	return idx.TopLevel(key, pod.Namespace)
+}
+
+// TopLevel follows the controller references of the indexed object keyed by
+// "Kind/namespace/name" and returns the last one, which is key itself when
+// the object is not indexed.
+func (idx OwnerIndex) TopLevel(key, namespace string) string {
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		parent, ok := idx[key]
+		if !ok {
+			break
+		}
+		key = fmt.Sprintf("%s/%s/%s", parent.Kind, namespace, parent.Name)
+	}
+	return key
+}
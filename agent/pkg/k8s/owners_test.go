@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTopLevelOwner(t *testing.T) {
+	yes := true
+	controller := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &yes}}
+	}
+	meta := func(name string, owners []metav1.OwnerReference) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "shop", OwnerReferences: owners}
+	}
+
+	index := make(OwnerIndex)
+	index.Add("ReplicaSet", &metav1.ObjectMeta{Name: "web-7d4b9", Namespace: "shop", OwnerReferences: controller("Deployment", "web")})
+	index.Add("Job", &metav1.ObjectMeta{Name: "backup-2890", Namespace: "shop", OwnerReferences: controller("CronJob", "backup")})
+	index.Add("ReplicaSet", &metav1.ObjectMeta{Name: "canary-5f6c7", Namespace: "shop", OwnerReferences: controller("Rollout", "canary")})
+	index.Add("ReplicaSet", &metav1.ObjectMeta{Name: "loop", Namespace: "shop", OwnerReferences: controller("ReplicaSet", "loop")})
+	if index.Add("ReplicaSet", &metav1.ObjectMeta{Name: "legacy", Namespace: "shop"}) {
+		t.Error("Add() recorded an object without a controller")
+	}
+
+	tests := []struct {
+		name  string
+		index OwnerIndex
+		pod   *corev1.Pod
+		want  string
+	}{
+		{name: "unowned", index: index, pod: &corev1.Pod{ObjectMeta: meta("debug", nil)}},
+		{
+			name:  "non-controller owner",
+			index: index,
+			pod:   &corev1.Pod{ObjectMeta: meta("x", []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d4b9"}})},
+		},
+		{name: "deployment", index: index, pod: &corev1.Pod{ObjectMeta: meta("web-7d4b9-x2x9k", controller("ReplicaSet", "web-7d4b9"))}, want: "Deployment/shop/web"},
+		{name: "cronjob", index: index, pod: &corev1.Pod{ObjectMeta: meta("backup-2890-abcde", controller("Job", "backup-2890"))}, want: "CronJob/shop/backup"},
+		{name: "rollout", index: index, pod: &corev1.Pod{ObjectMeta: meta("canary-5f6c7-klmno", controller("ReplicaSet", "canary-5f6c7"))}, want: "Rollout/shop/canary"},
+		{name: "standalone replicaset", index: index, pod: &corev1.Pod{ObjectMeta: meta("legacy-abcde", controller("ReplicaSet", "legacy"))}, want: "ReplicaSet/shop/legacy"},
+		{name: "cycle is bounded", index: index, pod: &corev1.Pod{ObjectMeta: meta("loop-abcde", controller("ReplicaSet", "loop"))}, want: "ReplicaSet/shop/loop"},
+		{
+			name:  "unlisted replicaset falls back to pod-template-hash",
+			index: index,
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "api-5c8f2-pqrst", Namespace: "shop", OwnerReferences: controller("ReplicaSet", "api-5c8f2"),
+				Labels: map[string]string{"pod-template-hash": "5c8f2"},
+			}},
+			want: "Deployment/shop/api",
+		},
+		{
+			name: "nil index uses pod-template-hash",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "web-7d4b9-x2x9k", Namespace: "shop", OwnerReferences: controller("ReplicaSet", "web-7d4b9"),
+				Labels: map[string]string{"pod-template-hash": "7d4b9"},
+			}},
+			want: "Deployment/shop/web",
+		},
+		{
+			name: "hash not in the name",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "custom-abcde", Namespace: "shop", OwnerReferences: controller("ReplicaSet", "custom"),
+				Labels: map[string]string{"pod-template-hash": "7d4b9"},
+			}},
+			want: "ReplicaSet/shop/custom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.index.TopLevelOwner(tt.pod); got != tt.want {
+				t.Errorf("TopLevelOwner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// secretDataAllowed lists the functions allowed to read Secret data, as
+// "path/from/module/root.go:Func". See SECURITY.md before adding to it.
+var secretDataAllowed = map[string]bool{
+	// The professional license key.
+	"internal/controller/compliancescan_controller.go:uploadToSaaS": true,
+	// tls.crt, and the public key derived from tls.key, of kubernetes.io/tls
+	// Secrets, behind --read-tls-secrets.
+	"pkg/k8s/client.go:ListTLSCertificates": true,
+}
+
+// secretSelectors are the selectors that mark a file as handling Secret
+// objects: the corev1.Secret and SecretList types and the Secrets and
+// GetSecret accessors.
+var secretSelectors = map[string]bool{
+	"Secret":     true,
+	"SecretList": true,
+	"Secrets":    true,
+	"GetSecret":  true,
+}
+
+// TestNoSecretDataAccess fails when a file that handles Secret objects reads a
+// Data or StringData field outside the allowed functions.
+func TestNoSecretDataAccess(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		handlesSecrets := false
+		ast.Inspect(file, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && secretSelectors[sel.Sel.Name] {
+				handlesSecrets = true
+			}
+			return !handlesSecrets
+		})
+		if !handlesSecrets {
+			return nil
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || secretDataAllowed[rel+":"+fn.Name.Name] {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok && (sel.Sel.Name == "Data" || sel.Sel.Name == "StringData") {
+					t.Errorf("%s: %s reads .%s in a file handling Secrets; Secret data must not be read (see SECURITY.md)", rel, fn.Name.Name, sel.Sel.Name)
+				}
+				return true
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +38,13 @@ func NewAnalyzer(client *k8s.Client, logger *slog.Logger) *Analyzer {
 	}
 }
 
+// systemNamespaces are handled differently from workload namespaces.
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
 // namespacePolicyInfo tracks policy coverage for a single namespace.
 type namespacePolicyInfo struct {
 	hasIngress       bool
@@ -82,6 +91,9 @@ func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.
 		nsPolicies[ns] = policies
 	}
 
+	// Fold in Cilium and Calico policies when their CRDs are installed.
+	a.addCNIPolicies(ctx, scanNS, allNamespaces, nsPolicies)
+
 	// Check 1: Namespace coverage (does each namespace have at least one NetworkPolicy?).
 	findings = append(findings, a.checkNamespaceCoverage(nsPolicies, scanNS, now)...)
 
@@ -92,7 +104,25 @@ func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.
 	findings = append(findings, a.checkIngressEgressCoverage(nsPolicies, now)...)
 
 	// Check 4: Open NodePort and LoadBalancer services.
-	findings = append(findings, a.checkExposedServices(ctx, scanNS, now)...)
+	services := a.listServices(ctx, scanNS)
+	findings = append(findings, a.checkExposedServices(services, now)...)
+
+	// Check 5: Pods not selected by any NetworkPolicy in covered namespaces.
+	engine := NewEngine(allNamespaces, a.listPods(ctx, scanNS), flattenPolicies(nsPolicies))
+	findings = append(findings, a.checkUnselectedPods(engine, nsPolicies, now)...)
+
+	// Check 6: Overly broad rules (any address, any peer, any port).
+	findings = append(findings, a.checkBroadRules(nsPolicies, now)...)
+
+	// Check 7: Workloads whose effective egress includes the public internet.
+	findings = append(findings, a.checkInternetEgress(engine, now)...)
+
+	// Check 8: Policies that select nothing, are shadowed, or reference missing namespace labels.
+	findings = append(findings, a.checkIneffectivePolicies(engine, nsPolicies, now)...)
+
+	// Check 9: Service, Ingress and Gateway exposure, correlated per workload.
+	ingresses, routes, gateways := a.listRoutes(ctx, scanNS)
+	findings = append(findings, a.checkServiceExposure(engine, services, ingresses, routes, gateways, now)...)
 
 	a.logger.Info("network policy analysis complete", "findings", len(findings))
 	return findings, nil
@@ -106,18 +136,11 @@ func (a *Analyzer) checkNamespaceCoverage(
 ) []scanner.Finding {
 	var findings []scanner.Finding
 
-	// System namespaces are handled differently.
-	systemNS := map[string]bool{
-		"kube-system":     true,
-		"kube-public":     true,
-		"kube-node-lease": true,
-	}
-
 	coveredCount := 0
 	totalCount := 0
 
 	for ns := range scanNS {
-		if systemNS[ns] {
+		if systemNamespaces[ns] {
 			continue
 		}
 		totalCount++
@@ -133,7 +156,7 @@ func (a *Analyzer) checkNamespaceCoverage(
 				Category:    "network",
 				Resource:    fmt.Sprintf("Namespace/%s", ns),
 				Namespace:   ns,
-				Remediation: "Create NetworkPolicies to restrict ingress and egress traffic. Start with a default-deny policy and add explicit allow rules.",
+				Remediation: "Create NetworkPolicies to restrict ingress and egress traffic. Start with a default-deny policy and add explicit allow rules; `kubecomply network generate -n <namespace>` produces a starting set.",
 				Timestamp:   now,
 			})
 		} else {
@@ -197,7 +220,7 @@ func (a *Analyzer) checkDefaultDenyPolicies(
 				Category:    "network",
 				Resource:    fmt.Sprintf("Namespace/%s", ns),
 				Namespace:   ns,
-				Remediation: "Create a NetworkPolicy with podSelector: {} and policyTypes: [Ingress] with no ingress rules to deny all ingress by default.",
+				Remediation: "Create a NetworkPolicy with podSelector: {} and policyTypes: [Ingress] with no ingress rules to deny all ingress by default, or run `kubecomply network generate -n <namespace>`.",
 				Timestamp:   now,
 			})
 		}
@@ -212,7 +235,7 @@ func (a *Analyzer) checkDefaultDenyPolicies(
 				Category:    "network",
 				Resource:    fmt.Sprintf("Namespace/%s", ns),
 				Namespace:   ns,
-				Remediation: "Create a NetworkPolicy with podSelector: {} and policyTypes: [Egress] with no egress rules to deny all egress by default.",
+				Remediation: "Create a NetworkPolicy with podSelector: {} and policyTypes: [Egress] with no egress rules to deny all egress by default, or run `kubecomply network generate -n <namespace>`.",
 				Timestamp:   now,
 			})
 		}
@@ -256,59 +279,204 @@ func (a *Analyzer) checkIngressEgressCoverage(
 }
 
 // checkExposedServices identifies NodePort and LoadBalancer services.
-func (a *Analyzer) checkExposedServices(ctx context.Context, scanNS map[string]bool, now time.Time) []scanner.Finding {
+func (a *Analyzer) checkExposedServices(services []corev1.Service, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for ns := range scanNS {
-		services, err := a.client.ListServices(ctx, ns)
-		if err != nil {
-			a.logger.Warn("failed to list services", "namespace", ns, "error", err)
-			continue
-		}
-
-		for _, svc := range services {
-			switch svc.Spec.Type {
-			case corev1.ServiceTypeNodePort:
-				for _, port := range svc.Spec.Ports {
-					findings = append(findings, scanner.Finding{
-						ID:          "NET-006",
-						Title:       "NodePort service detected",
-						Description: fmt.Sprintf("Service %s/%s exposes NodePort %d (target port %s)", svc.Namespace, svc.Name, port.NodePort, port.TargetPort.String()),
-						Severity:    scanner.SeverityMedium,
-						Status:      scanner.StatusWarning,
-						Category:    "network",
-						Resource:    fmt.Sprintf("Service/%s/%s", svc.Namespace, svc.Name),
-						Namespace:   svc.Namespace,
-						Remediation: "Consider using a LoadBalancer or Ingress controller instead of NodePort to avoid exposing ports on all cluster nodes.",
-						Details: map[string]string{
-							"node_port":   fmt.Sprintf("%d", port.NodePort),
-							"target_port": port.TargetPort.String(),
-							"protocol":    string(port.Protocol),
-						},
-						Timestamp: now,
-					})
-				}
-
-			case corev1.ServiceTypeLoadBalancer:
+	for _, svc := range services {
+		switch svc.Spec.Type {
+		case corev1.ServiceTypeNodePort:
+			for _, port := range svc.Spec.Ports {
 				findings = append(findings, scanner.Finding{
-					ID:          "NET-007",
-					Title:       "LoadBalancer service detected",
-					Description: fmt.Sprintf("Service %s/%s is exposed via LoadBalancer", svc.Namespace, svc.Name),
-					Severity:    scanner.SeverityLow,
+					ID:          "NET-006",
+					Title:       "NodePort service detected",
+					Description: fmt.Sprintf("Service %s/%s exposes NodePort %d (target port %s)", svc.Namespace, svc.Name, port.NodePort, port.TargetPort.String()),
+					Severity:    scanner.SeverityMedium,
 					Status:      scanner.StatusWarning,
 					Category:    "network",
 					Resource:    fmt.Sprintf("Service/%s/%s", svc.Namespace, svc.Name),
 					Namespace:   svc.Namespace,
-					Remediation: "Verify that the LoadBalancer has appropriate security group rules and is not publicly accessible unless intended.",
-					Timestamp:   now,
+					Remediation: "Consider using a LoadBalancer or Ingress controller instead of NodePort to avoid exposing ports on all cluster nodes.",
+					Details: map[string]string{
+						"node_port":   fmt.Sprintf("%d", port.NodePort),
+						"target_port": port.TargetPort.String(),
+						"protocol":    string(port.Protocol),
+					},
+					Timestamp: now,
 				})
 			}
+
+		case corev1.ServiceTypeLoadBalancer:
+			// Public load balancers open to any address are reported by
+			// NET-016 instead.
+			internal := isInternalLoadBalancer(&svc)
+			ranges := loadBalancerSourceRanges(&svc)
+			if !internal && unrestrictedRanges(ranges) {
+				continue
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "NET-007",
+				Title:       "LoadBalancer service detected",
+				Description: fmt.Sprintf("Service %s/%s is exposed via LoadBalancer", svc.Namespace, svc.Name),
+				Severity:    scanner.SeverityLow,
+				Status:      scanner.StatusWarning,
+				Category:    "network",
+				Resource:    fmt.Sprintf("Service/%s/%s", svc.Namespace, svc.Name),
+				Namespace:   svc.Namespace,
+				Remediation: "Verify that the LoadBalancer has appropriate security group rules and is not publicly accessible unless intended.",
+				Details: map[string]string{
+					"internal":      fmt.Sprintf("%t", internal),
+					"source_ranges": strings.Join(ranges, ","),
+				},
+				Timestamp: now,
+			})
 		}
 	}
 
 	return findings
 }
 
+// Policies returns, for each given namespace, its NetworkPolicies and the
+// Cilium and Calico policies that apply to it, translated to NetworkPolicies.
+// Namespaces whose NetworkPolicies cannot be listed are left out unless a
+// cluster-wide policy applies to them.
+func (a *Analyzer) Policies(ctx context.Context, namespaces []string) map[string][]networkingv1.NetworkPolicy {
+	allNamespaces, err := a.client.ListNamespaces(ctx)
+	if err != nil {
+		a.logger.Warn("failed to list namespaces", "error", err)
+	}
+
+	scanNS := make(map[string]bool)
+	nsPolicies := make(map[string][]networkingv1.NetworkPolicy)
+	for _, ns := range namespaces {
+		scanNS[ns] = true
+		policies, err := a.client.ListNetworkPolicies(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list network policies", "namespace", ns, "error", err)
+			continue
+		}
+		nsPolicies[ns] = policies
+	}
+	a.addCNIPolicies(ctx, scanNS, allNamespaces, nsPolicies)
+	return nsPolicies
+}
+
+// Reachability builds a pod-level reachability engine for the given
+// namespaces, or for all namespaces when none are specified.
+func (a *Analyzer) Reachability(ctx context.Context, namespaces []string) (*Engine, error) {
+	allNamespaces, err := a.client.ListNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing namespaces: %w", err)
+	}
+
+	scanNS := make(map[string]bool)
+	for _, ns := range namespaces {
+		scanNS[ns] = true
+	}
+	if len(scanNS) == 0 {
+		for _, ns := range allNamespaces {
+			scanNS[ns.Name] = true
+		}
+	}
+
+	nsPolicies := make(map[string][]networkingv1.NetworkPolicy)
+	for ns := range scanNS {
+		policies, err := a.client.ListNetworkPolicies(ctx, ns)
+		if err != nil {
+			return nil, fmt.Errorf("listing network policies in %s: %w", ns, err)
+		}
+		nsPolicies[ns] = policies
+	}
+	a.addCNIPolicies(ctx, scanNS, allNamespaces, nsPolicies)
+
+	return NewEngine(allNamespaces, a.listPods(ctx, scanNS), flattenPolicies(nsPolicies)), nil
+}
+
+// listPods returns the pods of all namespaces in scanNS, skipping namespaces
+// that cannot be listed.
+func (a *Analyzer) listPods(ctx context.Context, scanNS map[string]bool) []corev1.Pod {
+	var pods []corev1.Pod
+	for ns := range scanNS {
+		nsPods, err := a.client.ListPods(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list pods", "namespace", ns, "error", err)
+			continue
+		}
+		pods = append(pods, nsPods...)
+	}
+	return pods
+}
+
+// listServices returns the Services of all namespaces in scanNS, sorted by
+// namespace and name, skipping namespaces that cannot be listed.
+func (a *Analyzer) listServices(ctx context.Context, scanNS map[string]bool) []corev1.Service {
+	var services []corev1.Service
+	for ns := range scanNS {
+		nsServices, err := a.client.ListServices(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list services", "namespace", ns, "error", err)
+			continue
+		}
+		services = append(services, nsServices...)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services
+}
+
+// flattenPolicies returns the policies of all namespaces as a single slice.
+func flattenPolicies(nsPolicies map[string][]networkingv1.NetworkPolicy) []networkingv1.NetworkPolicy {
+	var result []networkingv1.NetworkPolicy
+	for _, policies := range nsPolicies {
+		result = append(result, policies...)
+	}
+	return result
+}
+
+// checkUnselectedPods identifies pods that no NetworkPolicy selects in
+// namespaces that do have policies. Such pods accept and send any traffic
+// even though the namespace appears covered. Namespaces without any policy
+// are already reported by NET-001.
+func (a *Analyzer) checkUnselectedPods(
+	engine *Engine,
+	nsPolicies map[string][]networkingv1.NetworkPolicy,
+	now time.Time,
+) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, pod := range engine.Pods() {
+		if len(nsPolicies[pod.Namespace]) == 0 || isTerminated(&pod) {
+			continue
+		}
+		if len(engine.PoliciesSelecting(&pod)) > 0 {
+			continue
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-008",
+			Title:       "Pod not selected by any NetworkPolicy",
+			Description: fmt.Sprintf("Pod %s/%s is not selected by any of the %d NetworkPolicies in its namespace and accepts unrestricted traffic", pod.Namespace, pod.Name, len(nsPolicies[pod.Namespace])),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "network",
+			Resource:    fmt.Sprintf("Pod/%s/%s", pod.Namespace, pod.Name),
+			Namespace:   pod.Namespace,
+			Remediation: "Add a default-deny policy with podSelector: {} or extend an existing policy's podSelector to cover this pod's labels.",
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
+
+// isTerminated reports whether a pod has finished and no longer receives traffic.
+func isTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
 // analyzeNamespacePolicies examines all policies in a namespace and determines
 // what types of traffic control are present.
 func analyzeNamespacePolicies(policies []networkingv1.NetworkPolicy) namespacePolicyInfo {
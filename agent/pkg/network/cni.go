@@ -0,0 +1,750 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// sourceKindAnnotation records the original kind of a policy translated from
+// a CNI-specific custom resource.
+const sourceKindAnnotation = "compliance.kubecomply.io/source-kind"
+
+const (
+	kindNetworkPolicy                  = "NetworkPolicy"
+	kindCiliumNetworkPolicy            = "CiliumNetworkPolicy"
+	kindCiliumClusterwideNetworkPolicy = "CiliumClusterwideNetworkPolicy"
+	kindCalicoGlobalNetworkPolicy      = "GlobalNetworkPolicy"
+)
+
+var (
+	ciliumNetworkPolicyGVR            = schema.GroupVersionResource{Group: "cilium.io", Version: "v2", Resource: "ciliumnetworkpolicies"}
+	ciliumClusterwideNetworkPolicyGVR = schema.GroupVersionResource{Group: "cilium.io", Version: "v2", Resource: "ciliumclusterwidenetworkpolicies"}
+	calicoGlobalNetworkPolicyGVR      = schema.GroupVersionResource{Group: "crd.projectcalico.org", Version: "v1", Resource: "globalnetworkpolicies"}
+)
+
+// clusterWideKinds are policy kinds that are not namespaced. They are
+// translated into one copy per namespace they apply to.
+var clusterWideKinds = map[string]bool{
+	kindCiliumClusterwideNetworkPolicy: true,
+	kindCalicoGlobalNetworkPolicy:      true,
+}
+
+// policyKind returns the original kind of a possibly translated policy.
+func policyKind(p *networkingv1.NetworkPolicy) string {
+	if kind := p.Annotations[sourceKindAnnotation]; kind != "" {
+		return kind
+	}
+	return kindNetworkPolicy
+}
+
+// isClusterWide reports whether a policy was translated from a cluster-scoped resource.
+func isClusterWide(p *networkingv1.NetworkPolicy) bool {
+	return clusterWideKinds[policyKind(p)]
+}
+
+// describePolicy returns the kind and name of a policy, e.g.
+// "CiliumNetworkPolicy web/allow-api".
+func describePolicy(p *networkingv1.NetworkPolicy) string {
+	if isClusterWide(p) {
+		return policyKind(p) + " " + p.Name
+	}
+	return policyKind(p) + " " + p.Namespace + "/" + p.Name
+}
+
+// policyRef returns the short reference used in rule explanations: "ns/name"
+// for NetworkPolicies and the kind-qualified name for CNI policies.
+func policyRef(p *networkingv1.NetworkPolicy) string {
+	if policyKind(p) == kindNetworkPolicy {
+		return p.Namespace + "/" + p.Name
+	}
+	return describePolicy(p)
+}
+
+// policyResource returns the Finding resource for a policy.
+func policyResource(p *networkingv1.NetworkPolicy) string {
+	if isClusterWide(p) {
+		return policyKind(p) + "/" + p.Name
+	}
+	return fmt.Sprintf("%s/%s/%s", policyKind(p), p.Namespace, p.Name)
+}
+
+// addCNIPolicies lists Cilium and Calico policy resources and appends their
+// NetworkPolicy equivalents to nsPolicies for every scanned namespace they
+// apply to. Resources whose CRDs are not installed are skipped silently.
+//
+// The translation is best effort: allow rules are mapped to NetworkPolicy
+// peers and ports, deny rules only contribute isolation, and matchers with no
+// NetworkPolicy equivalent (FQDNs, service accounts, negations) drop the rule.
+func (a *Analyzer) addCNIPolicies(
+	ctx context.Context,
+	scanNS map[string]bool,
+	namespaces []corev1.Namespace,
+	nsPolicies map[string][]networkingv1.NetworkPolicy,
+) {
+	nsLabels := make(map[string]map[string]string, len(namespaces))
+	for i := range namespaces {
+		nsLabels[namespaces[i].Name] = namespaceLabelSet(&namespaces[i])
+	}
+
+	add := func(policies []networkingv1.NetworkPolicy) {
+		for _, p := range policies {
+			nsPolicies[p.Namespace] = append(nsPolicies[p.Namespace], p)
+		}
+	}
+
+	cnps, err := a.client.ListCustomResources(ctx, ciliumNetworkPolicyGVR, "")
+	if err != nil {
+		a.logger.Warn("failed to list CiliumNetworkPolicies", "error", err)
+	}
+	for i := range cnps {
+		if !scanNS[cnps[i].GetNamespace()] {
+			continue
+		}
+		add(translateCilium(&cnps[i], kindCiliumNetworkPolicy, cnps[i].GetNamespace(), nil))
+	}
+
+	ccnps, err := a.client.ListCustomResources(ctx, ciliumClusterwideNetworkPolicyGVR, "")
+	if err != nil {
+		a.logger.Warn("failed to list CiliumClusterwideNetworkPolicies", "error", err)
+	}
+	for i := range ccnps {
+		for ns := range scanNS {
+			add(translateCilium(&ccnps[i], kindCiliumClusterwideNetworkPolicy, ns, nsLabels[ns]))
+		}
+	}
+
+	gnps, err := a.client.ListCustomResources(ctx, calicoGlobalNetworkPolicyGVR, "")
+	if err != nil {
+		a.logger.Warn("failed to list Calico GlobalNetworkPolicies", "error", err)
+	}
+	for i := range gnps {
+		for ns := range scanNS {
+			p, err := translateCalico(&gnps[i], ns, nsLabels[ns])
+			if err != nil {
+				a.logger.Warn("skipping Calico GlobalNetworkPolicy", "name", gnps[i].GetName(), "namespace", ns, "error", err)
+				continue
+			}
+			if p != nil {
+				add([]networkingv1.NetworkPolicy{*p})
+			}
+		}
+	}
+
+	a.logger.Debug("translated CNI network policies",
+		"cilium", len(cnps), "ciliumClusterwide", len(ccnps), "calicoGlobal", len(gnps))
+}
+
+// translatedPolicy creates the NetworkPolicy shell for a translated resource.
+func translatedPolicy(kind, name, namespace string, podSelector *metav1.LabelSelector) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{sourceKindAnnotation: kind},
+		},
+		Spec: networkingv1.NetworkPolicySpec{PodSelector: *podSelector},
+	}
+}
+
+// --- Cilium ---
+
+// translateCilium converts a CiliumNetworkPolicy or
+// CiliumClusterwideNetworkPolicy into NetworkPolicies for namespace. For
+// cluster-wide policies, nsLabels are the labels of that namespace and the
+// result is empty when the endpointSelector excludes it.
+func translateCilium(obj *unstructured.Unstructured, kind, namespace string, nsLabels map[string]string) []networkingv1.NetworkPolicy {
+	var specs []map[string]interface{}
+	if spec, ok, _ := unstructured.NestedMap(obj.Object, "spec"); ok {
+		specs = append(specs, spec)
+	}
+	if list, ok, _ := unstructured.NestedSlice(obj.Object, "specs"); ok {
+		for _, item := range list {
+			if spec, ok := item.(map[string]interface{}); ok {
+				specs = append(specs, spec)
+			}
+		}
+	}
+
+	clusterWide := clusterWideKinds[kind]
+	var result []networkingv1.NetworkPolicy
+	for i, spec := range specs {
+		raw, ok := spec["endpointSelector"].(map[string]interface{})
+		if !ok {
+			// nodeSelector policies apply to hosts, not pods.
+			continue
+		}
+		podSel, nsSel, ok := ciliumSelector(raw)
+		if !ok {
+			continue
+		}
+		if clusterWide && nsSel != nil && !selectorMatches(nsSel, nsLabels) {
+			continue
+		}
+
+		name := obj.GetName()
+		if len(specs) > 1 {
+			name = fmt.Sprintf("%s[%d]", name, i)
+		}
+		p := translatedPolicy(kind, name, namespace, podSel)
+
+		if _, ok := spec["ingress"]; ok || spec["ingressDeny"] != nil {
+			p.Spec.PolicyTypes = append(p.Spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+		}
+		if _, ok := spec["egress"]; ok || spec["egressDeny"] != nil {
+			p.Spec.PolicyTypes = append(p.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		}
+		if len(p.Spec.PolicyTypes) == 0 {
+			continue
+		}
+
+		for _, rule := range ruleMaps(spec["ingress"]) {
+			if peers, ports, ok := ciliumRule(rule, "from", clusterWide); ok {
+				p.Spec.Ingress = append(p.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: peers, Ports: ports})
+			}
+		}
+		for _, rule := range ruleMaps(spec["egress"]) {
+			if peers, ports, ok := ciliumRule(rule, "to", clusterWide); ok {
+				p.Spec.Egress = append(p.Spec.Egress, networkingv1.NetworkPolicyEgressRule{To: peers, Ports: ports})
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// ruleMaps returns the rule objects of an unstructured rule list.
+func ruleMaps(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var result []map[string]interface{}
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// ciliumL3Keys are the peer selectors of Cilium ingress ("from") and egress
+// ("to") rules. Other keys, such as toPorts, icmps and the *Requires
+// constraints, do not select peers.
+var ciliumL3Keys = map[string][]string{
+	"from": {"fromEndpoints", "fromCIDR", "fromCIDRSet", "fromEntities", "fromGroups", "fromNodes"},
+	"to":   {"toEndpoints", "toCIDR", "toCIDRSet", "toEntities", "toFQDNs", "toServices", "toGroups", "toNodes"},
+}
+
+// ciliumRule translates one Cilium rule. prefix is "from" for ingress and
+// "to" for egress. It returns false when the rule allows nothing, or has L3
+// matchers none of which can be expressed as NetworkPolicy peers.
+func ciliumRule(rule map[string]interface{}, prefix string, clusterWide bool) ([]networkingv1.NetworkPolicyPeer, []networkingv1.NetworkPolicyPort, bool) {
+	var peers []networkingv1.NetworkPolicyPeer
+	hasL3 := false
+
+	for _, key := range ciliumL3Keys[prefix] {
+		if _, ok := rule[key]; ok {
+			hasL3 = true
+		}
+	}
+
+	for _, raw := range ruleMaps(rule[prefix+"Endpoints"]) {
+		podSel, nsSel, ok := ciliumSelector(raw)
+		if !ok {
+			continue
+		}
+		peer := networkingv1.NetworkPolicyPeer{PodSelector: podSel, NamespaceSelector: nsSel}
+		if nsSel == nil && clusterWide {
+			peer.NamespaceSelector = &metav1.LabelSelector{}
+		}
+		peers = append(peers, peer)
+	}
+
+	cidrs, _ := rule[prefix+"CIDR"].([]interface{})
+	for _, c := range cidrs {
+		if s, ok := c.(string); ok {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: normalizeCIDR(s)}})
+		}
+	}
+	for _, set := range ruleMaps(rule[prefix+"CIDRSet"]) {
+		cidr, _ := set["cidr"].(string)
+		if cidr == "" {
+			continue
+		}
+		block := &networkingv1.IPBlock{CIDR: normalizeCIDR(cidr)}
+		except, _ := set["except"].([]interface{})
+		for _, e := range except {
+			if s, ok := e.(string); ok {
+				block.Except = append(block.Except, normalizeCIDR(s))
+			}
+		}
+		peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: block})
+	}
+
+	entities, _ := rule[prefix+"Entities"].([]interface{})
+	for _, e := range entities {
+		switch e {
+		case "world", "all":
+			peers = append(peers,
+				networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0"}},
+				networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "::/0"}},
+			)
+			if e == "all" {
+				peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{}})
+			}
+		case "cluster":
+			peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{}})
+		}
+	}
+
+	if hasL3 && len(peers) == 0 {
+		return nil, nil, false
+	}
+
+	var ports []networkingv1.NetworkPolicyPort
+	for _, tp := range ruleMaps(rule["toPorts"]) {
+		for _, pp := range ruleMaps(tp["ports"]) {
+			ports = append(ports, ciliumPorts(pp)...)
+		}
+	}
+	// An empty rule selects nothing in Cilium (it is the default-deny idiom),
+	// while an L4-only rule allows the ports from every peer.
+	if !hasL3 && len(ports) == 0 {
+		return nil, nil, false
+	}
+	return peers, ports, true
+}
+
+// ciliumPorts converts a Cilium PortProtocol into NetworkPolicy ports.
+// Protocol ANY (or unset) expands to TCP and UDP.
+func ciliumPorts(pp map[string]interface{}) []networkingv1.NetworkPolicyPort {
+	protocols := []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP}
+	if proto, _ := pp["protocol"].(string); proto != "" && !strings.EqualFold(proto, "ANY") {
+		protocols = []corev1.Protocol{corev1.Protocol(strings.ToUpper(proto))}
+	}
+
+	var port *intstr.IntOrString
+	if v, ok := pp["port"]; ok && v != nil {
+		if raw := fmt.Sprint(v); raw != "" && raw != "0" {
+			parsed := intstr.Parse(raw)
+			port = &parsed
+		}
+	}
+	var endPort *int32
+	if ep, ok := pp["endPort"].(int64); ok && ep > 0 {
+		v := int32(ep)
+		endPort = &v
+	}
+
+	ports := make([]networkingv1.NetworkPolicyPort, 0, len(protocols))
+	for _, proto := range protocols {
+		proto := proto
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &proto, Port: port, EndPort: endPort})
+	}
+	return ports
+}
+
+// Cilium selector label keys that refer to the namespace rather than the pod.
+const (
+	ciliumNamespaceKey       = "io.kubernetes.pod.namespace"
+	ciliumNamespaceLabelsKey = "io.cilium.k8s.namespace.labels."
+)
+
+// ciliumSelector splits a Cilium endpoint selector into a pod selector and,
+// when it constrains the namespace, a namespace selector. Source prefixes
+// such as "k8s:" are stripped. It returns false for selectors on reserved
+// identities (hosts, health endpoints) that do not correspond to pods.
+func ciliumSelector(raw map[string]interface{}) (*metav1.LabelSelector, *metav1.LabelSelector, bool) {
+	var ls metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &ls); err != nil {
+		return nil, nil, false
+	}
+
+	pod := &metav1.LabelSelector{}
+	var ns *metav1.LabelSelector
+	nsSel := func() *metav1.LabelSelector {
+		if ns == nil {
+			ns = &metav1.LabelSelector{}
+		}
+		return ns
+	}
+
+	for key, value := range ls.MatchLabels {
+		key, ok := stripCiliumSource(key)
+		if !ok {
+			return nil, nil, false
+		}
+		switch {
+		case key == ciliumNamespaceKey:
+			setMatchLabel(nsSel(), corev1.LabelMetadataName, value)
+		case strings.HasPrefix(key, ciliumNamespaceLabelsKey):
+			setMatchLabel(nsSel(), strings.TrimPrefix(key, ciliumNamespaceLabelsKey), value)
+		default:
+			setMatchLabel(pod, key, value)
+		}
+	}
+	for _, expr := range ls.MatchExpressions {
+		key, ok := stripCiliumSource(expr.Key)
+		if !ok {
+			return nil, nil, false
+		}
+		expr.Key = key
+		switch {
+		case key == ciliumNamespaceKey:
+			expr.Key = corev1.LabelMetadataName
+			nsSel().MatchExpressions = append(nsSel().MatchExpressions, expr)
+		case strings.HasPrefix(key, ciliumNamespaceLabelsKey):
+			expr.Key = strings.TrimPrefix(key, ciliumNamespaceLabelsKey)
+			nsSel().MatchExpressions = append(nsSel().MatchExpressions, expr)
+		default:
+			pod.MatchExpressions = append(pod.MatchExpressions, expr)
+		}
+	}
+	return pod, ns, true
+}
+
+// stripCiliumSource removes the label source prefix from a Cilium selector
+// key. Reserved and other non-Kubernetes sources are rejected.
+func stripCiliumSource(key string) (string, bool) {
+	source, rest, ok := strings.Cut(key, ":")
+	if !ok {
+		return key, true
+	}
+	switch source {
+	case "k8s", "any":
+		return rest, true
+	default:
+		return "", false
+	}
+}
+
+func setMatchLabel(ls *metav1.LabelSelector, key, value string) {
+	if ls.MatchLabels == nil {
+		ls.MatchLabels = make(map[string]string)
+	}
+	ls.MatchLabels[key] = value
+}
+
+// normalizeCIDR turns a bare IP into a host CIDR.
+func normalizeCIDR(s string) string {
+	if strings.Contains(s, "/") {
+		return s
+	}
+	if strings.Contains(s, ":") {
+		return s + "/128"
+	}
+	return s + "/32"
+}
+
+// --- Calico ---
+
+// Calico selector keys that refer to the namespace rather than the pod.
+const (
+	calicoNamespaceKey       = "projectcalico.org/namespace"
+	calicoNameKey            = "projectcalico.org/name"
+	calicoNamespaceLabelsKey = "pcns."
+)
+
+// translateCalico converts a Calico GlobalNetworkPolicy into a NetworkPolicy
+// for namespace. It returns nil when the policy does not apply to the
+// namespace, and an error when a selector cannot be translated.
+func translateCalico(obj *unstructured.Unstructured, namespace string, nsLabels map[string]string) (*networkingv1.NetworkPolicy, error) {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+
+	selector, _ := spec["selector"].(string)
+	podSel, nsSel, err := calicoEndpointSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("selector %q: %w", selector, err)
+	}
+	if raw, _ := spec["namespaceSelector"].(string); raw != "" {
+		sel, err := parseCalicoSelector(raw, true)
+		if err != nil {
+			return nil, fmt.Errorf("namespaceSelector %q: %w", raw, err)
+		}
+		nsSel = mergeSelectors(nsSel, sel)
+	}
+	if nsSel != nil && !selectorMatches(nsSel, nsLabels) {
+		return nil, nil
+	}
+
+	p := translatedPolicy(kindCalicoGlobalNetworkPolicy, obj.GetName(), namespace, podSel)
+
+	ingress, egress := ruleMaps(spec["ingress"]), ruleMaps(spec["egress"])
+	types, _ := spec["types"].([]interface{})
+	for _, t := range types {
+		if s, ok := t.(string); ok {
+			p.Spec.PolicyTypes = append(p.Spec.PolicyTypes, networkingv1.PolicyType(s))
+		}
+	}
+	if len(p.Spec.PolicyTypes) == 0 {
+		p.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		if len(egress) > 0 {
+			p.Spec.PolicyTypes = append(p.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+
+	for _, rule := range ingress {
+		if peers, ports, ok := calicoRule(rule, "source"); ok {
+			p.Spec.Ingress = append(p.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: peers, Ports: ports})
+		}
+	}
+	for _, rule := range egress {
+		if peers, ports, ok := calicoRule(rule, "destination"); ok {
+			p.Spec.Egress = append(p.Spec.Egress, networkingv1.NetworkPolicyEgressRule{To: peers, Ports: ports})
+		}
+	}
+	return &p, nil
+}
+
+// calicoRule translates an Allow rule. peerField is "source" for ingress and
+// "destination" for egress; ports always come from the destination. Rules
+// with other actions or untranslatable matchers are dropped.
+func calicoRule(rule map[string]interface{}, peerField string) ([]networkingv1.NetworkPolicyPeer, []networkingv1.NetworkPolicyPort, bool) {
+	if action, _ := rule["action"].(string); action != "Allow" {
+		return nil, nil, false
+	}
+	for _, unsupported := range []string{"notProtocol", "icmp", "notICMP", "http"} {
+		if rule[unsupported] != nil {
+			return nil, nil, false
+		}
+	}
+
+	entity, _ := rule[peerField].(map[string]interface{})
+	for _, unsupported := range []string{"notSelector", "notNets", "notPorts", "serviceAccounts", "services"} {
+		if entity[unsupported] != nil {
+			return nil, nil, false
+		}
+	}
+
+	nets, _ := entity["nets"].([]interface{})
+	if n, _ := entity["net"].(string); n != "" {
+		nets = append(nets, n)
+	}
+	var blocks []*networkingv1.IPBlock
+	for _, n := range nets {
+		if s, ok := n.(string); ok {
+			blocks = append(blocks, &networkingv1.IPBlock{CIDR: normalizeCIDR(s)})
+		}
+	}
+
+	var peers []networkingv1.NetworkPolicyPeer
+	selector, _ := entity["selector"].(string)
+	nsSelector, _ := entity["namespaceSelector"].(string)
+	if selector != "" || nsSelector != "" {
+		podSel, nsSel, err := calicoEndpointSelector(selector)
+		if err != nil {
+			return nil, nil, false
+		}
+		if nsSelector != "" {
+			sel, err := parseCalicoSelector(nsSelector, true)
+			if err != nil {
+				return nil, nil, false
+			}
+			nsSel = mergeSelectors(nsSel, sel)
+		}
+		if nsSel == nil {
+			// Selectors in global policies match endpoints in every namespace.
+			nsSel = &metav1.LabelSelector{}
+		}
+		peer := networkingv1.NetworkPolicyPeer{PodSelector: podSel, NamespaceSelector: nsSel}
+		if len(blocks) == 0 {
+			peers = append(peers, peer)
+		}
+		// Calico ANDs the selectors with nets: only selected endpoints
+		// whose address is in one of the nets match. The peers carry both,
+		// which the engine evaluates as an intersection (see addressBlock).
+		for _, block := range blocks {
+			peer := peer
+			peer.IPBlock = block
+			peers = append(peers, peer)
+		}
+	} else {
+		for _, block := range blocks {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: block})
+		}
+	}
+
+	var protocols []corev1.Protocol
+	switch proto := rule["protocol"].(type) {
+	case string:
+		protocols = []corev1.Protocol{corev1.Protocol(strings.ToUpper(proto))}
+	case nil:
+		protocols = []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP}
+	default:
+		// Numeric protocols have no NetworkPolicy equivalent.
+		return nil, nil, false
+	}
+
+	destination, _ := rule["destination"].(map[string]interface{})
+	rawPorts, _ := destination["ports"].([]interface{})
+	var ports []networkingv1.NetworkPolicyPort
+	for _, raw := range rawPorts {
+		port, endPort, ok := calicoPort(raw)
+		if !ok {
+			return nil, nil, false
+		}
+		for _, proto := range protocols {
+			proto := proto
+			ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &proto, Port: port, EndPort: endPort})
+		}
+	}
+	if len(ports) == 0 && rule["protocol"] != nil {
+		for _, proto := range protocols {
+			proto := proto
+			ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &proto})
+		}
+	}
+	return peers, ports, true
+}
+
+// calicoPort parses a Calico port: a number, a "min:max" range or a name.
+func calicoPort(raw interface{}) (*intstr.IntOrString, *int32, bool) {
+	switch v := raw.(type) {
+	case int64:
+		p := intstr.FromInt32(int32(v))
+		return &p, nil, true
+	case float64:
+		p := intstr.FromInt32(int32(v))
+		return &p, nil, true
+	case string:
+		if lo, hi, ok := strings.Cut(v, ":"); ok {
+			min, err1 := strconv.ParseInt(lo, 10, 32)
+			max, err2 := strconv.ParseInt(hi, 10, 32)
+			if err1 != nil || err2 != nil {
+				return nil, nil, false
+			}
+			p := intstr.FromInt32(int32(min))
+			end := int32(max)
+			return &p, &end, true
+		}
+		p := intstr.Parse(v)
+		return &p, nil, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// calicoEndpointSelector parses an endpoint selector and moves namespace
+// terms (projectcalico.org/namespace, pcns.*) into a namespace selector.
+func calicoEndpointSelector(expr string) (*metav1.LabelSelector, *metav1.LabelSelector, error) {
+	sel, err := parseCalicoSelector(expr, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pod := &metav1.LabelSelector{}
+	var ns *metav1.LabelSelector
+	for _, req := range sel.MatchExpressions {
+		switch {
+		case req.Key == calicoNamespaceKey:
+			req.Key = corev1.LabelMetadataName
+		case strings.HasPrefix(req.Key, calicoNamespaceLabelsKey):
+			req.Key = strings.TrimPrefix(req.Key, calicoNamespaceLabelsKey)
+		default:
+			pod.MatchExpressions = append(pod.MatchExpressions, req)
+			continue
+		}
+		if ns == nil {
+			ns = &metav1.LabelSelector{}
+		}
+		ns.MatchExpressions = append(ns.MatchExpressions, req)
+	}
+	return pod, ns, nil
+}
+
+// mergeSelectors returns the conjunction of two selectors.
+func mergeSelectors(a, b *metav1.LabelSelector) *metav1.LabelSelector {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &metav1.LabelSelector{MatchExpressions: append(append([]metav1.LabelSelectorRequirement(nil), a.MatchExpressions...), b.MatchExpressions...)}
+}
+
+// parseCalicoSelector converts the subset of the Calico selector language
+// that maps onto label selectors: all(), has(k), !has(k), k == 'v',
+// k != 'v', k in {...} and k not in {...}, joined with &&. For namespace
+// selectors, projectcalico.org/name is mapped to the namespace name label.
+func parseCalicoSelector(expr string, namespace bool) (*metav1.LabelSelector, error) {
+	sel := &metav1.LabelSelector{}
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "all()" {
+		return sel, nil
+	}
+	stripped := strings.NewReplacer("!has(", "", "has(", "", ")", "").Replace(expr)
+	if strings.Contains(expr, "||") || strings.Contains(stripped, "(") {
+		return nil, fmt.Errorf("unsupported selector expression")
+	}
+
+	for _, term := range strings.Split(expr, "&&") {
+		req, err := parseCalicoTerm(strings.TrimSpace(term))
+		if err != nil {
+			return nil, err
+		}
+		if namespace && req.Key == calicoNameKey {
+			req.Key = corev1.LabelMetadataName
+		}
+		sel.MatchExpressions = append(sel.MatchExpressions, req)
+	}
+	return sel, nil
+}
+
+// parseCalicoTerm parses a single selector term.
+func parseCalicoTerm(term string) (metav1.LabelSelectorRequirement, error) {
+	switch {
+	case term == "all()":
+		return metav1.LabelSelectorRequirement{}, fmt.Errorf("all() cannot be combined with other terms")
+	case strings.HasPrefix(term, "!has(") && strings.HasSuffix(term, ")"):
+		return metav1.LabelSelectorRequirement{Key: strings.TrimSpace(term[5 : len(term)-1]), Operator: metav1.LabelSelectorOpDoesNotExist}, nil
+	case strings.HasPrefix(term, "has(") && strings.HasSuffix(term, ")"):
+		return metav1.LabelSelectorRequirement{Key: strings.TrimSpace(term[4 : len(term)-1]), Operator: metav1.LabelSelectorOpExists}, nil
+	}
+
+	for _, op := range []struct {
+		token    string
+		operator metav1.LabelSelectorOperator
+	}{
+		{" not in ", metav1.LabelSelectorOpNotIn},
+		{" in ", metav1.LabelSelectorOpIn},
+		{"==", metav1.LabelSelectorOpIn},
+		{"!=", metav1.LabelSelectorOpNotIn},
+	} {
+		key, value, ok := strings.Cut(term, op.token)
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		var values []string
+		if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+			for _, v := range strings.Split(value[1:len(value)-1], ",") {
+				values = append(values, unquote(strings.TrimSpace(v)))
+			}
+		} else {
+			values = []string{unquote(value)}
+		}
+		if key == "" || len(values) == 0 {
+			break
+		}
+		return metav1.LabelSelectorRequirement{Key: key, Operator: op.operator, Values: values}, nil
+	}
+	return metav1.LabelSelectorRequirement{}, fmt.Errorf("unsupported selector term %q", term)
+}
+
+// unquote strips single or double quotes from a selector value.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
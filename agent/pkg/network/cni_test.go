@@ -0,0 +1,222 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCiliumRule(t *testing.T) {
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	https := intstr.FromInt32(443)
+	dns := intstr.FromInt32(53)
+
+	httpsPorts := []interface{}{
+		map[string]interface{}{"ports": []interface{}{
+			map[string]interface{}{"port": "443", "protocol": "TCP"},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		rule        map[string]interface{}
+		prefix      string
+		clusterWide bool
+		wantPeers   []networkingv1.NetworkPolicyPeer
+		wantPorts   []networkingv1.NetworkPolicyPort
+		wantOK      bool
+	}{
+		{
+			name:   "empty rule allows nothing",
+			rule:   map[string]interface{}{},
+			prefix: "to",
+			wantOK: false,
+		},
+		{
+			name:      "L4-only egress rule",
+			rule:      map[string]interface{}{"toPorts": httpsPorts},
+			prefix:    "to",
+			wantPorts: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &https}},
+			wantOK:    true,
+		},
+		{
+			name:      "L4-only ingress rule",
+			rule:      map[string]interface{}{"toPorts": httpsPorts},
+			prefix:    "from",
+			wantPorts: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &https}},
+			wantOK:    true,
+		},
+		{
+			name: "protocol ANY expands to TCP and UDP",
+			rule: map[string]interface{}{"toPorts": []interface{}{
+				map[string]interface{}{"ports": []interface{}{
+					map[string]interface{}{"port": "53", "protocol": "ANY"},
+				}},
+			}},
+			prefix:    "to",
+			wantPorts: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &dns}, {Protocol: &udp, Port: &dns}},
+			wantOK:    true,
+		},
+		{
+			name: "endpoints with namespace label",
+			rule: map[string]interface{}{"fromEndpoints": []interface{}{
+				map[string]interface{}{"matchLabels": map[string]interface{}{
+					"k8s:app":                     "web",
+					"io.kubernetes.pod.namespace": "shop",
+				}},
+			}},
+			prefix: "from",
+			wantPeers: []networkingv1.NetworkPolicyPeer{{
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "shop"}},
+			}},
+			wantOK: true,
+		},
+		{
+			name: "cluster-wide endpoints match every namespace",
+			rule: map[string]interface{}{"toEndpoints": []interface{}{
+				map[string]interface{}{"matchLabels": map[string]interface{}{"app": "db"}},
+			}},
+			prefix:      "to",
+			clusterWide: true,
+			wantPeers: []networkingv1.NetworkPolicyPeer{{
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				NamespaceSelector: &metav1.LabelSelector{},
+			}},
+			wantOK: true,
+		},
+		{
+			name: "CIDR set with exceptions",
+			rule: map[string]interface{}{"toCIDRSet": []interface{}{
+				map[string]interface{}{"cidr": "10.0.0.0/8", "except": []interface{}{"10.1.0.0/16"}},
+			}},
+			prefix: "to",
+			wantPeers: []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "world entity",
+			rule:   map[string]interface{}{"fromEntities": []interface{}{"world"}},
+			prefix: "from",
+			wantPeers: []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0"}},
+				{IPBlock: &networkingv1.IPBlock{CIDR: "::/0"}},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "FQDN peers cannot be translated",
+			rule:   map[string]interface{}{"toFQDNs": []interface{}{map[string]interface{}{"matchName": "example.com"}}, "toPorts": httpsPorts},
+			prefix: "to",
+			wantOK: false,
+		},
+		{
+			name: "reserved identities cannot be translated",
+			rule: map[string]interface{}{"fromEndpoints": []interface{}{
+				map[string]interface{}{"matchLabels": map[string]interface{}{"reserved:host": ""}},
+			}},
+			prefix: "from",
+			wantOK: false,
+		},
+		{
+			name:   "egress selectors ignored on ingress",
+			rule:   map[string]interface{}{"toEndpoints": []interface{}{map[string]interface{}{}}, "toPorts": httpsPorts},
+			prefix: "from",
+			wantPorts: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &https},
+			},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peers, ports, ok := ciliumRule(tt.rule, tt.prefix, tt.clusterWide)
+			if ok != tt.wantOK {
+				t.Fatalf("ciliumRule() ok = %t, want %t", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(peers, tt.wantPeers) {
+				t.Errorf("peers = %+v, want %+v", peers, tt.wantPeers)
+			}
+			if !reflect.DeepEqual(ports, tt.wantPorts) {
+				t.Errorf("ports = %+v, want %+v", ports, tt.wantPorts)
+			}
+		})
+	}
+}
+
+func TestTranslateCalico(t *testing.T) {
+	gnp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "projectcalico.org/v3",
+		"kind":       "GlobalNetworkPolicy",
+		"metadata":   map[string]interface{}{"name": "web-ingress"},
+		"spec": map[string]interface{}{
+			"selector": "app == 'web'",
+			"types":    []interface{}{"Ingress"},
+			"ingress": []interface{}{
+				map[string]interface{}{
+					"action": "Allow",
+					"source": map[string]interface{}{
+						"selector": "app == 'client'",
+						"nets":     []interface{}{"10.0.1.0/24"},
+					},
+				},
+				map[string]interface{}{
+					"action": "Allow",
+					"source": map[string]interface{}{"nets": []interface{}{"192.168.0.0/16"}},
+				},
+			},
+		},
+	}}
+
+	p, err := translateCalico(gnp, "shop", map[string]string{corev1.LabelMetadataName: "shop"})
+	if err != nil || p == nil {
+		t.Fatalf("translateCalico() = %v, %v", p, err)
+	}
+
+	pod := func(name, app, ip string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: map[string]string{"app": app}},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("web", "web", "10.0.1.2"),
+		pod("client", "client", "10.0.1.5"),
+		pod("remote-client", "client", "10.0.9.5"),
+		pod("batch", "batch", "10.0.1.8"),
+		pod("legacy", "batch", "192.168.4.4"),
+	}
+	e := NewEngine([]corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}}, pods, []networkingv1.NetworkPolicy{*p})
+	web := e.FindPod("shop", "web")
+
+	// selector and nets must both match; nets alone match any address.
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"client", true},
+		{"remote-client", false},
+		{"batch", false},
+		{"legacy", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			if got := e.CanReach(e.FindPod("shop", tt.src), web, 80, corev1.ProtocolTCP).Allowed; got != tt.want {
+				t.Errorf("CanReach(%s, web) = %t, want %t", tt.src, got, tt.want)
+			}
+		})
+	}
+
+	// Only the nets-only peer is an address range open to external clients.
+	if got, want := e.ingressIPBlocks(web), []string{"192.168.0.0/16"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ingressIPBlocks() = %v, want %v", got, want)
+	}
+}
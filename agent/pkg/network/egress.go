@@ -0,0 +1,293 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// privateRanges are address ranges that are not routable on the public
+// internet. A CIDR contained entirely in one of them is considered internal.
+var privateRanges = mustParseCIDRs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"fc00::/7",
+	"fe80::/10",
+	"::1/128",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	result := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+// isAllAddresses reports whether a CIDR covers the whole address space.
+func isAllAddresses(cidr string) bool {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ones, _ := n.Mask.Size()
+	return ones == 0
+}
+
+// includesPublic reports whether a CIDR contains addresses outside the
+// private ranges.
+func includesPublic(cidr string) bool {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ones, bits := n.Mask.Size()
+	for _, private := range privateRanges {
+		pOnes, pBits := private.Mask.Size()
+		if bits == pBits && ones >= pOnes && private.Contains(n.IP) {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleRef identifies a rule within a policy, e.g. "ns/name egress[0]".
+func ruleRef(p *networkingv1.NetworkPolicy, direction networkingv1.PolicyType, index int) string {
+	return fmt.Sprintf("%s %s[%d]", policyRef(p), strings.ToLower(string(direction)), index)
+}
+
+// checkBroadRules reports rules that allow every address (0.0.0.0/0 or ::/0),
+// every peer (empty from/to) or every port (no ports list).
+func (a *Analyzer) checkBroadRules(
+	nsPolicies map[string][]networkingv1.NetworkPolicy,
+	now time.Time,
+) []scanner.Finding {
+	var findings []scanner.Finding
+	seen := make(map[string]bool)
+
+	for _, policies := range nsPolicies {
+		for i := range policies {
+			p := &policies[i]
+			// Cluster-wide policies are translated once per namespace; report them once.
+			resource := policyResource(p)
+			if seen[resource] {
+				continue
+			}
+			seen[resource] = true
+
+			for idx, rule := range p.Spec.Ingress {
+				findings = append(findings, broadRuleFindings(p, networkingv1.PolicyTypeIngress, idx, rule.From, rule.Ports, now)...)
+			}
+			for idx, rule := range p.Spec.Egress {
+				findings = append(findings, broadRuleFindings(p, networkingv1.PolicyTypeEgress, idx, rule.To, rule.Ports, now)...)
+			}
+		}
+	}
+
+	return findings
+}
+
+// broadRuleFindings evaluates a single ingress or egress rule.
+func broadRuleFindings(
+	p *networkingv1.NetworkPolicy,
+	direction networkingv1.PolicyType,
+	index int,
+	peers []networkingv1.NetworkPolicyPeer,
+	ports []networkingv1.NetworkPolicyPort,
+	now time.Time,
+) []scanner.Finding {
+	var findings []scanner.Finding
+
+	resource := policyResource(p)
+	ref := ruleRef(p, direction, index)
+	peerField := "from"
+	if direction == networkingv1.PolicyTypeEgress {
+		peerField = "to"
+	}
+	details := func(extra ...string) map[string]string {
+		d := map[string]string{
+			"policy":     policyRef(p),
+			"direction":  string(direction),
+			"rule_index": fmt.Sprintf("%d", index),
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			d[extra[i]] = extra[i+1]
+		}
+		return d
+	}
+
+	for _, peer := range peers {
+		if addressBlock(peer) == nil || !isAllAddresses(peer.IPBlock.CIDR) {
+			continue
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-009",
+			Title:       "NetworkPolicy rule allows all IP addresses",
+			Description: fmt.Sprintf("Rule %s allows %s traffic %s ipBlock %s", ref, strings.ToLower(string(direction)), peerField, peer.IPBlock.CIDR),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusWarning,
+			Category:    "network",
+			Resource:    resource,
+			Namespace:   p.Namespace,
+			Remediation: "Replace the catch-all ipBlock with the specific CIDRs the workload needs, or add except entries for ranges it must not reach.",
+			Details:     details("cidr", peer.IPBlock.CIDR, "except", strings.Join(peer.IPBlock.Except, ",")),
+			Timestamp:   now,
+		})
+	}
+
+	if len(peers) == 0 {
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-010",
+			Title:       "NetworkPolicy rule allows all peers",
+			Description: fmt.Sprintf("Rule %s has an empty %q list and allows %s traffic with every pod, namespace and external address", ref, peerField, strings.ToLower(string(direction))),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusWarning,
+			Category:    "network",
+			Resource:    resource,
+			Namespace:   p.Namespace,
+			Remediation: fmt.Sprintf("Add %q peers (podSelector, namespaceSelector or ipBlock) that name the workloads this rule is meant for.", peerField),
+			Details:     details(),
+			Timestamp:   now,
+		})
+	}
+
+	if len(ports) == 0 {
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-011",
+			Title:       "NetworkPolicy rule allows all ports",
+			Description: fmt.Sprintf("Rule %s has no ports list and allows %s traffic on every port and protocol", ref, strings.ToLower(string(direction))),
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusWarning,
+			Category:    "network",
+			Resource:    resource,
+			Namespace:   p.Namespace,
+			Remediation: "Restrict the rule to the ports and protocols the workload actually uses.",
+			Details:     details(),
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
+
+// InternetEgress returns the reasons a pod can send traffic to public
+// addresses: either no egress policy selects it, or one of the rules
+// allowing its egress has no peers or an ipBlock that includes public ranges.
+// An empty result means egress to the internet is blocked.
+func (e *Engine) InternetEgress(pod *corev1.Pod) []string {
+	var reasons []string
+	isolated := false
+
+	for i := range e.policies[pod.Namespace] {
+		p := &e.policies[pod.Namespace][i]
+		if !selectorMatches(&p.Spec.PodSelector, pod.Labels) || !hasPolicyType(p, networkingv1.PolicyTypeEgress) {
+			continue
+		}
+		isolated = true
+
+		for idx, rule := range p.Spec.Egress {
+			ref := ruleRef(p, networkingv1.PolicyTypeEgress, idx)
+			if len(rule.To) == 0 {
+				reasons = append(reasons, ref+" allows all destinations")
+				continue
+			}
+			for _, peer := range rule.To {
+				if block := addressBlock(peer); block != nil && includesPublic(block.CIDR) {
+					reasons = append(reasons, fmt.Sprintf("%s allows ipBlock %s", ref, block.CIDR))
+				}
+			}
+		}
+	}
+
+	if !isolated {
+		return []string{"no NetworkPolicy restricts egress"}
+	}
+	return reasons
+}
+
+// workloadRef returns the "Kind/namespace/name" of the controller owning a
+// pod. Pods created by a Deployment are attributed to the Deployment using
+// the pod-template-hash label. Unowned pods are returned as themselves.
+func workloadRef(pod *corev1.Pod) string {
+	if ref := k8s.OwnerIndex(nil).TopLevelOwner(pod); ref != "" {
+		return ref
+	}
+	return fmt.Sprintf("Pod/%s/%s", pod.Namespace, pod.Name)
+}
+
+// checkInternetEgress reports workloads whose effective egress includes the
+// public internet. Pods are grouped by owning controller.
+func (a *Analyzer) checkInternetEgress(engine *Engine, now time.Time) []scanner.Finding {
+	type workload struct {
+		namespace string
+		pods      []string
+		reasons   []string
+	}
+	workloads := make(map[string]*workload)
+
+	pods := engine.Pods()
+	for i := range pods {
+		pod := &pods[i]
+		if systemNamespaces[pod.Namespace] || isTerminated(pod) || pod.Spec.HostNetwork {
+			continue
+		}
+		reasons := engine.InternetEgress(pod)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		ref := workloadRef(pod)
+		w, ok := workloads[ref]
+		if !ok {
+			w = &workload{namespace: pod.Namespace, reasons: reasons}
+			workloads[ref] = w
+		}
+		w.pods = append(w.pods, pod.Name)
+	}
+
+	refs := make([]string, 0, len(workloads))
+	for ref := range workloads {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	var findings []scanner.Finding
+	for _, ref := range refs {
+		w := workloads[ref]
+		sort.Strings(w.pods)
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-012",
+			Title:       "Workload can reach the public internet",
+			Description: fmt.Sprintf("%s can send traffic to public internet addresses: %s", ref, strings.Join(w.reasons, "; ")),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusWarning,
+			Category:    "network",
+			Resource:    ref,
+			Namespace:   w.namespace,
+			Remediation: "Apply a default-deny egress policy and allow only the internal services and specific external CIDRs the workload needs.",
+			Details: map[string]string{
+				"egress_paths": strings.Join(w.reasons, "; "),
+				"pods":         strings.Join(w.pods, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,578 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubecomply/kubecomply/pkg/ingress"
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// internalLoadBalancerAnnotations mark a LoadBalancer Service as internal on
+// the major cloud providers. An empty value accepts anything but "false".
+var internalLoadBalancerAnnotations = map[string]string{
+	"service.beta.kubernetes.io/aws-load-balancer-internal":          "",
+	"service.beta.kubernetes.io/aws-load-balancer-scheme":            "internal",
+	"networking.gke.io/load-balancer-type":                           "Internal",
+	"cloud.google.com/load-balancer-type":                            "Internal",
+	"service.beta.kubernetes.io/azure-load-balancer-internal":        "true",
+	"service.beta.kubernetes.io/oci-load-balancer-internal":          "true",
+	"service.kubernetes.io/ibm-load-balancer-cloud-provider-ip-type": "private",
+}
+
+// sourceRangesAnnotation is the legacy annotation form of
+// spec.loadBalancerSourceRanges.
+const sourceRangesAnnotation = "service.beta.kubernetes.io/load-balancer-source-ranges"
+
+// Ingress protection levels reported in the exposure inventory.
+const (
+	protectionNone       = "none"
+	protectionAllowAll   = "allow-all"
+	protectionRestricted = "restricted"
+)
+
+var protectionRank = map[string]int{
+	protectionRestricted: 0,
+	protectionAllowAll:   1,
+	protectionNone:       2,
+}
+
+// exposurePath is one way traffic from outside the cluster reaches a Service.
+type exposurePath struct {
+	// via describes the path, e.g. "LoadBalancer Service/web/frontend".
+	via string
+	// http is set for Ingress and HTTPRoute paths.
+	http bool
+	// tls is set for HTTP paths that only accept TLS.
+	tls bool
+	// open is set for L4 paths without source address restrictions.
+	open bool
+}
+
+// isInternalLoadBalancer reports whether a LoadBalancer Service is annotated
+// to provision a load balancer without a public address.
+func isInternalLoadBalancer(svc *corev1.Service) bool {
+	for key, want := range internalLoadBalancerAnnotations {
+		got, ok := svc.Annotations[key]
+		if !ok {
+			continue
+		}
+		if (want == "" && !strings.EqualFold(got, "false")) || strings.EqualFold(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadBalancerSourceRanges returns the client CIDRs a LoadBalancer accepts,
+// from the spec or the legacy annotation.
+func loadBalancerSourceRanges(svc *corev1.Service) []string {
+	if len(svc.Spec.LoadBalancerSourceRanges) > 0 {
+		return svc.Spec.LoadBalancerSourceRanges
+	}
+	var ranges []string
+	for _, r := range strings.Split(svc.Annotations[sourceRangesAnnotation], ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// unrestrictedRanges reports whether a source range list admits every address.
+func unrestrictedRanges(ranges []string) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if isAllAddresses(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IngressProtection describes how NetworkPolicies restrict ingress to a pod:
+// "none" when no policy isolates it, "allow-all" when a rule admits every
+// peer or every address, and "restricted" otherwise.
+func (e *Engine) IngressProtection(pod *corev1.Pod) string {
+	isolated := false
+	for i := range e.policies[pod.Namespace] {
+		p := &e.policies[pod.Namespace][i]
+		if !selectorMatches(&p.Spec.PodSelector, pod.Labels) || !hasPolicyType(p, networkingv1.PolicyTypeIngress) {
+			continue
+		}
+		isolated = true
+		for _, rule := range p.Spec.Ingress {
+			if len(rule.From) == 0 {
+				return protectionAllowAll
+			}
+			for _, peer := range rule.From {
+				if block := addressBlock(peer); block != nil && isAllAddresses(block.CIDR) {
+					return protectionAllowAll
+				}
+			}
+		}
+	}
+	if !isolated {
+		return protectionNone
+	}
+	return protectionRestricted
+}
+
+// ingressIPBlocks returns the ipBlock CIDRs of the ingress rules that apply
+// to a pod.
+func (e *Engine) ingressIPBlocks(pod *corev1.Pod) []string {
+	var cidrs []string
+	for i := range e.policies[pod.Namespace] {
+		p := &e.policies[pod.Namespace][i]
+		if !selectorMatches(&p.Spec.PodSelector, pod.Labels) || !hasPolicyType(p, networkingv1.PolicyTypeIngress) {
+			continue
+		}
+		for _, rule := range p.Spec.Ingress {
+			for _, peer := range rule.From {
+				if block := addressBlock(peer); block != nil {
+					cidrs = append(cidrs, block.CIDR)
+				}
+			}
+		}
+	}
+	return cidrs
+}
+
+// servicePods returns the running pods selected by a Service. Services
+// without a selector (manually managed endpoints) select no pods.
+func (e *Engine) servicePods(svc *corev1.Service) []*corev1.Pod {
+	if len(svc.Spec.Selector) == 0 {
+		return nil
+	}
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	var pods []*corev1.Pod
+	for i := range e.pods {
+		pod := &e.pods[i]
+		if pod.Namespace == svc.Namespace && !isTerminated(pod) && selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// listRoutes returns the Ingresses and HTTPRoutes of the scanned namespaces
+// and all Gateways keyed by "namespace/name". Gateways are listed cluster-wide
+// because routes may attach to a Gateway in another namespace.
+func (a *Analyzer) listRoutes(ctx context.Context, scanNS map[string]bool) ([]networkingv1.Ingress, []k8s.HTTPRoute, map[string]*k8s.Gateway) {
+	var ingresses []networkingv1.Ingress
+	var routes []k8s.HTTPRoute
+	for ns := range scanNS {
+		nsIngresses, err := a.client.ListIngresses(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list ingresses", "namespace", ns, "error", err)
+		}
+		ingresses = append(ingresses, nsIngresses...)
+
+		nsRoutes, err := a.client.ListHTTPRoutes(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list HTTPRoutes", "namespace", ns, "error", err)
+		}
+		routes = append(routes, nsRoutes...)
+	}
+	sort.Slice(ingresses, func(i, j int) bool {
+		return ingresses[i].Namespace+"/"+ingresses[i].Name < ingresses[j].Namespace+"/"+ingresses[j].Name
+	})
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Namespace+"/"+routes[i].Name < routes[j].Namespace+"/"+routes[j].Name
+	})
+
+	gateways := make(map[string]*k8s.Gateway)
+	list, err := a.client.ListGateways(ctx, "")
+	if err != nil {
+		a.logger.Warn("failed to list gateways", "error", err)
+	}
+	for i := range list {
+		gateways[list[i].Namespace+"/"+list[i].Name] = &list[i]
+	}
+
+	return ingresses, routes, gateways
+}
+
+// checkServiceExposure analyzes how Services are reachable from outside the
+// cluster: LoadBalancers without source ranges (NET-016), externalIPs
+// (NET-017), externalTrafficPolicy hiding client addresses from ipBlock rules
+// (NET-018) and Services in front of pods without ingress isolation
+// (NET-019). Service, Ingress and HTTPRoute paths are then correlated per
+// workload into the internet-exposed workloads inventory (NET-020).
+func (a *Analyzer) checkServiceExposure(
+	engine *Engine,
+	services []corev1.Service,
+	ingresses []networkingv1.Ingress,
+	routes []k8s.HTTPRoute,
+	gateways map[string]*k8s.Gateway,
+	now time.Time,
+) []scanner.Finding {
+	var findings []scanner.Finding
+
+	// Exposure paths keyed by the "namespace/name" of the backing Service.
+	paths := make(map[string][]exposurePath)
+
+	for i := range services {
+		svc := &services[i]
+		key := svc.Namespace + "/" + svc.Name
+		resource := "Service/" + key
+
+		switch svc.Spec.Type {
+		case corev1.ServiceTypeLoadBalancer:
+			if isInternalLoadBalancer(svc) {
+				break
+			}
+			ranges := loadBalancerSourceRanges(svc)
+			open := unrestrictedRanges(ranges)
+			via := "LoadBalancer " + resource
+			if open {
+				findings = append(findings, scanner.Finding{
+					ID:          "NET-016",
+					Title:       "LoadBalancer accepts traffic from any address",
+					Description: fmt.Sprintf("Service %s provisions an external load balancer without loadBalancerSourceRanges, so every internet address can connect", key),
+					Severity:    scanner.SeverityHigh,
+					Status:      scanner.StatusFail,
+					Category:    "network",
+					Resource:    resource,
+					Namespace:   svc.Namespace,
+					Remediation: "Set spec.loadBalancerSourceRanges to the client CIDRs that need access, or annotate the Service for an internal load balancer if it is not meant to be public.",
+					Details: map[string]string{
+						"source_ranges": strings.Join(ranges, ","),
+					},
+					Timestamp: now,
+				})
+			} else {
+				via += " (source ranges " + strings.Join(ranges, ",") + ")"
+			}
+			paths[key] = append(paths[key], exposurePath{via: via, open: open})
+
+		case corev1.ServiceTypeNodePort:
+			paths[key] = append(paths[key], exposurePath{via: "NodePort " + resource, open: true})
+		}
+
+		if len(svc.Spec.ExternalIPs) > 0 {
+			findings = append(findings, scanner.Finding{
+				ID:          "NET-017",
+				Title:       "Service uses externalIPs",
+				Description: fmt.Sprintf("Service %s sets externalIPs %s. Any user who can create Services can claim arbitrary IPs this way and intercept cluster traffic to them (CVE-2020-8554)", key, strings.Join(svc.Spec.ExternalIPs, ",")),
+				Severity:    scanner.SeverityHigh,
+				Status:      scanner.StatusFail,
+				Category:    "network",
+				Resource:    resource,
+				Namespace:   svc.Namespace,
+				Remediation: "Remove spec.externalIPs in favour of a LoadBalancer or Ingress, and block the field cluster-wide with the DenyServiceExternalIPs admission plugin or a policy engine rule.",
+				Details: map[string]string{
+					"external_ips": strings.Join(svc.Spec.ExternalIPs, ","),
+					"cve":          "CVE-2020-8554",
+				},
+				Timestamp: now,
+			})
+			paths[key] = append(paths[key], exposurePath{
+				via:  fmt.Sprintf("externalIPs %s on %s", strings.Join(svc.Spec.ExternalIPs, ","), resource),
+				open: true,
+			})
+		}
+
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer || svc.Spec.Type == corev1.ServiceTypeNodePort {
+			findings = append(findings, trafficPolicyFindings(engine, svc, now)...)
+		}
+	}
+
+	ingressExposure(ingresses, paths)
+	engine.routeExposure(routes, gateways, paths)
+	findings = append(findings, unprotectedServices(engine, services, paths, now)...)
+	findings = append(findings, exposedWorkloads(engine, services, paths, now)...)
+
+	return findings
+}
+
+// trafficPolicyFindings reports externally reachable Services whose
+// externalTrafficPolicy is Cluster while the backing pods rely on ipBlock
+// ingress rules. With Cluster, traffic is SNATed to a node address before it
+// reaches the pod, so ipBlock rules never see the real client address.
+func trafficPolicyFindings(engine *Engine, svc *corev1.Service, now time.Time) []scanner.Finding {
+	if svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var cidrs []string
+	for _, pod := range engine.servicePods(svc) {
+		for _, cidr := range engine.ingressIPBlocks(pod) {
+			if !seen[cidr] {
+				seen[cidr] = true
+				cidrs = append(cidrs, cidr)
+			}
+		}
+	}
+	if len(cidrs) == 0 {
+		return nil
+	}
+	sort.Strings(cidrs)
+
+	return []scanner.Finding{{
+		ID:          "NET-018",
+		Title:       "externalTrafficPolicy Cluster hides client addresses from ipBlock rules",
+		Description: fmt.Sprintf("Service %s/%s uses externalTrafficPolicy Cluster, so external clients are SNATed to node addresses and the ipBlock ingress rules (%s) on its pods cannot filter them", svc.Namespace, svc.Name, strings.Join(cidrs, ",")),
+		Severity:    scanner.SeverityMedium,
+		Status:      scanner.StatusWarning,
+		Category:    "network",
+		Resource:    fmt.Sprintf("Service/%s/%s", svc.Namespace, svc.Name),
+		Namespace:   svc.Namespace,
+		Remediation: "Set externalTrafficPolicy: Local to preserve client source addresses, or enforce the client allow-list with loadBalancerSourceRanges instead of ipBlock rules.",
+		Details: map[string]string{
+			"external_traffic_policy": string(corev1.ServiceExternalTrafficPolicyCluster),
+			"ip_blocks":               strings.Join(cidrs, ","),
+		},
+		Timestamp: now,
+	}}
+}
+
+// ingressExposure records the Services behind each Ingress as exposure
+// paths. Missing TLS is reported by the ingress analyzer (ING-001).
+func ingressExposure(ingresses []networkingv1.Ingress, paths map[string][]exposurePath) {
+	for i := range ingresses {
+		ing := &ingresses[i]
+
+		addBackend := func(backend *networkingv1.IngressBackend, host string) {
+			if backend == nil || backend.Service == nil {
+				return
+			}
+			display := host
+			if display == "" {
+				display = "*"
+			}
+			key := ing.Namespace + "/" + backend.Service.Name
+			paths[key] = append(paths[key], exposurePath{
+				via:  fmt.Sprintf("Ingress %s/%s host %s", ing.Namespace, ing.Name, display),
+				http: true,
+				tls:  ingress.HostHasTLS(ing, host),
+			})
+		}
+
+		addBackend(ing.Spec.DefaultBackend, "")
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for j := range rule.HTTP.Paths {
+				addBackend(&rule.HTTP.Paths[j].Backend, rule.Host)
+			}
+		}
+	}
+}
+
+// routeExposure records the Services behind each HTTPRoute attached to a
+// Gateway listener as exposure paths. Routes without an HTTPS listener are
+// reported by the ingress analyzer (ING-002).
+func (e *Engine) routeExposure(routes []k8s.HTTPRoute, gateways map[string]*k8s.Gateway, paths map[string][]exposurePath) {
+	for i := range routes {
+		route := &routes[i]
+		var parents []string
+		hasHTTP := false
+
+		for _, ref := range route.Spec.ParentRefs {
+			if !ref.IsGateway() {
+				continue
+			}
+			gwKey := route.ParentNamespace(ref) + "/" + ref.Name
+			gw := gateways[gwKey]
+			if gw == nil {
+				continue
+			}
+			listeners := ingress.AttachedListeners(route, ref, gw, e.namespaceLabels[route.Namespace])
+			if len(listeners) == 0 {
+				continue
+			}
+			for _, l := range listeners {
+				hasHTTP = hasHTTP || l.Protocol == "HTTP"
+			}
+			parents = append(parents, "Gateway "+gwKey)
+		}
+		if len(parents) == 0 {
+			continue
+		}
+
+		via := fmt.Sprintf("HTTPRoute %s/%s via %s", route.Namespace, route.Name, strings.Join(parents, ","))
+		for _, rule := range route.Spec.Rules {
+			for _, backend := range rule.BackendRefs {
+				if !backend.IsService() {
+					continue
+				}
+				key := route.BackendNamespace(backend) + "/" + backend.Name
+				paths[key] = append(paths[key], exposurePath{via: via, http: true, tls: !hasHTTP})
+			}
+		}
+	}
+}
+
+// unprotectedServices reports Services whose pods are not isolated for
+// ingress by any NetworkPolicy (NET-019). Services reachable from outside
+// the cluster are reported with high severity.
+func unprotectedServices(engine *Engine, services []corev1.Service, paths map[string][]exposurePath, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for i := range services {
+		svc := &services[i]
+		if systemNamespaces[svc.Namespace] {
+			continue
+		}
+		var unprotected []string
+		for _, pod := range engine.servicePods(svc) {
+			if engine.IngressProtection(pod) == protectionNone {
+				unprotected = append(unprotected, pod.Name)
+			}
+		}
+		if len(unprotected) == 0 {
+			continue
+		}
+		sort.Strings(unprotected)
+
+		key := svc.Namespace + "/" + svc.Name
+		severity, reach := scanner.SeverityLow, "inside the cluster"
+		if len(paths[key]) > 0 {
+			severity, reach = scanner.SeverityHigh, "from outside the cluster"
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-019",
+			Title:       "Service selects pods without NetworkPolicy ingress protection",
+			Description: fmt.Sprintf("Service %s is reachable %s and %d of its pods are not isolated for ingress by any NetworkPolicy", key, reach, len(unprotected)),
+			Severity:    severity,
+			Status:      scanner.StatusFail,
+			Category:    "network",
+			Resource:    "Service/" + key,
+			Namespace:   svc.Namespace,
+			Remediation: "Apply an ingress NetworkPolicy to the Service's pods that admits only the clients, ingress controller or load balancer that should reach it.",
+			Details: map[string]string{
+				"pods": strings.Join(unprotected, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// exposedWorkloads correlates the exposure paths of each Service with the
+// workloads behind it and reports one inventory entry per workload (NET-020).
+func exposedWorkloads(engine *Engine, services []corev1.Service, paths map[string][]exposurePath, now time.Time) []scanner.Finding {
+	type workload struct {
+		namespace  string
+		pods       map[string]bool
+		via        []string
+		seenVia    map[string]bool
+		protection string
+		httpPaths  int
+		tlsPaths   int
+		open       bool
+	}
+	workloads := make(map[string]*workload)
+
+	for i := range services {
+		svc := &services[i]
+		svcPaths := paths[svc.Namespace+"/"+svc.Name]
+		if len(svcPaths) == 0 {
+			continue
+		}
+		for _, pod := range engine.servicePods(svc) {
+			ref := workloadRef(pod)
+			w, ok := workloads[ref]
+			if !ok {
+				w = &workload{
+					namespace:  pod.Namespace,
+					pods:       make(map[string]bool),
+					seenVia:    make(map[string]bool),
+					protection: protectionRestricted,
+				}
+				workloads[ref] = w
+			}
+			w.pods[pod.Name] = true
+			if p := engine.IngressProtection(pod); protectionRank[p] > protectionRank[w.protection] {
+				w.protection = p
+			}
+			for _, path := range svcPaths {
+				if w.seenVia[path.via] {
+					continue
+				}
+				w.seenVia[path.via] = true
+				w.via = append(w.via, path.via)
+				w.open = w.open || path.open
+				if path.http {
+					w.httpPaths++
+					if path.tls {
+						w.tlsPaths++
+					}
+				}
+			}
+		}
+	}
+
+	refs := make([]string, 0, len(workloads))
+	for ref := range workloads {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	var findings []scanner.Finding
+	for _, ref := range refs {
+		w := workloads[ref]
+
+		tls := "n/a"
+		switch {
+		case w.httpPaths == 0:
+		case w.tlsPaths == w.httpPaths:
+			tls = "all"
+		case w.tlsPaths == 0:
+			tls = "none"
+		default:
+			tls = "partial"
+		}
+
+		risky := w.open || tls == "none" || tls == "partial"
+		severity := scanner.SeverityLow
+		switch {
+		case w.protection != protectionRestricted && risky:
+			severity = scanner.SeverityHigh
+		case w.protection != protectionRestricted || risky:
+			severity = scanner.SeverityMedium
+		}
+
+		pods := make([]string, 0, len(w.pods))
+		for name := range w.pods {
+			pods = append(pods, name)
+		}
+		sort.Strings(pods)
+
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-020",
+			Title:       "Internet-exposed workload",
+			Description: fmt.Sprintf("%s is reachable from outside the cluster via %s (NetworkPolicy ingress protection: %s, TLS: %s)", ref, strings.Join(w.via, "; "), w.protection, tls),
+			Severity:    severity,
+			Status:      scanner.StatusWarning,
+			Category:    "network",
+			Resource:    ref,
+			Namespace:   w.namespace,
+			Remediation: "Confirm the exposure is intended. Restrict load balancers with loadBalancerSourceRanges, terminate TLS on every HTTP route, and apply an ingress NetworkPolicy that only admits the ingress controller or load balancer.",
+			Details: map[string]string{
+				scanner.DetailExposedVia:    strings.Join(w.via, "; "),
+				scanner.DetailNetworkPolicy: w.protection,
+				scanner.DetailTLS:           tls,
+				"pods":                      strings.Join(pods, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
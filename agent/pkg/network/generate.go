@@ -0,0 +1,557 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// generatedFromAnnotation records why a generated policy exists.
+const generatedFromAnnotation = "compliance.kubecomply.io/generated-from"
+
+// volatileLabels change between pod instances and are dropped when a pod's
+// labels are turned into a selector.
+var volatileLabels = map[string]bool{
+	"pod-template-hash":                        true,
+	"controller-revision-hash":                 true,
+	"pod-template-generation":                  true,
+	"statefulset.kubernetes.io/pod-name":       true,
+	"apps.kubernetes.io/pod-index":             true,
+	"controller-uid":                           true,
+	"batch.kubernetes.io/controller-uid":       true,
+	"batch.kubernetes.io/job-completion-index": true,
+}
+
+// FlowEndpoint is one side of an observed connection. Pods are identified by
+// namespace and name, by labels, or by IP; an IP that belongs to no known pod
+// is treated as an external address.
+type FlowEndpoint struct {
+	Namespace string            `json:"namespace,omitempty"`
+	Pod       string            `json:"pod,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+}
+
+// Flow is an observed connection from Source to Destination.
+type Flow struct {
+	Source      FlowEndpoint    `json:"source"`
+	Destination FlowEndpoint    `json:"destination"`
+	Port        int32           `json:"port"`
+	Protocol    corev1.Protocol `json:"protocol,omitempty"`
+}
+
+// ParseFlowLog reads observed connections from either a JSON array of flows
+// or a stream of JSON objects (one per line).
+func ParseFlowLog(r io.Reader) ([]Flow, error) {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading flow log: %w", err)
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		var flows []Flow
+		if err := dec.Decode(&flows); err != nil {
+			return nil, fmt.Errorf("decoding flow log: %w", err)
+		}
+		return flows, nil
+	}
+
+	var flows []Flow
+	for {
+		var f Flow
+		if err := dec.Decode(&f); err != nil {
+			if errors.Is(err, io.EOF) {
+				return flows, nil
+			}
+			return nil, fmt.Errorf("decoding flow %d: %w", len(flows)+1, err)
+		}
+		flows = append(flows, f)
+	}
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			if _, err := br.ReadByte(); err != nil {
+				return 0, err
+			}
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// GeneratedPolicies is a set of NetworkPolicies that puts a namespace under
+// default-deny while keeping its known traffic flowing.
+type GeneratedPolicies struct {
+	Namespace string
+	Policies  []networkingv1.NetworkPolicy
+	// Skipped lists flows that could not be turned into rules, with the reason.
+	Skipped []string
+}
+
+// Generate builds a default-deny policy for the namespace plus allow rules
+// inferred from its Services and from observed flows. Services without
+// matching flows allow their target ports from every namespace (or from
+// anywhere for NodePort and LoadBalancer Services), so adopting the
+// generated policies does not break traffic that is already known.
+func (a *Analyzer) Generate(ctx context.Context, namespace string, flows []Flow) (*GeneratedPolicies, error) {
+	scope := map[string]bool{namespace: true}
+	for _, f := range flows {
+		for _, ep := range []FlowEndpoint{f.Source, f.Destination} {
+			if ep.Namespace != "" {
+				scope[ep.Namespace] = true
+			}
+		}
+	}
+	namespaces := make([]string, 0, len(scope))
+	for ns := range scope {
+		namespaces = append(namespaces, ns)
+	}
+
+	engine, err := a.Reachability(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := a.client.ListServices(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("listing services: %w", err)
+	}
+
+	g := &generator{namespace: namespace, engine: engine}
+	g.addDefaultDeny()
+	g.addDNSEgress()
+	g.addFlows(flows)
+	g.addServices(services)
+
+	a.logger.Info("network policy generation complete",
+		"namespace", namespace,
+		"policies", len(g.result.Policies),
+		"flows", len(flows),
+		"skipped", len(g.result.Skipped),
+	)
+	return &g.result, nil
+}
+
+// generator accumulates policies for a single namespace.
+type generator struct {
+	namespace string
+	engine    *Engine
+	result    GeneratedPolicies
+
+	// ingress and egress rules keyed by target workload, plus the
+	// selector and origin of each target.
+	ingress   map[string][]networkingv1.NetworkPolicyIngressRule
+	egress    map[string][]networkingv1.NetworkPolicyEgressRule
+	selectors map[string]map[string]string
+	origins   map[string]string
+	order     []string
+}
+
+func (g *generator) newPolicy(name, origin string, selector map[string]string) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: g.namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "kubecomply",
+			},
+			Annotations: map[string]string{
+				generatedFromAnnotation: origin,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: selector},
+		},
+	}
+}
+
+func (g *generator) addDefaultDeny() {
+	p := g.newPolicy("default-deny", "default-deny", nil)
+	p.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	g.result.Namespace = g.namespace
+	g.result.Policies = append(g.result.Policies, p)
+}
+
+// addDNSEgress allows every pod to resolve names through cluster DNS, which
+// would otherwise be the first casualty of default-deny egress.
+func (g *generator) addDNSEgress() {
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	dns := intstr.FromInt32(53)
+
+	p := g.newPolicy("allow-dns-egress", "cluster DNS", nil)
+	p.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	p.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{
+		To: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "kube-system"}},
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+		}},
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &udp, Port: &dns},
+			{Protocol: &tcp, Port: &dns},
+		},
+	}}
+	g.result.Policies = append(g.result.Policies, p)
+}
+
+// resolvedEndpoint is a flow endpoint mapped onto the cluster.
+type resolvedEndpoint struct {
+	namespace string
+	labels    map[string]string
+	workload  string
+	cidr      string
+}
+
+// resolve maps a flow endpoint to a pod (by name, IP or labels) or to an
+// external address.
+func (g *generator) resolve(ep FlowEndpoint) (resolvedEndpoint, error) {
+	if ep.Pod != "" {
+		if ep.Namespace == "" {
+			return resolvedEndpoint{}, fmt.Errorf("pod %q has no namespace", ep.Pod)
+		}
+		pod := g.engine.FindPod(ep.Namespace, ep.Pod)
+		if pod == nil && len(ep.Labels) == 0 {
+			return resolvedEndpoint{}, fmt.Errorf("pod %s/%s not found", ep.Namespace, ep.Pod)
+		}
+		if pod != nil {
+			return resolvedEndpoint{namespace: pod.Namespace, labels: stableLabels(pod.Labels), workload: workloadName(pod)}, nil
+		}
+	}
+
+	if len(ep.Labels) > 0 {
+		if ep.Namespace == "" {
+			return resolvedEndpoint{}, fmt.Errorf("labels %v have no namespace", ep.Labels)
+		}
+		workload := ep.Pod
+		if workload == "" {
+			workload = labels.Set(stableLabels(ep.Labels)).String()
+		}
+		return resolvedEndpoint{namespace: ep.Namespace, labels: stableLabels(ep.Labels), workload: workload}, nil
+	}
+
+	ip := net.ParseIP(ep.IP)
+	if ip == nil {
+		return resolvedEndpoint{}, fmt.Errorf("endpoint has no pod, labels or valid IP")
+	}
+	pods := g.engine.Pods()
+	for i := range pods {
+		if pods[i].Status.PodIP == ep.IP {
+			return resolvedEndpoint{namespace: pods[i].Namespace, labels: stableLabels(pods[i].Labels), workload: workloadName(&pods[i])}, nil
+		}
+	}
+	if ip.To4() != nil {
+		return resolvedEndpoint{cidr: ip.String() + "/32"}, nil
+	}
+	return resolvedEndpoint{cidr: ip.String() + "/128"}, nil
+}
+
+// peer converts a resolved endpoint into a NetworkPolicyPeer as seen from
+// the generated namespace.
+func (g *generator) peer(ep resolvedEndpoint) networkingv1.NetworkPolicyPeer {
+	if ep.cidr != "" {
+		return networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: ep.cidr}}
+	}
+	p := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: ep.labels}}
+	if ep.namespace != g.namespace {
+		p.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: ep.namespace}}
+	}
+	return p
+}
+
+// addFlows turns observed connections touching the namespace into ingress
+// rules on the destination and egress rules on the source.
+func (g *generator) addFlows(flows []Flow) {
+	g.ingress = make(map[string][]networkingv1.NetworkPolicyIngressRule)
+	g.egress = make(map[string][]networkingv1.NetworkPolicyEgressRule)
+	g.selectors = make(map[string]map[string]string)
+	g.origins = make(map[string]string)
+
+	for i, f := range flows {
+		src, err := g.resolve(f.Source)
+		if err != nil {
+			g.result.Skipped = append(g.result.Skipped, fmt.Sprintf("flow %d: source: %v", i+1, err))
+			continue
+		}
+		dst, err := g.resolve(f.Destination)
+		if err != nil {
+			g.result.Skipped = append(g.result.Skipped, fmt.Sprintf("flow %d: destination: %v", i+1, err))
+			continue
+		}
+
+		if dst.namespace != g.namespace && src.namespace != g.namespace {
+			g.result.Skipped = append(g.result.Skipped, fmt.Sprintf("flow %d: neither endpoint is in namespace %s", i+1, g.namespace))
+			continue
+		}
+
+		ports := flowPorts(f)
+		if dst.namespace == g.namespace {
+			g.track(dst, "flow log")
+			g.ingress[dst.workload] = appendIngress(g.ingress[dst.workload], networkingv1.NetworkPolicyIngressRule{
+				From:  []networkingv1.NetworkPolicyPeer{g.peer(src)},
+				Ports: ports,
+			})
+		}
+		if src.namespace == g.namespace {
+			g.track(src, "flow log")
+			g.egress[src.workload] = appendEgress(g.egress[src.workload], networkingv1.NetworkPolicyEgressRule{
+				To:    []networkingv1.NetworkPolicyPeer{g.peer(dst)},
+				Ports: ports,
+			})
+		}
+	}
+}
+
+// track registers a workload the first time it is seen.
+func (g *generator) track(ep resolvedEndpoint, origin string) {
+	if _, ok := g.selectors[ep.workload]; ok {
+		return
+	}
+	g.selectors[ep.workload] = ep.labels
+	g.origins[ep.workload] = origin
+	g.order = append(g.order, ep.workload)
+}
+
+// addServices adds ingress for Services whose pods received no observed
+// traffic, then emits one policy per workload.
+func (g *generator) addServices(services []corev1.Service) {
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	for _, svc := range services {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+
+		covered := false
+		for _, workload := range g.order {
+			if len(g.ingress[workload]) > 0 && selectorSubset(svc.Spec.Selector, g.selectors[workload]) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		var ports []networkingv1.NetworkPolicyPort
+		for _, sp := range svc.Spec.Ports {
+			proto := sp.Protocol
+			if proto == "" {
+				proto = corev1.ProtocolTCP
+			}
+			target := sp.TargetPort
+			if target.Type == intstr.Int && target.IntVal == 0 {
+				target = intstr.FromInt32(sp.Port)
+			}
+			ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &proto, Port: &target})
+		}
+
+		rule := networkingv1.NetworkPolicyIngressRule{Ports: ports}
+		if svc.Spec.Type != corev1.ServiceTypeNodePort && svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			rule.From = []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}}
+		}
+
+		// Service keys contain a "/", which workload names cannot, so a
+		// Service never shares rules with a workload of the same name.
+		key := "svc/" + svc.Name
+		g.track(resolvedEndpoint{namespace: g.namespace, labels: svc.Spec.Selector, workload: key}, "Service/"+svc.Name)
+		g.ingress[key] = append(g.ingress[key], rule)
+	}
+
+	used := make(map[string]bool)
+	for _, workload := range g.order {
+		name := policyName(workload)
+		if used[name] {
+			name = hashedName(name, workload)
+		}
+		used[name] = true
+		if rules := g.ingress[workload]; len(rules) > 0 {
+			p := g.newPolicy("allow-ingress-"+name, g.origins[workload], g.selectors[workload])
+			p.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+			p.Spec.Ingress = rules
+			g.result.Policies = append(g.result.Policies, p)
+		}
+		if rules := g.egress[workload]; len(rules) > 0 {
+			p := g.newPolicy("allow-egress-"+name, g.origins[workload], g.selectors[workload])
+			p.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+			p.Spec.Egress = rules
+			g.result.Policies = append(g.result.Policies, p)
+		}
+	}
+}
+
+// flowPorts returns the port list for a flow; port 0 allows every port.
+func flowPorts(f Flow) []networkingv1.NetworkPolicyPort {
+	if f.Port == 0 {
+		return nil
+	}
+	proto := f.Protocol
+	if proto == "" {
+		proto = corev1.ProtocolTCP
+	}
+	port := intstr.FromInt32(f.Port)
+	return []networkingv1.NetworkPolicyPort{{Protocol: &proto, Port: &port}}
+}
+
+// appendIngress merges a rule into existing rules that share its peers, so
+// repeated flows collapse into one rule with several ports.
+func appendIngress(rules []networkingv1.NetworkPolicyIngressRule, r networkingv1.NetworkPolicyIngressRule) []networkingv1.NetworkPolicyIngressRule {
+	for i := range rules {
+		if samePeers(rules[i].From, r.From) {
+			rules[i].Ports = mergePorts(rules[i].Ports, r.Ports)
+			return rules
+		}
+	}
+	return append(rules, r)
+}
+
+// appendEgress is the egress counterpart of appendIngress.
+func appendEgress(rules []networkingv1.NetworkPolicyEgressRule, r networkingv1.NetworkPolicyEgressRule) []networkingv1.NetworkPolicyEgressRule {
+	for i := range rules {
+		if samePeers(rules[i].To, r.To) {
+			rules[i].Ports = mergePorts(rules[i].Ports, r.Ports)
+			return rules
+		}
+	}
+	return append(rules, r)
+}
+
+func samePeers(a, b []networkingv1.NetworkPolicyPeer) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+// mergePorts unions two port lists. An empty list means all ports and wins.
+func mergePorts(a, b []networkingv1.NetworkPolicyPort) []networkingv1.NetworkPolicyPort {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	result := append([]networkingv1.NetworkPolicyPort(nil), a...)
+	for _, pb := range b {
+		dup := false
+		for _, pa := range a {
+			if protocolOf(pa) == protocolOf(pb) && pa.Port.String() == pb.Port.String() {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			result = append(result, pb)
+		}
+	}
+	return result
+}
+
+// stableLabels drops labels that differ between replicas of a workload.
+func stableLabels(set map[string]string) map[string]string {
+	result := make(map[string]string, len(set))
+	for k, v := range set {
+		if !volatileLabels[k] {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// selectorSubset reports whether every key/value in selector is present in set.
+func selectorSubset(selector, set map[string]string) bool {
+	for k, v := range selector {
+		if set[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// workloadName returns the name of the controller owning a pod, or the pod
+// name for unowned pods.
+func workloadName(pod *corev1.Pod) string {
+	ref := workloadRef(pod)
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// invalidPolicyNameChars matches characters not allowed in object names.
+var invalidPolicyNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// maxPolicyNameSuffix bounds the workload part of generated policy names.
+const maxPolicyNameSuffix = 48
+
+// policyName derives a DNS-compatible suffix from a workload name. Longer
+// names are shortened with a hash of the workload, so that workloads sharing
+// a long prefix still get distinct policies.
+func policyName(workload string) string {
+	name := strings.Trim(invalidPolicyNameChars.ReplaceAllString(strings.ToLower(workload), "-"), "-")
+	if name == "" {
+		name = "workload"
+	}
+	if len(name) > maxPolicyNameSuffix {
+		name = hashedName(name, workload)
+	}
+	return name
+}
+
+// hashedName shortens name as needed and appends the first eight hex
+// digits of the SHA-256 of key.
+func hashedName(name, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	suffix := hex.EncodeToString(sum[:4])
+	if limit := maxPolicyNameSuffix - len(suffix) - 1; len(name) > limit {
+		name = name[:limit]
+	}
+	return strings.TrimRight(name, "-") + "-" + suffix
+}
+
+// WriteYAML writes the generated policies as a multi-document YAML manifest.
+func (gp *GeneratedPolicies) WriteYAML(w io.Writer) error {
+	serializer := k8sjson.NewSerializerWithOptions(k8sjson.DefaultMetaFactory, nil, nil, k8sjson.SerializerOptions{Yaml: true})
+
+	if _, err := fmt.Fprintf(w, "# NetworkPolicies generated by kubecomply for namespace %q.\n# Review before applying: the default-deny policy blocks all traffic not listed below.\n", gp.Namespace); err != nil {
+		return err
+	}
+	for _, skipped := range gp.Skipped {
+		if _, err := fmt.Fprintf(w, "# skipped %s\n", skipped); err != nil {
+			return err
+		}
+	}
+
+	for i := range gp.Policies {
+		if _, err := io.WriteString(w, "---\n"); err != nil {
+			return err
+		}
+		var obj runtime.Object = &gp.Policies[i]
+		if err := serializer.Encode(obj, w); err != nil {
+			return fmt.Errorf("encoding NetworkPolicy %s: %w", gp.Policies[i].Name, err)
+		}
+	}
+	return nil
+}
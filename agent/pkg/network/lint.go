@@ -0,0 +1,414 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// checkIneffectivePolicies lints NetworkPolicies for podSelectors that match
+// no current pod, policies fully shadowed by a broader policy, and
+// namespaceSelectors that match no namespace.
+func (a *Analyzer) checkIneffectivePolicies(
+	engine *Engine,
+	nsPolicies map[string][]networkingv1.NetworkPolicy,
+	now time.Time,
+) []scanner.Finding {
+	var findings []scanner.Finding
+	seen := make(map[string]bool)
+
+	for ns, policies := range nsPolicies {
+		for i := range policies {
+			p := &policies[i]
+			resource := policyResource(p)
+
+			// Cluster-wide policies span namespaces, so per-namespace
+			// selection and shadowing say nothing about them.
+			if isClusterWide(p) {
+				if !seen[resource] {
+					seen[resource] = true
+					findings = append(findings, unmatchedSelectorFindings(engine, p, now)...)
+				}
+				continue
+			}
+
+			if !isEmptySelector(&p.Spec.PodSelector) && engine.countSelected(p) == 0 {
+				findings = append(findings, scanner.Finding{
+					ID:          "NET-013",
+					Title:       "NetworkPolicy selects no pods",
+					Description: fmt.Sprintf("%s has podSelector %s, which matches none of the current pods in the namespace", describePolicy(p), metav1.FormatLabelSelector(&p.Spec.PodSelector)),
+					Severity:    scanner.SeverityLow,
+					Status:      scanner.StatusWarning,
+					Category:    "network",
+					Resource:    resource,
+					Namespace:   ns,
+					Remediation: "Fix the podSelector to match the workload's labels, or delete the policy if the workload no longer exists.",
+					Details: map[string]string{
+						"pod_selector": metav1.FormatLabelSelector(&p.Spec.PodSelector),
+					},
+					Timestamp: now,
+				})
+			}
+
+			for j := range policies {
+				q := &policies[j]
+				if i == j || isClusterWide(q) || !policyShadows(q, p) {
+					continue
+				}
+				// Identical policies shadow each other; report only one of them.
+				if policyShadows(p, q) && p.Name < q.Name {
+					continue
+				}
+				findings = append(findings, scanner.Finding{
+					ID:          "NET-014",
+					Title:       "NetworkPolicy shadowed by a broader policy",
+					Description: fmt.Sprintf("Every pod and rule of %s is already covered by the broader allow rules of %s; it has no effect", describePolicy(p), describePolicy(q)),
+					Severity:    scanner.SeverityLow,
+					Status:      scanner.StatusWarning,
+					Category:    "network",
+					Resource:    resource,
+					Namespace:   ns,
+					Remediation: "Remove the redundant policy, or narrow the broader policy if it allows more than intended.",
+					Details: map[string]string{
+						"shadowed_by": policyRef(q),
+					},
+					Timestamp: now,
+				})
+				break
+			}
+
+			findings = append(findings, unmatchedSelectorFindings(engine, p, now)...)
+		}
+	}
+
+	return findings
+}
+
+// unmatchedSelectorFindings reports the namespaceSelectors of a policy that
+// match no namespace.
+func unmatchedSelectorFindings(engine *Engine, p *networkingv1.NetworkPolicy, now time.Time) []scanner.Finding {
+	namespace := p.Namespace
+	if isClusterWide(p) {
+		namespace = ""
+	}
+
+	var findings []scanner.Finding
+	for _, ref := range engine.unmatchedNamespaceSelectors(p) {
+		findings = append(findings, scanner.Finding{
+			ID:          "NET-015",
+			Title:       "namespaceSelector matches no namespace",
+			Description: fmt.Sprintf("Rule %s uses namespaceSelector %s, but no namespace carries the referenced labels (%s)", ref.rule, ref.selector, strings.Join(ref.missingKeys, ", ")),
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusWarning,
+			Category:    "network",
+			Resource:    policyResource(p),
+			Namespace:   namespace,
+			Remediation: "Label the intended namespaces, or use the automatic kubernetes.io/metadata.name label to select a namespace by name.",
+			Details: map[string]string{
+				"rule":               ref.rule,
+				"namespace_selector": ref.selector,
+				"missing_labels":     strings.Join(ref.missingKeys, ","),
+			},
+			Timestamp: now,
+		})
+	}
+	return findings
+}
+
+// countSelected returns the number of pods in the policy's namespace matched
+// by its podSelector.
+func (e *Engine) countSelected(p *networkingv1.NetworkPolicy) int {
+	count := 0
+	for i := range e.pods {
+		if e.pods[i].Namespace == p.Namespace && selectorMatches(&p.Spec.PodSelector, e.pods[i].Labels) {
+			count++
+		}
+	}
+	return count
+}
+
+// selectorRef describes a namespaceSelector that matches no namespace.
+type selectorRef struct {
+	rule        string
+	selector    string
+	missingKeys []string
+}
+
+// unmatchedNamespaceSelectors returns the namespaceSelectors of a policy's
+// rules that match none of the known namespaces.
+func (e *Engine) unmatchedNamespaceSelectors(p *networkingv1.NetworkPolicy) []selectorRef {
+	var result []selectorRef
+
+	check := func(rule string, peers []networkingv1.NetworkPolicyPeer) {
+		for _, peer := range peers {
+			if peer.NamespaceSelector == nil || isEmptySelector(peer.NamespaceSelector) {
+				continue
+			}
+			matched := false
+			for _, nsLabels := range e.namespaceLabels {
+				if selectorMatches(peer.NamespaceSelector, nsLabels) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			result = append(result, selectorRef{
+				rule:        rule,
+				selector:    metav1.FormatLabelSelector(peer.NamespaceSelector),
+				missingKeys: e.missingLabels(peer.NamespaceSelector),
+			})
+		}
+	}
+
+	for idx, rule := range p.Spec.Ingress {
+		check(ruleRef(p, networkingv1.PolicyTypeIngress, idx), rule.From)
+	}
+	for idx, rule := range p.Spec.Egress {
+		check(ruleRef(p, networkingv1.PolicyTypeEgress, idx), rule.To)
+	}
+	return result
+}
+
+// missingLabels returns the label keys (or key=value pairs) referenced by a
+// selector that no namespace carries.
+func (e *Engine) missingLabels(ls *metav1.LabelSelector) []string {
+	carried := func(key, value string) bool {
+		for _, nsLabels := range e.namespaceLabels {
+			if v, ok := nsLabels[key]; ok && (value == "" || v == value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var missing []string
+	for k, v := range ls.MatchLabels {
+		if !carried(k, v) {
+			missing = append(missing, k+"="+v)
+		}
+	}
+	for _, expr := range ls.MatchExpressions {
+		if expr.Operator != metav1.LabelSelectorOpIn && expr.Operator != metav1.LabelSelectorOpExists {
+			continue
+		}
+		if !carried(expr.Key, "") {
+			missing = append(missing, expr.Key)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) == 0 {
+		// Every key exists somewhere, but no namespace satisfies the
+		// combination; report the selector itself.
+		missing = []string{metav1.FormatLabelSelector(ls)}
+	}
+	return missing
+}
+
+// policyShadows reports whether outer makes inner redundant: outer selects at
+// least the pods inner selects, applies to every direction inner applies to,
+// and each of inner's rules is covered by one of outer's rules.
+func policyShadows(outer, inner *networkingv1.NetworkPolicy) bool {
+	if !selectorCovers(&outer.Spec.PodSelector, &inner.Spec.PodSelector) {
+		return false
+	}
+
+	for _, direction := range []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress} {
+		if !hasPolicyType(inner, direction) {
+			continue
+		}
+		if !hasPolicyType(outer, direction) {
+			return false
+		}
+
+		innerRules, outerRules := ingressRules(inner), ingressRules(outer)
+		if direction == networkingv1.PolicyTypeEgress {
+			innerRules, outerRules = egressRules(inner), egressRules(outer)
+		}
+		for _, innerRule := range innerRules {
+			covered := false
+			for _, outerRule := range outerRules {
+				if ruleCovers(outerRule, innerRule) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// policyRule is a direction-independent view of an ingress or egress rule.
+type policyRule struct {
+	peers []networkingv1.NetworkPolicyPeer
+	ports []networkingv1.NetworkPolicyPort
+}
+
+func ingressRules(p *networkingv1.NetworkPolicy) []policyRule {
+	rules := make([]policyRule, 0, len(p.Spec.Ingress))
+	for _, r := range p.Spec.Ingress {
+		rules = append(rules, policyRule{peers: r.From, ports: r.Ports})
+	}
+	return rules
+}
+
+func egressRules(p *networkingv1.NetworkPolicy) []policyRule {
+	rules := make([]policyRule, 0, len(p.Spec.Egress))
+	for _, r := range p.Spec.Egress {
+		rules = append(rules, policyRule{peers: r.To, ports: r.Ports})
+	}
+	return rules
+}
+
+// ruleCovers reports whether every connection allowed by inner is also
+// allowed by outer.
+func ruleCovers(outer, inner policyRule) bool {
+	if len(outer.peers) > 0 {
+		if len(inner.peers) == 0 {
+			return false
+		}
+		for _, ip := range inner.peers {
+			covered := false
+			for _, op := range outer.peers {
+				if peerCovers(op, ip) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+
+	if len(outer.ports) > 0 {
+		if len(inner.ports) == 0 {
+			return false
+		}
+		for _, ip := range inner.ports {
+			covered := false
+			for _, op := range outer.ports {
+				if portCovers(op, ip) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// peerCovers reports whether outer matches at least the endpoints inner matches.
+func peerCovers(outer, inner networkingv1.NetworkPolicyPeer) bool {
+	if outer.IPBlock != nil {
+		if inner.IPBlock == nil || len(outer.IPBlock.Except) > 0 || !cidrCovers(outer.IPBlock.CIDR, inner.IPBlock.CIDR) {
+			return false
+		}
+		if addressBlock(outer) != nil {
+			return true
+		}
+		// outer also requires selectors, so inner must be just as narrow.
+		if addressBlock(inner) != nil {
+			return false
+		}
+	} else if addressBlock(inner) != nil {
+		return false
+	}
+
+	switch {
+	case outer.NamespaceSelector == nil:
+		if inner.NamespaceSelector != nil {
+			return false
+		}
+	case !selectorCovers(outer.NamespaceSelector, inner.NamespaceSelector):
+		return false
+	}
+
+	if outer.PodSelector == nil {
+		return true
+	}
+	if inner.PodSelector == nil {
+		return isEmptySelector(outer.PodSelector)
+	}
+	return selectorCovers(outer.PodSelector, inner.PodSelector)
+}
+
+// portCovers reports whether outer allows at least the ports inner allows.
+func portCovers(outer, inner networkingv1.NetworkPolicyPort) bool {
+	if protocolOf(outer) != protocolOf(inner) {
+		return false
+	}
+	if outer.Port == nil {
+		return true
+	}
+	if inner.Port == nil {
+		return false
+	}
+	if outer.Port.StrVal != "" || inner.Port.StrVal != "" {
+		return outer.Port.String() == inner.Port.String()
+	}
+
+	innerEnd := inner.Port.IntVal
+	if inner.EndPort != nil {
+		innerEnd = *inner.EndPort
+	}
+	outerEnd := outer.Port.IntVal
+	if outer.EndPort != nil {
+		outerEnd = *outer.EndPort
+	}
+	return inner.Port.IntVal >= outer.Port.IntVal && innerEnd <= outerEnd
+}
+
+func protocolOf(p networkingv1.NetworkPolicyPort) string {
+	if p.Protocol == nil {
+		return "TCP"
+	}
+	return string(*p.Protocol)
+}
+
+// cidrCovers reports whether the outer CIDR contains the inner CIDR.
+func cidrCovers(outer, inner string) bool {
+	_, o, err := net.ParseCIDR(outer)
+	if err != nil {
+		return false
+	}
+	_, i, err := net.ParseCIDR(inner)
+	if err != nil {
+		return false
+	}
+	oOnes, oBits := o.Mask.Size()
+	iOnes, iBits := i.Mask.Size()
+	return oBits == iBits && oOnes <= iOnes && o.Contains(i.IP)
+}
+
+// selectorCovers reports whether outer matches every set of labels inner
+// matches. Only the empty selector and identical selectors are recognised.
+func selectorCovers(outer, inner *metav1.LabelSelector) bool {
+	if isEmptySelector(outer) {
+		return true
+	}
+	if inner == nil {
+		return false
+	}
+	return equality.Semantic.DeepEqual(outer, inner)
+}
+
+// isEmptySelector reports whether a selector matches everything.
+func isEmptySelector(ls *metav1.LabelSelector) bool {
+	return len(ls.MatchLabels) == 0 && len(ls.MatchExpressions) == 0
+}
@@ -0,0 +1,350 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Engine evaluates NetworkPolicies at pod granularity. It answers whether one
+// pod can reach another on a given port and which policies decided it.
+type Engine struct {
+	namespaceLabels map[string]map[string]string
+	pods            []corev1.Pod
+	policies        map[string][]networkingv1.NetworkPolicy
+}
+
+// DirectionVerdict explains the outcome of one side (egress from the source
+// or ingress into the destination) of a connection.
+type DirectionVerdict struct {
+	// Isolated is true when at least one policy selects the pod for this direction.
+	Isolated bool `json:"isolated"`
+
+	// Allowed is true when traffic passes this side of the connection.
+	Allowed bool `json:"allowed"`
+
+	// SelectingPolicies are the policies that isolate the pod for this direction.
+	SelectingPolicies []string `json:"selectingPolicies,omitempty"`
+
+	// AllowingRules identify the policy rules that admit the traffic.
+	AllowingRules []string `json:"allowingRules,omitempty"`
+}
+
+// Verdict is the result of a reachability query between two pods.
+type Verdict struct {
+	Source      string           `json:"source"`
+	Destination string           `json:"destination"`
+	Port        int32            `json:"port,omitempty"`
+	Protocol    corev1.Protocol  `json:"protocol"`
+	Allowed     bool             `json:"allowed"`
+	Egress      DirectionVerdict `json:"egress"`
+	Ingress     DirectionVerdict `json:"ingress"`
+}
+
+// NewEngine creates a reachability engine from namespace, pod and
+// NetworkPolicy listings.
+func NewEngine(namespaces []corev1.Namespace, pods []corev1.Pod, policies []networkingv1.NetworkPolicy) *Engine {
+	e := &Engine{
+		namespaceLabels: make(map[string]map[string]string, len(namespaces)),
+		pods:            pods,
+		policies:        make(map[string][]networkingv1.NetworkPolicy),
+	}
+	for i := range namespaces {
+		e.namespaceLabels[namespaces[i].Name] = namespaceLabelSet(&namespaces[i])
+	}
+	for _, p := range policies {
+		e.policies[p.Namespace] = append(e.policies[p.Namespace], p)
+	}
+	return e
+}
+
+// namespaceLabelSet returns the labels of a namespace including the
+// kubernetes.io/metadata.name label, which older clusters do not set.
+func namespaceLabelSet(ns *corev1.Namespace) map[string]string {
+	set := make(map[string]string, len(ns.Labels)+1)
+	for k, v := range ns.Labels {
+		set[k] = v
+	}
+	set[corev1.LabelMetadataName] = ns.Name
+	return set
+}
+
+// Pods returns the pods known to the engine.
+func (e *Engine) Pods() []corev1.Pod {
+	return e.pods
+}
+
+// FindPod returns the pod with the given namespace and name, or nil.
+func (e *Engine) FindPod(namespace, name string) *corev1.Pod {
+	for i := range e.pods {
+		if e.pods[i].Namespace == namespace && e.pods[i].Name == name {
+			return &e.pods[i]
+		}
+	}
+	return nil
+}
+
+// PoliciesSelecting returns the policies in the pod's namespace whose
+// podSelector matches the pod, regardless of policy type.
+func (e *Engine) PoliciesSelecting(pod *corev1.Pod) []networkingv1.NetworkPolicy {
+	var result []networkingv1.NetworkPolicy
+	for _, p := range e.policies[pod.Namespace] {
+		if selectorMatches(&p.Spec.PodSelector, pod.Labels) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// CanReach evaluates whether src may open a connection to dst on the given
+// port and protocol. A zero port means "any port".
+func (e *Engine) CanReach(src, dst *corev1.Pod, port int32, protocol corev1.Protocol) Verdict {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+
+	v := Verdict{
+		Source:      src.Namespace + "/" + src.Name,
+		Destination: dst.Namespace + "/" + dst.Name,
+		Port:        port,
+		Protocol:    protocol,
+	}
+
+	v.Egress = e.evaluate(src, dst, dst, port, protocol, networkingv1.PolicyTypeEgress)
+	v.Ingress = e.evaluate(dst, src, dst, port, protocol, networkingv1.PolicyTypeIngress)
+	v.Allowed = v.Egress.Allowed && v.Ingress.Allowed
+
+	return v
+}
+
+// evaluate applies the policies selecting subject for one direction. peer is
+// the other end of the connection; target is the destination pod, used to
+// resolve named ports.
+func (e *Engine) evaluate(subject, peer, target *corev1.Pod, port int32, protocol corev1.Protocol, direction networkingv1.PolicyType) DirectionVerdict {
+	var dv DirectionVerdict
+
+	for _, p := range e.policies[subject.Namespace] {
+		if !selectorMatches(&p.Spec.PodSelector, subject.Labels) || !hasPolicyType(&p, direction) {
+			continue
+		}
+		dv.Isolated = true
+		dv.SelectingPolicies = append(dv.SelectingPolicies, policyRef(&p))
+
+		if direction == networkingv1.PolicyTypeIngress {
+			for i, rule := range p.Spec.Ingress {
+				if e.peersMatch(rule.From, p.Namespace, peer) && portsMatch(rule.Ports, target, port, protocol) {
+					dv.AllowingRules = append(dv.AllowingRules, ruleRef(&p, networkingv1.PolicyTypeIngress, i))
+				}
+			}
+		} else {
+			for i, rule := range p.Spec.Egress {
+				if e.peersMatch(rule.To, p.Namespace, peer) && portsMatch(rule.Ports, target, port, protocol) {
+					dv.AllowingRules = append(dv.AllowingRules, ruleRef(&p, networkingv1.PolicyTypeEgress, i))
+				}
+			}
+		}
+	}
+
+	dv.Allowed = !dv.Isolated || len(dv.AllowingRules) > 0
+	return dv
+}
+
+// peersMatch reports whether pod matches any of the peers of a rule defined in
+// policyNamespace. An empty peer list matches every pod.
+func (e *Engine) peersMatch(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, pod *corev1.Pod) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if e.peerMatches(peer, policyNamespace, pod) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerMatches evaluates a single NetworkPolicyPeer against a pod.
+func (e *Engine) peerMatches(peer networkingv1.NetworkPolicyPeer, policyNamespace string, pod *corev1.Pod) bool {
+	if peer.IPBlock != nil {
+		if !ipBlockMatchesPod(peer.IPBlock, pod) {
+			return false
+		}
+		if addressBlock(peer) != nil {
+			return true
+		}
+	}
+
+	if peer.NamespaceSelector != nil {
+		if !selectorMatches(peer.NamespaceSelector, e.namespaceLabels[pod.Namespace]) {
+			return false
+		}
+	} else if pod.Namespace != policyNamespace {
+		return false
+	}
+
+	if peer.PodSelector != nil {
+		return selectorMatches(peer.PodSelector, pod.Labels)
+	}
+	return true
+}
+
+// addressBlock returns the ipBlock of a peer that matches by address alone,
+// or nil. Peers translated from Calico rules that set both a selector and
+// nets carry an ipBlock together with selectors and match only the selected
+// endpoints within it, never external addresses.
+func addressBlock(peer networkingv1.NetworkPolicyPeer) *networkingv1.IPBlock {
+	if peer.PodSelector != nil || peer.NamespaceSelector != nil {
+		return nil
+	}
+	return peer.IPBlock
+}
+
+// ipBlockMatchesPod reports whether any of the pod's IPs falls inside the
+// block's CIDR and outside its exceptions.
+func ipBlockMatchesPod(block *networkingv1.IPBlock, pod *corev1.Pod) bool {
+	ips := []string{pod.Status.PodIP}
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
+	}
+	for _, raw := range ips {
+		if ip := net.ParseIP(raw); ip != nil && ipBlockContains(block, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipBlockContains reports whether ip is inside block.CIDR and not excepted.
+func ipBlockContains(block *networkingv1.IPBlock, ip net.IP) bool {
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(ip) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, ex, err := net.ParseCIDR(except); err == nil && ex.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// portsMatch reports whether the port and protocol are allowed by a rule's
+// port list. Named ports are resolved against the destination pod. A zero
+// port only matches rules that allow every port.
+func portsMatch(ports []networkingv1.NetworkPolicyPort, target *corev1.Pod, port int32, protocol corev1.Protocol) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		proto := corev1.ProtocolTCP
+		if p.Protocol != nil {
+			proto = *p.Protocol
+		}
+		if proto != protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true
+		}
+		if port == 0 {
+			continue
+		}
+
+		want := p.Port.IntVal
+		if p.Port.StrVal != "" {
+			want = resolveNamedPort(target, p.Port.StrVal, proto)
+			if want == 0 {
+				continue
+			}
+		}
+		end := want
+		if p.EndPort != nil && *p.EndPort > want {
+			end = *p.EndPort
+		}
+		if port >= want && port <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveNamedPort returns the container port number for a named port, or 0.
+func resolveNamedPort(pod *corev1.Pod, name string, protocol corev1.Protocol) int32 {
+	for _, c := range pod.Spec.Containers {
+		for _, cp := range c.Ports {
+			proto := cp.Protocol
+			if proto == "" {
+				proto = corev1.ProtocolTCP
+			}
+			if cp.Name == name && proto == protocol {
+				return cp.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+// hasPolicyType reports whether a policy applies to the given direction,
+// applying the API defaults when policyTypes is omitted.
+func hasPolicyType(p *networkingv1.NetworkPolicy, direction networkingv1.PolicyType) bool {
+	if len(p.Spec.PolicyTypes) == 0 {
+		if direction == networkingv1.PolicyTypeIngress {
+			return true
+		}
+		return len(p.Spec.Egress) > 0
+	}
+	for _, pt := range p.Spec.PolicyTypes {
+		if pt == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorMatches evaluates a label selector. Invalid selectors match nothing.
+func selectorMatches(ls *metav1.LabelSelector, set map[string]string) bool {
+	selector, err := metav1.LabelSelectorAsSelector(ls)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(set))
+}
+
+// Explain renders the verdict as human-readable lines.
+func (v Verdict) Explain() []string {
+	port := "any port"
+	if v.Port != 0 {
+		port = fmt.Sprintf("port %d/%s", v.Port, v.Protocol)
+	}
+
+	result := "DENIED"
+	if v.Allowed {
+		result = "ALLOWED"
+	}
+
+	lines := []string{fmt.Sprintf("%s -> %s on %s: %s", v.Source, v.Destination, port, result)}
+	lines = append(lines, explainDirection("egress from "+v.Source, v.Egress)...)
+	lines = append(lines, explainDirection("ingress to "+v.Destination, v.Ingress)...)
+	return lines
+}
+
+func explainDirection(label string, dv DirectionVerdict) []string {
+	switch {
+	case !dv.Isolated:
+		return []string{fmt.Sprintf("  %s: allowed (no NetworkPolicy selects the pod for this direction)", label)}
+	case dv.Allowed:
+		rules := append([]string(nil), dv.AllowingRules...)
+		sort.Strings(rules)
+		return []string{fmt.Sprintf("  %s: allowed by %s", label, strings.Join(rules, ", "))}
+	default:
+		policies := append([]string(nil), dv.SelectingPolicies...)
+		sort.Strings(policies)
+		return []string{fmt.Sprintf("  %s: denied; selected by %s but no rule matches", label, strings.Join(policies, ", "))}
+	}
+}
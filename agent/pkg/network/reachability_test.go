@@ -0,0 +1,239 @@
+package network
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCanReach(t *testing.T) {
+	udp := corev1.ProtocolUDP
+	endPort := int32(8100)
+
+	pod := func(namespace, name, app, ip string, ports ...corev1.ContainerPort) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Ports: ports}}},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ops", Labels: map[string]string{"team": "ops"}}},
+	}
+	pods := []corev1.Pod{
+		pod("shop", "client", "client", "10.0.1.5"),
+		pod("shop", "web", "web", "10.0.1.6", corev1.ContainerPort{Name: "http", ContainerPort: 8080}),
+		pod("shop", "prometheus", "prometheus", "10.0.1.7"),
+		pod("ops", "prometheus", "prometheus", "10.0.2.7"),
+		pod("ops", "backup", "backup", "10.0.2.8"),
+	}
+	selector := func(app string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}
+	}
+	policy := func(app string, types []networkingv1.PolicyType, ingress []networkingv1.NetworkPolicyIngressRule, egress []networkingv1.NetworkPolicyEgressRule) networkingv1.NetworkPolicy {
+		return networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "p"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: *selector(app),
+				PolicyTypes: types,
+				Ingress:     ingress,
+				Egress:      egress,
+			},
+		}
+	}
+	fromPeers := func(peers ...networkingv1.NetworkPolicyPeer) []networkingv1.NetworkPolicyIngressRule {
+		return []networkingv1.NetworkPolicyIngressRule{{From: peers}}
+	}
+	onPorts := func(ports ...networkingv1.NetworkPolicyPort) []networkingv1.NetworkPolicyIngressRule {
+		return []networkingv1.NetworkPolicyIngressRule{{Ports: ports}}
+	}
+	port := func(p intstr.IntOrString) networkingv1.NetworkPolicyPort {
+		return networkingv1.NetworkPolicyPort{Port: &p}
+	}
+	opsPrometheus := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}},
+		PodSelector:       selector("prometheus"),
+	}
+
+	tests := []struct {
+		name     string
+		policies []networkingv1.NetworkPolicy
+		src, dst string
+		port     int32
+		protocol corev1.Protocol
+		want     bool
+	}{
+		{name: "no policies", src: "shop/client", dst: "shop/web", port: 8080, want: true},
+		{
+			name:     "ingress by default when policyTypes is omitted",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, nil, nil)},
+			src:      "shop/client", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name:     "no egress isolation without egress rules",
+			policies: []networkingv1.NetworkPolicy{policy("client", nil, nil, nil)},
+			src:      "shop/client", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name: "egress isolation when egress rules are present",
+			policies: []networkingv1.NetworkPolicy{policy("client", nil, nil, []networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{{PodSelector: selector("db")}}},
+			})},
+			src: "shop/client", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name:     "explicit egress type without rules denies all egress",
+			policies: []networkingv1.NetworkPolicy{policy("client", []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}, nil, nil)},
+			src:      "shop/client", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name:     "explicit egress type leaves ingress open",
+			policies: []networkingv1.NetworkPolicy{policy("web", []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}, nil, nil)},
+			src:      "shop/client", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name:     "podSelector peer in the policy namespace",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(networkingv1.NetworkPolicyPeer{PodSelector: selector("prometheus")}), nil)},
+			src:      "shop/prometheus", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name:     "podSelector peer excludes other namespaces",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(networkingv1.NetworkPolicyPeer{PodSelector: selector("prometheus")}), nil)},
+			src:      "ops/prometheus", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name:     "namespaceSelector and podSelector both match",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(opsPrometheus), nil)},
+			src:      "ops/prometheus", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name:     "namespaceSelector matches but podSelector does not",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(opsPrometheus), nil)},
+			src:      "ops/backup", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name:     "podSelector matches but namespaceSelector does not",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(opsPrometheus), nil)},
+			src:      "shop/prometheus", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name: "separate peers are alternatives",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(
+				networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}}},
+				networkingv1.NetworkPolicyPeer{PodSelector: selector("prometheus")},
+			), nil)},
+			src: "ops/backup", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name: "namespace matched by metadata.name",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(networkingv1.NetworkPolicyPeer{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "ops"}},
+			}), nil)},
+			src: "ops/backup", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name: "ipBlock contains the pod",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.2.0/24"}},
+			}), nil)},
+			src: "shop/client", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name: "ipBlock except excludes the pod",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, fromPeers(networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.2.0/24"}},
+			}), nil)},
+			src: "ops/backup", dst: "shop/web", port: 8080,
+			want: false,
+		},
+		{
+			name:     "named port",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(port(intstr.FromString("http"))), nil)},
+			src:      "shop/client", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name:     "named port resolves to another number",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(port(intstr.FromString("http"))), nil)},
+			src:      "shop/client", dst: "shop/web", port: 80,
+			want: false,
+		},
+		{
+			name:     "named port missing on the destination",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(port(intstr.FromString("metrics"))), nil)},
+			src:      "shop/client", dst: "shop/web", port: 9090,
+			want: false,
+		},
+		{
+			name: "endPort range",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(networkingv1.NetworkPolicyPort{
+				Port: func() *intstr.IntOrString { p := intstr.FromInt32(8000); return &p }(), EndPort: &endPort,
+			}), nil)},
+			src: "shop/client", dst: "shop/web", port: 8080,
+			want: true,
+		},
+		{
+			name: "outside endPort range",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(networkingv1.NetworkPolicyPort{
+				Port: func() *intstr.IntOrString { p := intstr.FromInt32(8000); return &p }(), EndPort: &endPort,
+			}), nil)},
+			src: "shop/client", dst: "shop/web", port: 8200,
+			want: false,
+		},
+		{
+			name:     "protocol defaults to TCP",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(port(intstr.FromInt32(8080))), nil)},
+			src:      "shop/client", dst: "shop/web", port: 8080, protocol: udp,
+			want: false,
+		},
+		{
+			name:     "any port against a port-restricted rule",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(port(intstr.FromInt32(8080))), nil)},
+			src:      "shop/client", dst: "shop/web",
+			want: false,
+		},
+		{
+			name:     "any port against a protocol-only rule",
+			policies: []networkingv1.NetworkPolicy{policy("web", nil, onPorts(networkingv1.NetworkPolicyPort{}), nil)},
+			src:      "shop/client", dst: "shop/web",
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(namespaces, pods, tt.policies)
+			find := func(ref string) *corev1.Pod {
+				for i := range pods {
+					if pods[i].Namespace+"/"+pods[i].Name == ref {
+						return e.FindPod(pods[i].Namespace, pods[i].Name)
+					}
+				}
+				t.Fatalf("unknown pod %s", ref)
+				return nil
+			}
+
+			v := e.CanReach(find(tt.src), find(tt.dst), tt.port, tt.protocol)
+			if v.Allowed != tt.want {
+				t.Errorf("CanReach() = %t, want %t: %v", v.Allowed, tt.want, v.Explain())
+			}
+		})
+	}
+}
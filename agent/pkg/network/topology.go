@@ -0,0 +1,247 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/graph"
+)
+
+// Node colours for NetworkPolicy coverage.
+const (
+	colorCovered   = "#bbf7d0" // isolated for ingress and egress
+	colorPartial   = "#fef08a" // isolated in one direction
+	colorUncovered = "#fecaca" // not selected by any policy
+)
+
+// Synthetic node IDs for traffic that is not pod-to-pod.
+const (
+	anySourceNodeID = "any"
+	internetNodeID  = "internet"
+)
+
+// Graph builds a namespace/workload topology of the given namespaces. Edges
+// are connections NetworkPolicies allow, labelled with the allowed ports;
+// workloads are grouped by namespace and coloured by policy coverage.
+// Workloads accepting traffic from every source get a single edge from an
+// "any source" node instead of an edge from every other workload.
+func (a *Analyzer) Graph(ctx context.Context, namespaces []string) (*graph.Graph, error) {
+	engine, err := a.Reachability(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return buildNetworkGraph(engine), nil
+}
+
+// topologyWorkload is a workload node with the pod used to evaluate it.
+type topologyWorkload struct {
+	id             string
+	pod            *corev1.Pod
+	ingressIsolate bool
+	egressIsolate  bool
+}
+
+// portProto is a destination port and protocol to probe. Port 0 means
+// "every port".
+type portProto struct {
+	port     int32
+	protocol corev1.Protocol
+}
+
+// buildNetworkGraph assembles the topology graph from a reachability engine.
+func buildNetworkGraph(engine *Engine) *graph.Graph {
+	g := graph.New("network")
+
+	workloads := topologyWorkloads(engine)
+	for _, w := range workloads {
+		coverage, color := "none", colorUncovered
+		switch {
+		case w.ingressIsolate && w.egressIsolate:
+			coverage, color = "full", colorCovered
+		case w.ingressIsolate || w.egressIsolate:
+			coverage, color = "partial", colorPartial
+		}
+		ref := workloadRef(w.pod)
+		kind, rest, _ := strings.Cut(ref, "/")
+		_, name, _ := strings.Cut(rest, "/")
+		g.AddNode(graph.Node{
+			ID:        w.id,
+			Label:     kind + "/" + name,
+			Kind:      "workload",
+			Group:     w.pod.Namespace,
+			Color:     color,
+			Highlight: coverage == "none",
+			Details: map[string]string{
+				"coverage":         coverage,
+				"ingress_isolated": fmt.Sprintf("%t", w.ingressIsolate),
+				"egress_isolated":  fmt.Sprintf("%t", w.egressIsolate),
+			},
+		})
+	}
+
+	for _, dst := range workloads {
+		if !dst.ingressIsolate {
+			g.AddNode(graph.Node{ID: anySourceNodeID, Label: "Any source", Kind: "external", Shape: "ellipse"})
+			g.AddEdge(anySourceNodeID, dst.id, "all traffic")
+		} else if engine.publicIngress(dst.pod) {
+			g.AddNode(graph.Node{ID: internetNodeID, Label: "Internet", Kind: "external", Shape: "ellipse"})
+			g.AddEdge(internetNodeID, dst.id, "ipBlock")
+		}
+
+		candidates := candidatePorts(engine, dst.pod)
+		for _, src := range workloads {
+			// Unrestricted pairs are covered by the "any source" edge.
+			if src == dst || (!dst.ingressIsolate && !src.egressIsolate) {
+				continue
+			}
+			if label := allowedPorts(engine, src.pod, dst.pod, candidates); label != "" {
+				g.AddEdge(src.id, dst.id, label)
+			}
+		}
+	}
+
+	for _, src := range workloads {
+		if len(engine.InternetEgress(src.pod)) > 0 {
+			g.AddNode(graph.Node{ID: internetNodeID, Label: "Internet", Kind: "external", Shape: "ellipse"})
+			g.AddEdge(src.id, internetNodeID, "egress")
+		}
+	}
+
+	return g
+}
+
+// topologyWorkloads groups the engine's running pods by owning workload,
+// skipping hostNetwork pods, which NetworkPolicies do not apply to. The first
+// pod by name represents the workload.
+func topologyWorkloads(engine *Engine) []*topologyWorkload {
+	pods := engine.Pods()
+	byRef := make(map[string]*topologyWorkload)
+	for i := range pods {
+		pod := &pods[i]
+		if isTerminated(pod) || pod.Spec.HostNetwork {
+			continue
+		}
+		id := "workload:" + workloadRef(pod)
+		if w, ok := byRef[id]; ok && w.pod.Name < pod.Name {
+			continue
+		}
+		byRef[id] = &topologyWorkload{
+			id:             id,
+			pod:            pod,
+			ingressIsolate: engine.isolated(pod, networkingv1.PolicyTypeIngress),
+			egressIsolate:  engine.isolated(pod, networkingv1.PolicyTypeEgress),
+		}
+	}
+
+	result := make([]*topologyWorkload, 0, len(byRef))
+	for _, w := range byRef {
+		result = append(result, w)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].id < result[j].id })
+	return result
+}
+
+// isolated reports whether any policy selects pod for the given direction.
+func (e *Engine) isolated(pod *corev1.Pod, direction networkingv1.PolicyType) bool {
+	for i := range e.policies[pod.Namespace] {
+		p := &e.policies[pod.Namespace][i]
+		if selectorMatches(&p.Spec.PodSelector, pod.Labels) && hasPolicyType(p, direction) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicIngress reports whether an ingress ipBlock rule applying to pod
+// includes public addresses.
+func (e *Engine) publicIngress(pod *corev1.Pod) bool {
+	for _, cidr := range e.ingressIPBlocks(pod) {
+		if includesPublic(cidr) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidatePorts returns the ports worth probing on dst: "every port", its
+// container ports and the ports named by ingress rules that apply to it.
+func candidatePorts(engine *Engine, dst *corev1.Pod) []portProto {
+	seen := make(map[portProto]bool)
+	var result []portProto
+	add := func(port int32, protocol corev1.Protocol) {
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		pp := portProto{port: port, protocol: protocol}
+		if !seen[pp] {
+			seen[pp] = true
+			result = append(result, pp)
+		}
+	}
+
+	add(0, corev1.ProtocolTCP)
+	for _, c := range dst.Spec.Containers {
+		for _, cp := range c.Ports {
+			add(cp.ContainerPort, cp.Protocol)
+		}
+	}
+	for _, p := range engine.policies[dst.Namespace] {
+		if !selectorMatches(&p.Spec.PodSelector, dst.Labels) || !hasPolicyType(&p, networkingv1.PolicyTypeIngress) {
+			continue
+		}
+		for _, rule := range p.Spec.Ingress {
+			for _, port := range rule.Ports {
+				if port.Port == nil {
+					continue
+				}
+				protocol := corev1.Protocol(protocolOf(port))
+				number := port.Port.IntVal
+				if port.Port.StrVal != "" {
+					number = resolveNamedPort(dst, port.Port.StrVal, protocol)
+				}
+				if number != 0 {
+					add(number, protocol)
+				}
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].protocol != result[j].protocol {
+			return result[i].protocol < result[j].protocol
+		}
+		return result[i].port < result[j].port
+	})
+	return result
+}
+
+// allowedPorts probes src -> dst on each candidate port with CanReach and
+// returns an edge label such as "TCP/80,443", "all ports", or "" when no
+// candidate is allowed.
+func allowedPorts(engine *Engine, src, dst *corev1.Pod, candidates []portProto) string {
+	byProtocol := make(map[corev1.Protocol][]string)
+	var protocols []corev1.Protocol
+	for _, c := range candidates {
+		if !engine.CanReach(src, dst, c.port, c.protocol).Allowed {
+			continue
+		}
+		if c.port == 0 {
+			return "all ports"
+		}
+		if _, ok := byProtocol[c.protocol]; !ok {
+			protocols = append(protocols, c.protocol)
+		}
+		byProtocol[c.protocol] = append(byProtocol[c.protocol], fmt.Sprintf("%d", c.port))
+	}
+
+	parts := make([]string, 0, len(protocols))
+	for _, proto := range protocols {
+		parts = append(parts, fmt.Sprintf("%s/%s", proto, strings.Join(byProtocol[proto], ",")))
+	}
+	return strings.Join(parts, " ")
+}
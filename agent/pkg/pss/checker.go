@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,6 +22,7 @@ import (
 type Profile string
 
 const (
+	ProfilePrivileged Profile = "privileged"
 	ProfileBaseline   Profile = "baseline"
 	ProfileRestricted Profile = "restricted"
 )
@@ -27,8 +30,24 @@ const (
 // Checker evaluates pods and workloads against Pod Security Standards.
 // It implements the scanner.Analyzer interface.
 type Checker struct {
-	client *k8s.Client
-	logger *slog.Logger
+	client          *k8s.Client
+	logger          *slog.Logger
+	templateSources []PodTemplateSource
+	// pinned is set when checks follow the standard as of v1.<minor>
+	// rather than the latest version.
+	pinned bool
+	minor  int
+}
+
+// Option configures a Checker instance.
+type Option func(*Checker)
+
+// WithPodTemplateSources adds custom resources whose embedded pod templates
+// are evaluated, in addition to DefaultPodTemplateSources.
+func WithPodTemplateSources(sources ...PodTemplateSource) Option {
+	return func(c *Checker) {
+		c.templateSources = append(c.templateSources, sources...)
+	}
 }
 
 // Name returns the analyzer name.
@@ -39,77 +58,209 @@ func (c *Checker) Analyze(ctx context.Context, namespaces []string) ([]scanner.F
 	return c.Check(ctx, namespaces)
 }
 
+// since reports whether a control introduced in v1.<minor> of the standard
+// applies to the version being evaluated.
+func (c *Checker) since(minor int) bool {
+	return !c.pinned || c.minor >= minor
+}
+
 // NewChecker creates a new PSS checker.
-func NewChecker(client *k8s.Client, logger *slog.Logger) *Checker {
+func NewChecker(client *k8s.Client, logger *slog.Logger, opts ...Option) *Checker {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Checker{
-		client: client,
-		logger: logger,
+	c := &Checker{
+		client:          client,
+		logger:          logger,
+		templateSources: append([]PodTemplateSource(nil), DefaultPodTemplateSources...),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // Check evaluates all pods and workloads in the given namespaces against
-// PSS Baseline and Restricted profiles.
+// PSS Baseline and Restricted profiles, and audits each namespace's Pod
+// Security Admission labels against the level its workloads satisfy.
 func (c *Checker) Check(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
 	c.logger.Info("starting Pod Security Standards check")
 
 	now := time.Now()
 	var findings []scanner.Finding
 
+	nsLabels := make(map[string]map[string]string)
+	allNamespaces, err := c.client.ListNamespaces(ctx)
+	if err != nil {
+		c.logger.Warn("failed to list namespaces; skipping PSA label audit", "error", err)
+	}
+	for _, ns := range allNamespaces {
+		nsLabels[ns.Name] = ns.Labels
+	}
+
 	for _, ns := range namespaces {
-		// Check pods directly.
-		pods, err := c.client.ListPods(ctx, ns)
-		if err != nil {
-			c.logger.Warn("failed to list pods", "namespace", ns, "error", err)
-			continue
+		nsFindings, complete := c.checkNamespace(ctx, ns, now)
+		findings = append(findings, nsFindings...)
+
+		// The achievable level is only meaningful when every workload was seen.
+		if labels, ok := nsLabels[ns]; ok && complete {
+			findings = append(findings, c.auditNamespaceLabels(ns, labels, nsFindings, now))
 		}
-		for i := range pods {
-			resource := fmt.Sprintf("Pod/%s/%s", pods[i].Namespace, pods[i].Name)
-			findings = append(findings, c.checkPodSpec(&pods[i].Spec, resource, pods[i].Namespace, now)...)
+	}
+
+	c.logger.Info("PSS check complete", "findings", len(findings))
+	return findings, nil
+}
+
+// workload is a pod or a controller pod template to evaluate.
+type workload struct {
+	kind      string
+	namespace string
+	name      string
+	spec      *corev1.PodSpec
+	// annotations are the pod's or template's annotations.
+	annotations map[string]string
+	// pod is set when the workload is a pod rather than a template.
+	pod *corev1.Pod
+	// controlled is set for templates of controllers that have a controller
+	// themselves.
+	controlled bool
+	// owner is the top-level controller of a pod or controlled template as
+	// "Kind/namespace/name", or "" for unowned pods and other templates.
+	owner string
+}
+
+// resource returns the "Kind/namespace/name" reference used in findings.
+func (w workload) resource() string {
+	return fmt.Sprintf("%s/%s/%s", w.kind, w.namespace, w.name)
+}
+
+// collectWorkloads lists the pods and controllers of a namespace, including
+// pod templates embedded in custom resources, and resolves each pod's
+// top-level controller. ReplicaSets and Jobs owned by another controller are
+// not evaluated themselves; they only link pods to that controller. Other
+// controlled templates are evaluated on behalf of their top-level controller.
+// On a listing error it returns what was collected so far together with the
+// error.
+func (c *Checker) collectWorkloads(ctx context.Context, ns string) ([]workload, error) {
+	var workloads []workload
+	owners := make(k8s.OwnerIndex)
+
+	// Owners are resolved once every intermediate controller is indexed, also
+	// on early return.
+	resolveOwners := func() {
+		for i := range workloads {
+			switch w := &workloads[i]; {
+			case w.pod != nil:
+				w.owner = owners.TopLevelOwner(w.pod)
+			case w.controlled:
+				w.owner = owners.TopLevel(w.resource(), w.namespace)
+			}
 		}
+	}
+	defer resolveOwners()
+
+	pods, err := c.client.ListPods(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing pods: %w", err)
+	}
+	for i := range pods {
+		workloads = append(workloads, workload{kind: "Pod", namespace: pods[i].Namespace, name: pods[i].Name, spec: &pods[i].Spec, annotations: pods[i].Annotations, pod: &pods[i]})
+	}
+
+	deployments, err := c.client.ListDeployments(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing deployments: %w", err)
+	}
+	for i := range deployments {
+		controlled := owners.Add("Deployment", &deployments[i])
+		workloads = append(workloads, workload{kind: "Deployment", namespace: deployments[i].Namespace, name: deployments[i].Name, spec: &deployments[i].Spec.Template.Spec, annotations: deployments[i].Spec.Template.Annotations, controlled: controlled})
+	}
+
+	daemonsets, err := c.client.ListDaemonSets(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing daemonsets: %w", err)
+	}
+	for i := range daemonsets {
+		controlled := owners.Add("DaemonSet", &daemonsets[i])
+		workloads = append(workloads, workload{kind: "DaemonSet", namespace: daemonsets[i].Namespace, name: daemonsets[i].Name, spec: &daemonsets[i].Spec.Template.Spec, annotations: daemonsets[i].Spec.Template.Annotations, controlled: controlled})
+	}
 
-		// Check deployments.
-		deployments, err := c.client.ListDeployments(ctx, ns)
-		if err != nil {
-			c.logger.Warn("failed to list deployments", "namespace", ns, "error", err)
+	statefulsets, err := c.client.ListStatefulSets(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing statefulsets: %w", err)
+	}
+	for i := range statefulsets {
+		controlled := owners.Add("StatefulSet", &statefulsets[i])
+		workloads = append(workloads, workload{kind: "StatefulSet", namespace: statefulsets[i].Namespace, name: statefulsets[i].Name, spec: &statefulsets[i].Spec.Template.Spec, annotations: statefulsets[i].Spec.Template.Annotations, controlled: controlled})
+	}
+
+	replicasets, err := c.client.ListReplicaSets(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing replicasets: %w", err)
+	}
+	for i := range replicasets {
+		rs := &replicasets[i]
+		if owners.Add("ReplicaSet", rs) {
 			continue
 		}
-		for i := range deployments {
-			resource := fmt.Sprintf("Deployment/%s/%s", deployments[i].Namespace, deployments[i].Name)
-			findings = append(findings, c.checkPodSpec(&deployments[i].Spec.Template.Spec, resource, deployments[i].Namespace, now)...)
-		}
+		workloads = append(workloads, workload{kind: "ReplicaSet", namespace: rs.Namespace, name: rs.Name, spec: &rs.Spec.Template.Spec, annotations: rs.Spec.Template.Annotations})
+	}
 
-		// Check daemonsets.
-		daemonsets, err := c.client.ListDaemonSets(ctx, ns)
-		if err != nil {
-			c.logger.Warn("failed to list daemonsets", "namespace", ns, "error", err)
+	jobs, err := c.client.ListJobs(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing jobs: %w", err)
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if owners.Add("Job", job) {
 			continue
 		}
-		for i := range daemonsets {
-			resource := fmt.Sprintf("DaemonSet/%s/%s", daemonsets[i].Namespace, daemonsets[i].Name)
-			findings = append(findings, c.checkPodSpec(&daemonsets[i].Spec.Template.Spec, resource, daemonsets[i].Namespace, now)...)
-		}
+		workloads = append(workloads, workload{kind: "Job", namespace: job.Namespace, name: job.Name, spec: &job.Spec.Template.Spec, annotations: job.Spec.Template.Annotations})
+	}
 
-		// Check statefulsets.
-		statefulsets, err := c.client.ListStatefulSets(ctx, ns)
-		if err != nil {
-			c.logger.Warn("failed to list statefulsets", "namespace", ns, "error", err)
+	cronjobs, err := c.client.ListCronJobs(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing cronjobs: %w", err)
+	}
+	for i := range cronjobs {
+		controlled := owners.Add("CronJob", &cronjobs[i])
+		tmpl := &cronjobs[i].Spec.JobTemplate.Spec.Template
+		workloads = append(workloads, workload{kind: "CronJob", namespace: cronjobs[i].Namespace, name: cronjobs[i].Name, spec: &tmpl.Spec, annotations: tmpl.Annotations, controlled: controlled})
+	}
+
+	rcs, err := c.client.ListReplicationControllers(ctx, ns)
+	if err != nil {
+		return workloads, fmt.Errorf("listing replicationcontrollers: %w", err)
+	}
+	for i := range rcs {
+		controlled := owners.Add("ReplicationController", &rcs[i])
+		if rcs[i].Spec.Template == nil {
 			continue
 		}
-		for i := range statefulsets {
-			resource := fmt.Sprintf("StatefulSet/%s/%s", statefulsets[i].Namespace, statefulsets[i].Name)
-			findings = append(findings, c.checkPodSpec(&statefulsets[i].Spec.Template.Spec, resource, statefulsets[i].Namespace, now)...)
-		}
+		workloads = append(workloads, workload{kind: "ReplicationController", namespace: rcs[i].Namespace, name: rcs[i].Name, spec: &rcs[i].Spec.Template.Spec, annotations: rcs[i].Spec.Template.Annotations, controlled: controlled})
 	}
 
-	c.logger.Info("PSS check complete", "findings", len(findings))
-	return findings, nil
+	workloads = append(workloads, c.customWorkloads(ctx, ns, owners)...)
+
+	return workloads, nil
 }
 
-// checkPodSpec evaluates a single PodSpec against PSS checks.
-func (c *Checker) checkPodSpec(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+// checkNamespace evaluates the pods and workloads of one namespace, reporting
+// each violation once against the owning controller. complete is false when a
+// listing failed and the remaining workloads were skipped.
+func (c *Checker) checkNamespace(ctx context.Context, ns string, now time.Time) (findings []scanner.Finding, complete bool) {
+	workloads, err := c.collectWorkloads(ctx, ns)
+	if err != nil {
+		c.logger.Warn("failed to list workloads", "namespace", ns, "error", err)
+	}
+	findings, _ = c.evaluateWorkloads(workloads, now)
+	return findings, err == nil
+}
+
+// checkPodSpec evaluates a single PodSpec and its pod annotations against PSS
+// checks.
+func (c *Checker) checkPodSpec(spec *corev1.PodSpec, annotations map[string]string, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
 	// PSS Baseline checks.
@@ -119,30 +270,46 @@ func (c *Checker) checkPodSpec(spec *corev1.PodSpec, resource, namespace string,
 	findings = append(findings, c.checkCapabilities(spec, resource, namespace, now)...)
 	findings = append(findings, c.checkVolumeTypes(spec, resource, namespace, now)...)
 	findings = append(findings, c.checkProcMount(spec, resource, namespace, now)...)
+	findings = append(findings, c.checkSysctls(spec, resource, namespace, now)...)
+	findings = append(findings, c.checkAppArmor(spec, annotations, resource, namespace, now)...)
+	findings = append(findings, c.checkSELinux(spec, resource, namespace, now)...)
+	findings = append(findings, c.checkHostProcess(spec, resource, namespace, now)...)
+	if c.since(19) {
+		findings = append(findings, c.checkSeccompUnconfined(spec, resource, namespace, now)...)
+	}
 
-	// PSS Restricted checks.
+	// PSS Restricted checks. Controls added after v1.0 of the standard are
+	// skipped when an older version is pinned.
 	findings = append(findings, c.checkRunAsNonRoot(spec, resource, namespace, now)...)
-	findings = append(findings, c.checkSeccompProfile(spec, resource, namespace, now)...)
-	findings = append(findings, c.checkDropAllCapabilities(spec, resource, namespace, now)...)
-	findings = append(findings, c.checkAllowPrivilegeEscalation(spec, resource, namespace, now)...)
+	if c.since(19) {
+		findings = append(findings, c.checkSeccompProfile(spec, resource, namespace, now)...)
+	}
+	if c.since(22) {
+		findings = append(findings, c.checkDropAllCapabilities(spec, resource, namespace, now)...)
+	}
+	if c.since(8) {
+		findings = append(findings, c.checkAllowPrivilegeEscalation(spec, resource, namespace, now)...)
+	}
 	findings = append(findings, c.checkReadOnlyRootFilesystem(spec, resource, namespace, now)...)
+	findings = append(findings, c.checkRestrictedVolumeTypes(spec, resource, namespace, now)...)
+	if c.since(23) {
+		findings = append(findings, c.checkRunAsRootUser(spec, resource, namespace, now)...)
+	}
+	if c.since(22) {
+		findings = append(findings, c.checkRestrictedCapabilities(spec, resource, namespace, now)...)
+	}
 
 	return findings
 }
 
-// allContainers returns all containers in a pod spec (init + regular + ephemeral).
-func allContainers(spec *corev1.PodSpec) []corev1.Container {
+// AllContainers returns all containers in a pod spec (init + regular + ephemeral).
+// Ephemeral containers are converted to plain containers with all their fields.
+func AllContainers(spec *corev1.PodSpec) []corev1.Container {
 	var containers []corev1.Container
 	containers = append(containers, spec.InitContainers...)
 	containers = append(containers, spec.Containers...)
 	for _, ec := range spec.EphemeralContainers {
-		containers = append(containers, corev1.Container{
-			Name:            ec.Name,
-			Image:           ec.Image,
-			SecurityContext: ec.SecurityContext,
-			Ports:           ec.Ports,
-			VolumeMounts:    ec.VolumeMounts,
-		})
+		containers = append(containers, corev1.Container(ec.EphemeralContainerCommon))
 	}
 	return containers
 }
@@ -153,7 +320,7 @@ func allContainers(spec *corev1.PodSpec) []corev1.Container {
 func (c *Checker) checkPrivileged(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
 			findings = append(findings, scanner.Finding{
 				ID:          "PSS-B001",
@@ -236,7 +403,7 @@ func (c *Checker) checkHostNamespaces(spec *corev1.PodSpec, resource, namespace
 func (c *Checker) checkHostPorts(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		for _, port := range container.Ports {
 			if port.HostPort != 0 {
 				findings = append(findings, scanner.Finding{
@@ -263,33 +430,33 @@ func (c *Checker) checkHostPorts(spec *corev1.PodSpec, resource, namespace strin
 	return findings
 }
 
+// baselineCapabilities are the capabilities Baseline allows adding.
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
 // checkCapabilities checks for dangerous added capabilities (Baseline).
 func (c *Checker) checkCapabilities(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	// Baseline disallows adding capabilities beyond a safe set.
-	safeCapabilities := map[corev1.Capability]bool{
-		"AUDIT_WRITE":      true,
-		"CHOWN":            true,
-		"DAC_OVERRIDE":     true,
-		"FOWNER":           true,
-		"FSETID":           true,
-		"KILL":             true,
-		"MKNOD":            true,
-		"NET_BIND_SERVICE": true,
-		"SETFCAP":          true,
-		"SETGID":           true,
-		"SETPCAP":          true,
-		"SETUID":           true,
-		"SYS_CHROOT":       true,
-	}
-
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
 			continue
 		}
 		for _, cap := range container.SecurityContext.Capabilities.Add {
-			if !safeCapabilities[cap] {
+			if !baselineCapabilities[cap] {
 				findings = append(findings, scanner.Finding{
 					ID:          "PSS-B006",
 					Title:       "Dangerous capability added",
@@ -347,7 +514,7 @@ func (c *Checker) checkVolumeTypes(spec *corev1.PodSpec, resource, namespace str
 func (c *Checker) checkProcMount(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		if container.SecurityContext != nil && container.SecurityContext.ProcMount != nil {
 			mount := *container.SecurityContext.ProcMount
 			if mount != corev1.DefaultProcMount {
@@ -375,9 +542,268 @@ func (c *Checker) checkProcMount(spec *corev1.PodSpec, resource, namespace strin
 	return findings
 }
 
+// safeSysctls are the sysctls the Baseline profile allows pods to set, with
+// the minor version of the standard that added each to the safe set.
+var safeSysctls = map[string]int{
+	"kernel.shm_rmid_forced":              0,
+	"net.ipv4.ip_local_port_range":        0,
+	"net.ipv4.ip_unprivileged_port_start": 0,
+	"net.ipv4.tcp_syncookies":             0,
+	"net.ipv4.ping_group_range":           0,
+	"net.ipv4.ip_local_reserved_ports":    27,
+	"net.ipv4.tcp_keepalive_time":         29,
+	"net.ipv4.tcp_fin_timeout":            29,
+	"net.ipv4.tcp_keepalive_intvl":        29,
+	"net.ipv4.tcp_keepalive_probes":       29,
+}
+
+// checkSysctls checks for sysctls outside the Baseline safe set.
+func (c *Checker) checkSysctls(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	if spec.SecurityContext == nil {
+		return findings
+	}
+	for _, sysctl := range spec.SecurityContext.Sysctls {
+		if minor, ok := safeSysctls[sysctl.Name]; ok && c.since(minor) {
+			continue
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-B009",
+			Title:       "Unsafe sysctl",
+			Description: fmt.Sprintf("%s sets sysctl %s, which is not in the Baseline safe set", resource, sysctl.Name),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: fmt.Sprintf("Remove %s from spec.securityContext.sysctls. Unsafe sysctls can affect other pods or the node; tune them on the node instead.", sysctl.Name),
+			Details: map[string]string{
+				"sysctl":  sysctl.Name,
+				"profile": string(ProfileBaseline),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// appArmorAnnotationPrefix is the legacy per-container AppArmor annotation.
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// checkAppArmor verifies AppArmor profiles are not overridden to unconfined,
+// through either the appArmorProfile fields or the legacy annotations.
+func (c *Checker) checkAppArmor(spec *corev1.PodSpec, annotations map[string]string, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	report := func(container, value string) {
+		target := resource
+		if container != "" {
+			target = fmt.Sprintf("container %q in %s", container, resource)
+		}
+		details := map[string]string{
+			"apparmor": value,
+			"profile":  string(ProfileBaseline),
+		}
+		if container != "" {
+			details["container"] = container
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-B010",
+			Title:       "AppArmor profile overridden",
+			Description: fmt.Sprintf("AppArmor profile of %s is set to %q", target, value),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: "Use the RuntimeDefault or a Localhost AppArmor profile (securityContext.appArmorProfile), or remove the override.",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+	allowedType := func(p *corev1.AppArmorProfile) bool {
+		return p == nil || p.Type == corev1.AppArmorProfileTypeRuntimeDefault || p.Type == corev1.AppArmorProfileTypeLocalhost
+	}
+
+	if spec.SecurityContext != nil && !allowedType(spec.SecurityContext.AppArmorProfile) {
+		report("", string(spec.SecurityContext.AppArmorProfile.Type))
+	}
+	for _, container := range AllContainers(spec) {
+		if container.SecurityContext != nil && !allowedType(container.SecurityContext.AppArmorProfile) {
+			report(container.Name, string(container.SecurityContext.AppArmorProfile.Type))
+		}
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		container, ok := strings.CutPrefix(key, appArmorAnnotationPrefix)
+		if !ok {
+			continue
+		}
+		value := annotations[key]
+		if value == "" || value == "runtime/default" || strings.HasPrefix(value, "localhost/") {
+			continue
+		}
+		report(container, value)
+	}
+
+	return findings
+}
+
+// allowedSELinuxTypes are the SELinux types the Baseline profile allows.
+var allowedSELinuxTypes = map[string]bool{
+	"":                   true,
+	"container_t":        true,
+	"container_init_t":   true,
+	"container_kvm_t":    true,
+	"container_engine_t": true,
+}
+
+// checkSELinux verifies SELinux options don't set a custom user or role or a
+// type outside the container types.
+func (c *Checker) checkSELinux(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	check := func(opts *corev1.SELinuxOptions, container string) {
+		if opts == nil {
+			return
+		}
+		var problems []string
+		if !allowedSELinuxTypes[opts.Type] {
+			problems = append(problems, fmt.Sprintf("type %q", opts.Type))
+		}
+		if opts.User != "" {
+			problems = append(problems, fmt.Sprintf("user %q", opts.User))
+		}
+		if opts.Role != "" {
+			problems = append(problems, fmt.Sprintf("role %q", opts.Role))
+		}
+		if len(problems) == 0 {
+			return
+		}
+
+		target := resource
+		details := map[string]string{"profile": string(ProfileBaseline)}
+		if container != "" {
+			target = fmt.Sprintf("container %q in %s", container, resource)
+			details["container"] = container
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-B011",
+			Title:       "Custom SELinux options",
+			Description: fmt.Sprintf("SELinux options of %s set %s", target, strings.Join(problems, ", ")),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: "Remove seLinuxOptions.user and seLinuxOptions.role, and limit seLinuxOptions.type to container_t, container_init_t, container_kvm_t or container_engine_t.",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	if spec.SecurityContext != nil {
+		check(spec.SecurityContext.SELinuxOptions, "")
+	}
+	for _, container := range AllContainers(spec) {
+		if container.SecurityContext != nil {
+			check(container.SecurityContext.SELinuxOptions, container.Name)
+		}
+	}
+
+	return findings
+}
+
+// checkHostProcess verifies no Windows HostProcess containers are used.
+func (c *Checker) checkHostProcess(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	isHostProcess := func(opts *corev1.WindowsSecurityContextOptions) bool {
+		return opts != nil && opts.HostProcess != nil && *opts.HostProcess
+	}
+
+	podHostProcess := spec.SecurityContext != nil && isHostProcess(spec.SecurityContext.WindowsOptions)
+	for _, container := range AllContainers(spec) {
+		containerHostProcess := container.SecurityContext != nil && isHostProcess(container.SecurityContext.WindowsOptions)
+		if !podHostProcess && !containerHostProcess {
+			continue
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-B012",
+			Title:       "Windows HostProcess container",
+			Description: fmt.Sprintf("Container %q in %s runs as a Windows HostProcess container with host-level access", container.Name, resource),
+			Severity:    scanner.SeverityCritical,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: "Remove windowsOptions.hostProcess. HostProcess containers are equivalent to privileged Linux containers.",
+			Details: map[string]string{
+				"container": container.Name,
+				"profile":   string(ProfileBaseline),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// checkSeccompUnconfined verifies no pod or container explicitly disables
+// seccomp.
+func (c *Checker) checkSeccompUnconfined(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	unconfined := func(p *corev1.SeccompProfile) bool {
+		return p != nil && p.Type == corev1.SeccompProfileTypeUnconfined
+	}
+	report := func(container string) {
+		target := resource
+		details := map[string]string{"profile": string(ProfileBaseline)}
+		if container != "" {
+			target = fmt.Sprintf("container %q in %s", container, resource)
+			details["container"] = container
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-B013",
+			Title:       "Seccomp explicitly unconfined",
+			Description: fmt.Sprintf("%s sets seccompProfile.type to Unconfined", target),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: "Set securityContext.seccompProfile.type to RuntimeDefault or Localhost.",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	if spec.SecurityContext != nil && unconfined(spec.SecurityContext.SeccompProfile) {
+		report("")
+	}
+	for _, container := range AllContainers(spec) {
+		if container.SecurityContext != nil && unconfined(container.SecurityContext.SeccompProfile) {
+			report(container.Name)
+		}
+	}
+
+	return findings
+}
+
 // --- Restricted Checks ---
 
-// checkRunAsNonRoot verifies pods/containers run as non-root.
+// checkRunAsNonRoot verifies pods/containers run as non-root. As in Pod
+// Security Admission, runAsNonRoot must be true at the pod level or on every
+// container, and a container must not override it to false; a non-zero
+// runAsUser alone does not satisfy the control.
 func (c *Checker) checkRunAsNonRoot(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
@@ -385,37 +811,38 @@ func (c *Checker) checkRunAsNonRoot(spec *corev1.PodSpec, resource, namespace st
 		spec.SecurityContext.RunAsNonRoot != nil &&
 		*spec.SecurityContext.RunAsNonRoot
 
-	for _, container := range allContainers(spec) {
-		containerNonRoot := container.SecurityContext != nil &&
-			container.SecurityContext.RunAsNonRoot != nil &&
-			*container.SecurityContext.RunAsNonRoot
-
-		containerHasUID := container.SecurityContext != nil &&
-			container.SecurityContext.RunAsUser != nil &&
-			*container.SecurityContext.RunAsUser > 0
-
-		podHasUID := spec.SecurityContext != nil &&
-			spec.SecurityContext.RunAsUser != nil &&
-			*spec.SecurityContext.RunAsUser > 0
+	for _, container := range AllContainers(spec) {
+		var containerNonRoot *bool
+		if container.SecurityContext != nil {
+			containerNonRoot = container.SecurityContext.RunAsNonRoot
+		}
 
-		if !podLevelNonRoot && !containerNonRoot && !containerHasUID && !podHasUID {
-			findings = append(findings, scanner.Finding{
-				ID:          "PSS-R001",
-				Title:       "Container may run as root",
-				Description: fmt.Sprintf("Container %q in %s does not set runAsNonRoot: true and does not specify a non-root runAsUser", container.Name, resource),
-				Severity:    scanner.SeverityHigh,
-				Status:      scanner.StatusFail,
-				Category:    "pss",
-				Resource:    resource,
-				Namespace:   namespace,
-				Remediation: "Set securityContext.runAsNonRoot: true or specify a non-root runAsUser at the pod or container level.",
-				Details: map[string]string{
-					"container": container.Name,
-					"profile":   string(ProfileRestricted),
-				},
-				Timestamp: now,
-			})
+		var description string
+		switch {
+		case containerNonRoot != nil && !*containerNonRoot:
+			description = fmt.Sprintf("Container %q in %s sets runAsNonRoot: false", container.Name, resource)
+		case containerNonRoot == nil && !podLevelNonRoot:
+			description = fmt.Sprintf("Container %q in %s does not set runAsNonRoot: true at the pod or container level", container.Name, resource)
+		default:
+			continue
 		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-R001",
+			Title:       "Container may run as root",
+			Description: description,
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: "Set securityContext.runAsNonRoot: true at the pod level (or on every container) and remove container-level overrides to false.",
+			Details: map[string]string{
+				"container": container.Name,
+				"profile":   string(ProfileRestricted),
+			},
+			Timestamp: now,
+		})
 	}
 
 	return findings
@@ -430,7 +857,7 @@ func (c *Checker) checkSeccompProfile(spec *corev1.PodSpec, resource, namespace
 		(spec.SecurityContext.SeccompProfile.Type == corev1.SeccompProfileTypeRuntimeDefault ||
 			spec.SecurityContext.SeccompProfile.Type == corev1.SeccompProfileTypeLocalhost)
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		containerHasSeccomp := container.SecurityContext != nil &&
 			container.SecurityContext.SeccompProfile != nil &&
 			(container.SecurityContext.SeccompProfile.Type == corev1.SeccompProfileTypeRuntimeDefault ||
@@ -463,7 +890,7 @@ func (c *Checker) checkSeccompProfile(spec *corev1.PodSpec, resource, namespace
 func (c *Checker) checkDropAllCapabilities(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		dropsAll := false
 		if container.SecurityContext != nil && container.SecurityContext.Capabilities != nil {
 			for _, cap := range container.SecurityContext.Capabilities.Drop {
@@ -501,7 +928,7 @@ func (c *Checker) checkDropAllCapabilities(spec *corev1.PodSpec, resource, names
 func (c *Checker) checkAllowPrivilegeEscalation(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		// Default is true when not set, so must be explicitly false.
 		if container.SecurityContext == nil ||
 			container.SecurityContext.AllowPrivilegeEscalation == nil ||
@@ -532,7 +959,7 @@ func (c *Checker) checkAllowPrivilegeEscalation(spec *corev1.PodSpec, resource,
 func (c *Checker) checkReadOnlyRootFilesystem(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
 	var findings []scanner.Finding
 
-	for _, container := range allContainers(spec) {
+	for _, container := range AllContainers(spec) {
 		if container.SecurityContext == nil ||
 			container.SecurityContext.ReadOnlyRootFilesystem == nil ||
 			!*container.SecurityContext.ReadOnlyRootFilesystem {
@@ -558,15 +985,156 @@ func (c *Checker) checkReadOnlyRootFilesystem(spec *corev1.PodSpec, resource, na
 	return findings
 }
 
+// restrictedVolumeSources are the volume types the Restricted profile allows.
+var restrictedVolumeSources = []string{"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret"}
+
+// volumeSourceType returns the name of the volume source set on a volume.
+func volumeSourceType(vol corev1.Volume) string {
+	v := vol.VolumeSource
+	switch {
+	case v.ConfigMap != nil:
+		return "configMap"
+	case v.CSI != nil:
+		return "csi"
+	case v.DownwardAPI != nil:
+		return "downwardAPI"
+	case v.EmptyDir != nil:
+		return "emptyDir"
+	case v.Ephemeral != nil:
+		return "ephemeral"
+	case v.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim"
+	case v.Projected != nil:
+		return "projected"
+	case v.Secret != nil:
+		return "secret"
+	case v.HostPath != nil:
+		return "hostPath"
+	case v.NFS != nil:
+		return "nfs"
+	case v.ISCSI != nil:
+		return "iscsi"
+	case v.GitRepo != nil:
+		return "gitRepo"
+	case v.Image != nil:
+		return "image"
+	}
+	return "other"
+}
+
+// checkRestrictedVolumeTypes checks for volume types outside the Restricted
+// allowlist. hostPath is reported by PSS-B007.
+func (c *Checker) checkRestrictedVolumeTypes(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, vol := range spec.Volumes {
+		volType := volumeSourceType(vol)
+		if volType == "hostPath" || slices.Contains(restrictedVolumeSources, volType) {
+			continue
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-R006",
+			Title:       "Volume type not allowed by Restricted",
+			Description: fmt.Sprintf("%s uses %s volume %q, which is outside the Restricted volume allowlist", resource, volType, vol.Name),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: fmt.Sprintf("Replace the volume with one of: %s. Mount network storage through a PersistentVolumeClaim instead of inline.", strings.Join(restrictedVolumeSources, ", ")),
+			Details: map[string]string{
+				"volume_name": vol.Name,
+				"volume_type": volType,
+				"profile":     string(ProfileRestricted),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// checkRunAsRootUser verifies no pod or container sets runAsUser: 0.
+func (c *Checker) checkRunAsRootUser(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	podRoot := spec.SecurityContext != nil && spec.SecurityContext.RunAsUser != nil && *spec.SecurityContext.RunAsUser == 0
+	for _, container := range AllContainers(spec) {
+		var containerUID *int64
+		if container.SecurityContext != nil {
+			containerUID = container.SecurityContext.RunAsUser
+		}
+		// A container-level UID overrides the pod-level one.
+		if (containerUID == nil && !podRoot) || (containerUID != nil && *containerUID != 0) {
+			continue
+		}
+		findings = append(findings, scanner.Finding{
+			ID:          "PSS-R007",
+			Title:       "Container runs as UID 0",
+			Description: fmt.Sprintf("Container %q in %s sets runAsUser: 0", container.Name, resource),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   namespace,
+			Remediation: "Set runAsUser to a non-zero UID at the pod or container level, or remove it and rely on the image's non-root user.",
+			Details: map[string]string{
+				"container": container.Name,
+				"profile":   string(ProfileRestricted),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
+
+// checkRestrictedCapabilities checks for added capabilities other than
+// NET_BIND_SERVICE. Capabilities outside the Baseline safe set are reported
+// by PSS-B006.
+func (c *Checker) checkRestrictedCapabilities(spec *corev1.PodSpec, resource, namespace string, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, container := range AllContainers(spec) {
+		if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+			continue
+		}
+		for _, cap := range container.SecurityContext.Capabilities.Add {
+			if cap == "NET_BIND_SERVICE" || !baselineCapabilities[cap] {
+				continue
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "PSS-R008",
+				Title:       "Capability added beyond NET_BIND_SERVICE",
+				Description: fmt.Sprintf("Container %q in %s adds capability %s; Restricted only allows NET_BIND_SERVICE", container.Name, resource, cap),
+				Severity:    scanner.SeverityMedium,
+				Status:      scanner.StatusFail,
+				Category:    "pss",
+				Resource:    resource,
+				Namespace:   namespace,
+				Remediation: fmt.Sprintf("Remove capability %s from securityContext.capabilities.add.", cap),
+				Details: map[string]string{
+					"container":  container.Name,
+					"capability": string(cap),
+					"profile":    string(ProfileRestricted),
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	return findings
+}
+
 // CheckDeployment evaluates a single Deployment's pod template against PSS.
 // This is exported for use by the scanner when checking individual resources.
 func (c *Checker) CheckDeployment(deploy *appsv1.Deployment, now time.Time) []scanner.Finding {
 	resource := fmt.Sprintf("Deployment/%s/%s", deploy.Namespace, deploy.Name)
-	return c.checkPodSpec(&deploy.Spec.Template.Spec, resource, deploy.Namespace, now)
+	return c.checkPodSpec(&deploy.Spec.Template.Spec, deploy.Spec.Template.Annotations, resource, deploy.Namespace, now)
 }
 
 // CheckPod evaluates a single Pod against PSS.
 func (c *Checker) CheckPod(pod *corev1.Pod, now time.Time) []scanner.Finding {
 	resource := fmt.Sprintf("Pod/%s/%s", pod.Namespace, pod.Name)
-	return c.checkPodSpec(&pod.Spec, resource, pod.Namespace, now)
+	return c.checkPodSpec(&pod.Spec, pod.Annotations, resource, pod.Namespace, now)
 }
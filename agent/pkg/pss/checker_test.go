@@ -0,0 +1,219 @@
+package pss
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// restrictedSpec returns a pod spec that passes every Baseline and
+// Restricted control.
+func restrictedSpec() *corev1.PodSpec {
+	yes, no := true, false
+	return &corev1.PodSpec{
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsNonRoot:   &yes,
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+		Containers: []corev1.Container{{
+			Name:  "app",
+			Image: "registry.example.com/app:1.0",
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: &no,
+				ReadOnlyRootFilesystem:   &yes,
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		}},
+	}
+}
+
+func TestCheckPodSpec(t *testing.T) {
+	yes, no := true, false
+	root, user := int64(0), int64(1000)
+	unmasked := corev1.UnmaskedProcMount
+
+	tests := []struct {
+		name        string
+		modify      func(spec *corev1.PodSpec)
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name:   "compliant",
+			modify: func(spec *corev1.PodSpec) {},
+		},
+		{
+			name: "unmasked procMount",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.ProcMount = &unmasked
+			},
+			want: []string{"PSS-B008"},
+		},
+		{
+			name: "safe sysctl",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.Sysctls = []corev1.Sysctl{{Name: "net.ipv4.tcp_syncookies", Value: "1"}}
+			},
+		},
+		{
+			name: "unsafe sysctl",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.Sysctls = []corev1.Sysctl{{Name: "kernel.msgmax", Value: "65536"}}
+			},
+			want: []string{"PSS-B009"},
+		},
+		{
+			name: "unconfined AppArmor field",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.AppArmorProfile = &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeUnconfined}
+			},
+			want: []string{"PSS-B010"},
+		},
+		{
+			name:        "unconfined AppArmor annotation",
+			modify:      func(spec *corev1.PodSpec) {},
+			annotations: map[string]string{appArmorAnnotationPrefix + "app": "unconfined"},
+			want:        []string{"PSS-B010"},
+		},
+		{
+			name:        "runtime default AppArmor annotation",
+			modify:      func(spec *corev1.PodSpec) {},
+			annotations: map[string]string{appArmorAnnotationPrefix + "app": "runtime/default"},
+		},
+		{
+			name: "container SELinux type",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.SELinuxOptions = &corev1.SELinuxOptions{Type: "container_t", Level: "s0:c1,c2"}
+			},
+		},
+		{
+			name: "custom SELinux type and user",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.SELinuxOptions = &corev1.SELinuxOptions{Type: "spc_t", User: "system_u"}
+			},
+			want: []string{"PSS-B011"},
+		},
+		{
+			name: "Windows HostProcess",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.WindowsOptions = &corev1.WindowsSecurityContextOptions{HostProcess: &yes}
+			},
+			want: []string{"PSS-B012"},
+		},
+		{
+			name: "container seccomp Unconfined",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+			},
+			want: []string{"PSS-B013"},
+		},
+		{
+			name: "container overrides runAsNonRoot",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.RunAsNonRoot = &no
+			},
+			want: []string{"PSS-R001"},
+		},
+		{
+			name: "seccomp missing",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.SeccompProfile = nil
+			},
+			want: []string{"PSS-R002"},
+		},
+		{
+			name: "hostPath volume reported by Baseline only",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Volumes = []corev1.Volume{{Name: "host", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}}}}
+			},
+			want: []string{"PSS-B007"},
+		},
+		{
+			name: "inline NFS volume",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/"}}}}
+			},
+			want: []string{"PSS-R006"},
+		},
+		{
+			name: "allowed volumes",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Volumes = []corev1.Volume{
+					{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+				}
+			},
+		},
+		{
+			name: "pod runs as UID 0",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.RunAsUser = &root
+			},
+			want: []string{"PSS-R007"},
+		},
+		{
+			name: "container overrides pod UID 0",
+			modify: func(spec *corev1.PodSpec) {
+				spec.SecurityContext.RunAsUser = &root
+				spec.Containers[0].SecurityContext.RunAsUser = &user
+			},
+		},
+		{
+			name: "NET_BIND_SERVICE added",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"NET_BIND_SERVICE"}
+			},
+		},
+		{
+			name: "Baseline capability added",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"SYS_CHROOT"}
+			},
+			want: []string{"PSS-R008"},
+		},
+		{
+			name: "capability outside Baseline reported by Baseline only",
+			modify: func(spec *corev1.PodSpec) {
+				spec.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"SYS_ADMIN"}
+			},
+			want: []string{"PSS-B006"},
+		},
+		{
+			name: "ephemeral container checked",
+			modify: func(spec *corev1.PodSpec) {
+				spec.EphemeralContainers = []corev1.EphemeralContainer{{
+					EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+						Name:            "debug",
+						SecurityContext: &corev1.SecurityContext{ProcMount: &unmasked},
+					},
+				}}
+			},
+			want: []string{"PSS-B008", "PSS-R003", "PSS-R004", "PSS-R005"},
+		},
+	}
+
+	c := NewChecker(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := restrictedSpec()
+			tt.modify(spec)
+
+			findings := c.checkPodSpec(spec, tt.annotations, "Pod/default/test", "default", time.Now())
+			var got []string
+			seen := make(map[string]bool)
+			for _, f := range findings {
+				if !seen[f.ID] {
+					seen[f.ID] = true
+					got = append(got, f.ID)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("finding IDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package pss
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// versionPattern matches PSA version label values other than "latest".
+var versionPattern = regexp.MustCompile(`^v1\.[0-9]+$`)
+
+// DryRunReport describes what enforcing a PSS level would reject.
+type DryRunReport struct {
+	Level      Profile              `json:"level"`
+	Version    string               `json:"version"`
+	Namespaces []NamespaceReadiness `json:"namespaces"`
+}
+
+// NamespaceReadiness is the dry-run verdict for one namespace.
+type NamespaceReadiness struct {
+	Namespace string `json:"namespace"`
+	// Enforce is the level currently enforced, or "" when unlabelled.
+	Enforce string `json:"enforce,omitempty"`
+	Ready   bool   `json:"ready"`
+	// Workloads is the number of controllers and unowned pods evaluated.
+	Workloads int                 `json:"workloads"`
+	Rejected  []WorkloadViolation `json:"rejected,omitempty"`
+	// Error is set when the namespace could not be fully listed.
+	Error string `json:"error,omitempty"`
+}
+
+// WorkloadViolation lists why a controller's pods, or an unowned pod, would
+// be rejected.
+type WorkloadViolation struct {
+	Workload   string      `json:"workload"`
+	Pods       []string    `json:"pods,omitempty"`
+	Violations []Violation `json:"violations"`
+}
+
+// Violation is a single failed check for a container or the pod.
+type Violation struct {
+	Check     string  `json:"check"`
+	Title     string  `json:"title"`
+	Profile   Profile `json:"profile"`
+	Container string  `json:"container,omitempty"`
+	Fix       string  `json:"fix"`
+}
+
+// DryRun evaluates the namespaces as if the given level were enforced. Pod
+// templates of controllers are checked because that is what admission will
+// see when pods are next created; running pods are attributed to their
+// top-level controller, and unowned pods are checked themselves.
+// A pinned version "v1.<minor>" evaluates the controls and safe sysctls of
+// that version of the standard; versions newer than the checks know are
+// evaluated as latest, as admission does.
+func (c *Checker) DryRun(ctx context.Context, namespaces []string, level Profile, version string) (*DryRunReport, error) {
+	if level != ProfileBaseline && level != ProfileRestricted {
+		return nil, fmt.Errorf("unsupported level %q (valid: baseline, restricted)", level)
+	}
+	if version == "" {
+		version = "latest"
+	}
+	if version != "latest" {
+		if !versionPattern.MatchString(version) {
+			return nil, fmt.Errorf("invalid version %q: expected latest or v1.<minor>", version)
+		}
+		minor, err := strconv.Atoi(strings.TrimPrefix(version, "v1."))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		pinned := *c
+		pinned.pinned, pinned.minor = true, minor
+		c = &pinned
+	}
+
+	nsLabels := make(map[string]map[string]string)
+	allNamespaces, err := c.client.ListNamespaces(ctx)
+	if err != nil {
+		c.logger.Warn("failed to list namespaces", "error", err)
+	}
+	for _, ns := range allNamespaces {
+		nsLabels[ns.Name] = ns.Labels
+	}
+
+	report := &DryRunReport{Level: level, Version: version}
+	now := time.Now()
+	for _, ns := range namespaces {
+		readiness := NamespaceReadiness{
+			Namespace: ns,
+			Enforce:   PolicyFromLabels(nsLabels[ns]).Enforce.Level,
+		}
+
+		workloads, err := c.collectWorkloads(ctx, ns)
+		if err != nil {
+			readiness.Error = err.Error()
+		}
+		readiness.Rejected, readiness.Workloads = c.dryRunNamespace(workloads, level, now)
+		readiness.Ready = err == nil && len(readiness.Rejected) == 0
+		report.Namespaces = append(report.Namespaces, readiness)
+	}
+
+	return report, nil
+}
+
+// dryRunNamespace groups the violations the given level enforces by owning
+// controller. It returns the rejected workloads and the number evaluated.
+func (c *Checker) dryRunNamespace(workloads []workload, level Profile, now time.Time) ([]WorkloadViolation, int) {
+	findings, evaluated := c.evaluateWorkloads(workloads, now)
+
+	byRef := make(map[string]*WorkloadViolation)
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		profile := Profile(f.Details["profile"])
+		if f.Status != scanner.StatusFail || profile.Stricter(level) {
+			continue
+		}
+
+		wv, ok := byRef[f.Resource]
+		if !ok {
+			wv = &WorkloadViolation{Workload: f.Resource}
+			byRef[f.Resource] = wv
+		}
+		for _, pod := range strings.Split(f.Details[DetailPods], ",") {
+			if pod != "" && !slices.Contains(wv.Pods, pod) {
+				wv.Pods = append(wv.Pods, pod)
+			}
+		}
+
+		key := f.Resource + "/" + f.ID + "/" + f.Details["container"]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		wv.Violations = append(wv.Violations, Violation{
+			Check:     f.ID,
+			Title:     f.Title,
+			Profile:   profile,
+			Container: f.Details["container"],
+			Fix:       f.Remediation,
+		})
+	}
+
+	rejected := make([]WorkloadViolation, 0, len(byRef))
+	for _, wv := range byRef {
+		sort.Strings(wv.Pods)
+		rejected = append(rejected, *wv)
+	}
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Workload < rejected[j].Workload })
+	return rejected, evaluated
+}
+
+// NotReady returns the namespaces that would reject workloads.
+func (r *DryRunReport) NotReady() []string {
+	var names []string
+	for _, ns := range r.Namespaces {
+		if !ns.Ready {
+			names = append(names, ns.Namespace)
+		}
+	}
+	return names
+}
+
+// WriteText writes a human-readable dry-run report.
+func (r *DryRunReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry-run: enforce=%s, version=%s\n", r.Level, r.Version)
+
+	for _, ns := range r.Namespaces {
+		current := ns.Enforce
+		if current == "" {
+			current = "unset"
+		}
+		b.WriteString("\n")
+		switch {
+		case ns.Error != "":
+			fmt.Fprintf(&b, "Namespace %s: UNKNOWN (currently enforce=%s): %s\n", ns.Namespace, current, ns.Error)
+		case ns.Ready:
+			fmt.Fprintf(&b, "Namespace %s: READY (currently enforce=%s): all %d workloads satisfy %s\n", ns.Namespace, current, ns.Workloads, r.Level)
+		default:
+			fmt.Fprintf(&b, "Namespace %s: NOT READY (currently enforce=%s): %d of %d workloads would be rejected\n", ns.Namespace, current, len(ns.Rejected), ns.Workloads)
+		}
+
+		for _, wv := range ns.Rejected {
+			fmt.Fprintf(&b, "  %s\n", wv.Workload)
+			if len(wv.Pods) > 0 {
+				fmt.Fprintf(&b, "    pods: %s\n", strings.Join(wv.Pods, ", "))
+			}
+			for _, v := range wv.Violations {
+				target := "pod"
+				if v.Container != "" {
+					target = fmt.Sprintf("container %q", v.Container)
+				}
+				fmt.Fprintf(&b, "    - %s [%s] %s: %s\n", v.Check, v.Profile, target, v.Title)
+				fmt.Fprintf(&b, "      fix: %s\n", v.Fix)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,134 @@
+package pss
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// summarize flattens rejected workloads for comparison.
+func summarize(rejected []WorkloadViolation) []string {
+	var out []string
+	for _, wv := range rejected {
+		for _, v := range wv.Violations {
+			out = append(out, fmt.Sprintf("%s %v %s/%s", wv.Workload, wv.Pods, v.Check, v.Container))
+		}
+	}
+	return out
+}
+
+func TestDryRunNamespace(t *testing.T) {
+	yes := true
+
+	// The template lets the app escalate privileges; the running pods also
+	// carry an injected privileged sidecar.
+	template := restrictedSpec()
+	template.Containers[0].SecurityContext.AllowPrivilegeEscalation = &yes
+	injected := template.DeepCopy()
+	sidecar := *restrictedSpec().Containers[0].DeepCopy()
+	sidecar.Name = "proxy"
+	sidecar.SecurityContext.Privileged = &yes
+	injected.Containers = append(injected.Containers, sidecar)
+
+	hostNetwork := restrictedSpec()
+	hostNetwork.HostNetwork = true
+
+	workloads := []workload{
+		{kind: "Deployment", namespace: "shop", name: "web", spec: template},
+		{kind: "Pod", namespace: "shop", name: "web-7d4b9-b", spec: injected, pod: &corev1.Pod{}, owner: "Deployment/shop/web"},
+		{kind: "Pod", namespace: "shop", name: "web-7d4b9-a", spec: injected, pod: &corev1.Pod{}, owner: "Deployment/shop/web"},
+		{kind: "Job", namespace: "shop", name: "backup", spec: hostNetwork},
+		{kind: "Pod", namespace: "shop", name: "debug", spec: restrictedSpec(), pod: &corev1.Pod{}},
+	}
+
+	tests := []struct {
+		name  string
+		level Profile
+		want  []string
+	}{
+		{
+			name:  "restricted",
+			level: ProfileRestricted,
+			want: []string{
+				"Deployment/shop/web [web-7d4b9-a web-7d4b9-b] PSS-R004/app",
+				"Deployment/shop/web [web-7d4b9-a web-7d4b9-b] PSS-B001/proxy",
+				"Job/shop/backup [] PSS-B002/",
+			},
+		},
+		{
+			name:  "baseline ignores restricted controls",
+			level: ProfileBaseline,
+			want: []string{
+				"Deployment/shop/web [web-7d4b9-a web-7d4b9-b] PSS-B001/proxy",
+				"Job/shop/backup [] PSS-B002/",
+			},
+		},
+	}
+
+	c := NewChecker(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected, evaluated := c.dryRunNamespace(workloads, tt.level, time.Now())
+			if evaluated != 3 {
+				t.Errorf("evaluated = %d, want 3", evaluated)
+			}
+			if got := summarize(rejected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rejected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunVersion(t *testing.T) {
+	root := int64(0)
+	spec := restrictedSpec()
+	spec.SecurityContext.RunAsUser = &root
+	spec.SecurityContext.Sysctls = []corev1.Sysctl{{Name: "net.ipv4.ip_local_reserved_ports", Value: "8080"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "tuned", Namespace: "shop"}, Spec: *spec}
+	client := k8s.NewClientFromInterface(fake.NewSimpleClientset(pod), "test", nil)
+
+	tests := []struct {
+		name    string
+		version string
+		want    []string
+		wantErr bool
+	}{
+		{name: "latest", version: "latest", want: []string{"Pod/shop/tuned [] PSS-R007/app"}},
+		{name: "default", want: []string{"Pod/shop/tuned [] PSS-R007/app"}},
+		{name: "reserved ports are safe from v1.27", version: "v1.27", want: []string{"Pod/shop/tuned [] PSS-R007/app"}},
+		{name: "reserved ports are unsafe before v1.27", version: "v1.26", want: []string{"Pod/shop/tuned [] PSS-B009/", "Pod/shop/tuned [] PSS-R007/app"}},
+		{name: "root user allowed before v1.23", version: "v1.22", want: []string{"Pod/shop/tuned [] PSS-B009/"}},
+		{name: "future version evaluated as latest", version: "v1.99", want: []string{"Pod/shop/tuned [] PSS-R007/app"}},
+		{name: "missing v prefix", version: "1.27", wantErr: true},
+		{name: "unknown major", version: "v2.0", wantErr: true},
+	}
+
+	c := NewChecker(client, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := c.DryRun(context.Background(), []string{"shop"}, ProfileRestricted, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DryRun() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := summarize(report.Namespaces[0].Rejected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rejected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Pinning a version must not change the checker it was called on.
+	if findings := c.CheckPod(pod, time.Now()); len(findings) == 0 {
+		t.Error("CheckPod() after a pinned DryRun reported no findings")
+	}
+}
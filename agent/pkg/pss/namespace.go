@@ -0,0 +1,166 @@
+package pss
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Pod Security Admission namespace label prefix. Each mode has a level label
+// ("<prefix>enforce") and a version label ("<prefix>enforce-version").
+const psaLabelPrefix = "pod-security.kubernetes.io/"
+
+// PSA modes.
+const (
+	ModeEnforce = "enforce"
+	ModeAudit   = "audit"
+	ModeWarn    = "warn"
+)
+
+// profileRank orders profiles from least to most restrictive.
+var profileRank = map[Profile]int{
+	ProfilePrivileged: 0,
+	ProfileBaseline:   1,
+	ProfileRestricted: 2,
+}
+
+// ParseProfile parses a PSA level label value.
+func ParseProfile(s string) (Profile, bool) {
+	p := Profile(s)
+	_, ok := profileRank[p]
+	return p, ok
+}
+
+// Stricter reports whether p is more restrictive than other.
+func (p Profile) Stricter(other Profile) bool {
+	return profileRank[p] > profileRank[other]
+}
+
+// ModeSetting is the level and version configured for one PSA mode. An empty
+// Level means the label is absent.
+type ModeSetting struct {
+	Level   string
+	Version string
+}
+
+// NamespacePolicy is the Pod Security Admission configuration of a namespace,
+// read from its pod-security.kubernetes.io labels.
+type NamespacePolicy struct {
+	Enforce ModeSetting
+	Audit   ModeSetting
+	Warn    ModeSetting
+}
+
+// PolicyFromLabels reads the PSA labels of a namespace.
+func PolicyFromLabels(labels map[string]string) NamespacePolicy {
+	mode := func(name string) ModeSetting {
+		return ModeSetting{
+			Level:   labels[psaLabelPrefix+name],
+			Version: labels[psaLabelPrefix+name+"-version"],
+		}
+	}
+	return NamespacePolicy{
+		Enforce: mode(ModeEnforce),
+		Audit:   mode(ModeAudit),
+		Warn:    mode(ModeWarn),
+	}
+}
+
+// HighestProfile returns the most restrictive profile that no failing
+// finding violates. Warnings such as PSS-R005 are not part of the standard
+// and do not lower the level.
+func HighestProfile(findings []scanner.Finding) Profile {
+	highest := ProfileRestricted
+	for _, f := range findings {
+		if f.Status != scanner.StatusFail {
+			continue
+		}
+		switch Profile(f.Details["profile"]) {
+		case ProfileBaseline:
+			return ProfilePrivileged
+		case ProfileRestricted:
+			highest = ProfileBaseline
+		}
+	}
+	return highest
+}
+
+// auditNamespaceLabels compares a namespace's PSA labels with the highest
+// profile its current workloads satisfy. It reports a missing enforce label
+// (PSS-N001), an enforce level that could be raised without violations
+// (PSS-N002), or a pass carrying the configured levels.
+func (c *Checker) auditNamespaceLabels(ns string, labels map[string]string, nsFindings []scanner.Finding, now time.Time) scanner.Finding {
+	policy := PolicyFromLabels(labels)
+	achievable := HighestProfile(nsFindings)
+
+	details := map[string]string{"achievable_level": string(achievable)}
+	for name, m := range map[string]ModeSetting{ModeEnforce: policy.Enforce, ModeAudit: policy.Audit, ModeWarn: policy.Warn} {
+		level, version := m.Level, m.Version
+		if level == "" {
+			level = "unset"
+		}
+		if version == "" {
+			version = "latest"
+		}
+		details[name] = level
+		details[name+"_version"] = version
+	}
+
+	labelCmd := fmt.Sprintf("kubectl label --overwrite namespace %s %s%s=%s", ns, psaLabelPrefix, ModeEnforce, achievable)
+	resource := "Namespace/" + ns
+
+	enforced, valid := ParseProfile(policy.Enforce.Level)
+	switch {
+	case !valid:
+		description := fmt.Sprintf("Namespace %s has no %s%s label, so pods are admitted at the privileged level; its workloads satisfy %s", ns, psaLabelPrefix, ModeEnforce, achievable)
+		if policy.Enforce.Level != "" {
+			description = fmt.Sprintf("Namespace %s has an invalid %s%s value %q; its workloads satisfy %s", ns, psaLabelPrefix, ModeEnforce, policy.Enforce.Level, achievable)
+		}
+		remediation := fmt.Sprintf("Label the namespace with the level its workloads already meet: `%s`. Pin %s%s-version to the cluster minor version to avoid surprises on upgrade.", labelCmd, psaLabelPrefix, ModeEnforce)
+		if achievable == ProfilePrivileged {
+			remediation = fmt.Sprintf("Fix the Baseline violations in this namespace, then enforce it. Until then, surface new violations with `kubectl label --overwrite namespace %s %s%s=%s %s%s=%s`.", ns, psaLabelPrefix, ModeAudit, ProfileBaseline, psaLabelPrefix, ModeWarn, ProfileBaseline)
+		}
+		return scanner.Finding{
+			ID:          "PSS-N001",
+			Title:       "Namespace has no Pod Security Admission enforce label",
+			Description: description,
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   ns,
+			Remediation: remediation,
+			Details:     details,
+			Timestamp:   now,
+		}
+
+	case achievable.Stricter(enforced):
+		return scanner.Finding{
+			ID:          "PSS-N002",
+			Title:       "Pod Security Admission enforce level can be raised",
+			Description: fmt.Sprintf("Namespace %s enforces %s, but all of its workloads satisfy %s", ns, enforced, achievable),
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusWarning,
+			Category:    "pss",
+			Resource:    resource,
+			Namespace:   ns,
+			Remediation: fmt.Sprintf("Raise the enforce level so new workloads cannot regress: `%s`.", labelCmd),
+			Details:     details,
+			Timestamp:   now,
+		}
+	}
+
+	return scanner.Finding{
+		ID:          "PSS-N002",
+		Title:       "Pod Security Admission enforce level set",
+		Description: fmt.Sprintf("Namespace %s enforces %s; its workloads satisfy %s", ns, enforced, achievable),
+		Severity:    scanner.SeverityLow,
+		Status:      scanner.StatusPass,
+		Category:    "pss",
+		Resource:    resource,
+		Namespace:   ns,
+		Details:     details,
+		Timestamp:   now,
+	}
+}
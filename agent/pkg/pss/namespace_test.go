@@ -0,0 +1,147 @@
+package pss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// violation returns a PSS finding against the given profile.
+func violation(profile Profile, status scanner.FindingStatus) scanner.Finding {
+	return scanner.Finding{Status: status, Details: map[string]string{"profile": string(profile)}}
+}
+
+func TestHighestProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []scanner.Finding
+		want     Profile
+	}{
+		{name: "no findings", want: ProfileRestricted},
+		{
+			name:     "restricted violation",
+			findings: []scanner.Finding{violation(ProfileRestricted, scanner.StatusFail)},
+			want:     ProfileBaseline,
+		},
+		{
+			name:     "baseline violation",
+			findings: []scanner.Finding{violation(ProfileRestricted, scanner.StatusFail), violation(ProfileBaseline, scanner.StatusFail)},
+			want:     ProfilePrivileged,
+		},
+		{
+			name:     "warnings and passes do not lower the level",
+			findings: []scanner.Finding{violation(ProfileRestricted, scanner.StatusWarning), violation(ProfileBaseline, scanner.StatusPass)},
+			want:     ProfileRestricted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HighestProfile(tt.findings); got != tt.want {
+				t.Errorf("HighestProfile() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditNamespaceLabels(t *testing.T) {
+	restrictedOnly := []scanner.Finding{violation(ProfileRestricted, scanner.StatusFail)}
+	baselineToo := []scanner.Finding{violation(ProfileBaseline, scanner.StatusFail)}
+	enforce := func(level string) map[string]string {
+		return map[string]string{psaLabelPrefix + ModeEnforce: level}
+	}
+
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		findings   []scanner.Finding
+		wantID     string
+		wantStatus scanner.FindingStatus
+		achievable string
+		enforce    string
+	}{
+		{
+			name:       "unlabelled",
+			findings:   restrictedOnly,
+			wantID:     "PSS-N001",
+			wantStatus: scanner.StatusFail,
+			achievable: "baseline",
+			enforce:    "unset",
+		},
+		{
+			name:       "unlabelled with baseline violations",
+			findings:   baselineToo,
+			wantID:     "PSS-N001",
+			wantStatus: scanner.StatusFail,
+			achievable: "privileged",
+			enforce:    "unset",
+		},
+		{
+			name:       "invalid level",
+			labels:     enforce("strict"),
+			wantID:     "PSS-N001",
+			wantStatus: scanner.StatusFail,
+			achievable: "restricted",
+			enforce:    "strict",
+		},
+		{
+			name:       "level can be raised",
+			labels:     enforce("privileged"),
+			findings:   restrictedOnly,
+			wantID:     "PSS-N002",
+			wantStatus: scanner.StatusWarning,
+			achievable: "baseline",
+			enforce:    "privileged",
+		},
+		{
+			name:       "level matches workloads",
+			labels:     enforce("baseline"),
+			findings:   restrictedOnly,
+			wantID:     "PSS-N002",
+			wantStatus: scanner.StatusPass,
+			achievable: "baseline",
+			enforce:    "baseline",
+		},
+		{
+			// Admission rejects new violating pods; the running ones are
+			// reported by the per-workload checks.
+			name:       "level above workloads",
+			labels:     enforce("restricted"),
+			findings:   baselineToo,
+			wantID:     "PSS-N002",
+			wantStatus: scanner.StatusPass,
+			achievable: "privileged",
+			enforce:    "restricted",
+		},
+	}
+
+	c := NewChecker(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := c.auditNamespaceLabels("shop", tt.labels, tt.findings, time.Now())
+			if f.ID != tt.wantID || f.Status != tt.wantStatus {
+				t.Errorf("auditNamespaceLabels() = %s %s, want %s %s", f.ID, f.Status, tt.wantID, tt.wantStatus)
+			}
+			if f.Details["achievable_level"] != tt.achievable || f.Details[ModeEnforce] != tt.enforce {
+				t.Errorf("details = achievable %s, enforce %s; want %s, %s", f.Details["achievable_level"], f.Details[ModeEnforce], tt.achievable, tt.enforce)
+			}
+			if f.Resource != "Namespace/shop" {
+				t.Errorf("resource = %s, want Namespace/shop", f.Resource)
+			}
+		})
+	}
+
+	t.Run("versions default to latest", func(t *testing.T) {
+		f := c.auditNamespaceLabels("shop", map[string]string{
+			psaLabelPrefix + ModeEnforce:              "baseline",
+			psaLabelPrefix + ModeEnforce + "-version": "v1.29",
+			psaLabelPrefix + ModeWarn:                 "restricted",
+		}, nil, time.Now())
+		for key, want := range map[string]string{"enforce_version": "v1.29", "warn": "restricted", "warn_version": "latest", "audit": "unset"} {
+			if f.Details[key] != want {
+				t.Errorf("details[%s] = %q, want %q", key, f.Details[key], want)
+			}
+		}
+	})
+}
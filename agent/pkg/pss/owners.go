@@ -0,0 +1,110 @@
+package pss
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// DetailPods is the finding detail listing the pods affected by a violation
+// reported against their owning controller.
+const DetailPods = "pods"
+
+// evaluateWorkloads runs the checks over a namespace's workloads and reports
+// each violation once against the top-level controller. Templates of
+// controllers that are themselves controlled, such as the Deployment of a
+// Knative Revision, are reported against that top-level controller. Owned
+// pods are still evaluated, so violations added at admission time (such as
+// injected sidecars) are not lost; they are merged into the owner's findings
+// with the affected pods listed under DetailPods. Unowned pods are reported
+// as themselves. It also returns the number of workloads evaluated.
+func (c *Checker) evaluateWorkloads(workloads []workload, now time.Time) ([]scanner.Finding, int) {
+	var findings []scanner.Finding
+	index := make(map[string]int)
+	evaluated := make(map[string]bool)
+
+	merge := func(f scanner.Finding, pod string) {
+		key := findingKey(f)
+		if i, ok := index[key]; ok {
+			if pod != "" {
+				findings[i].Details[DetailPods] = appendPod(findings[i].Details[DetailPods], pod)
+			}
+			return
+		}
+		if pod != "" {
+			if f.Details == nil {
+				f.Details = make(map[string]string)
+			}
+			f.Details[DetailPods] = pod
+		}
+		index[key] = len(findings)
+		findings = append(findings, f)
+	}
+
+	// Templates first, so pods merge into their controller's findings.
+	for _, w := range workloads {
+		if w.pod != nil {
+			continue
+		}
+		resource := w.resource()
+		if w.owner != "" {
+			resource = w.owner
+		}
+		evaluated[resource] = true
+		for _, f := range c.checkPodSpec(w.spec, w.annotations, resource, w.namespace, now) {
+			merge(f, "")
+		}
+	}
+
+	for _, w := range workloads {
+		if w.pod == nil {
+			continue
+		}
+		if w.owner == "" {
+			evaluated[w.resource()] = true
+			for _, f := range c.checkPodSpec(w.spec, w.annotations, w.resource(), w.namespace, now) {
+				merge(f, "")
+			}
+			continue
+		}
+		evaluated[w.owner] = true
+		for _, f := range c.checkPodSpec(w.spec, w.annotations, w.owner, w.namespace, now) {
+			merge(f, w.name)
+		}
+	}
+
+	return findings, len(evaluated)
+}
+
+// findingKey identifies a violation independently of which pod or template
+// it was found on.
+func findingKey(f scanner.Finding) string {
+	keys := make([]string, 0, len(f.Details))
+	for k := range f.Details {
+		if k != DetailPods {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(f.ID + "|" + f.Resource)
+	for _, k := range keys {
+		b.WriteString("|" + k + "=" + f.Details[k])
+	}
+	return b.String()
+}
+
+// appendPod adds a pod to a comma-separated pod list.
+func appendPod(list, pod string) string {
+	if list == "" {
+		return pod
+	}
+	if slices.Contains(strings.Split(list, ","), pod) {
+		return list
+	}
+	return list + "," + pod
+}
@@ -0,0 +1,107 @@
+package pss
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// controlledBy returns a controller reference to the named object.
+func controlledBy(apiVersion, kind, name string) []metav1.OwnerReference {
+	yes := true
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, UID: types.UID("uid-" + name), Controller: &yes}}
+}
+
+// knativeObject builds an unstructured Knative Serving object.
+func knativeObject(kind, name string, owners []metav1.OwnerReference, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion("serving.knative.dev/v1")
+	obj.SetKind(kind)
+	obj.SetNamespace("shop")
+	obj.SetName(name)
+	obj.SetOwnerReferences(owners)
+	return obj
+}
+
+func TestEvaluateWorkloadsKnativeService(t *testing.T) {
+	privileged := true
+	container := corev1.Container{
+		Name:            "user-container",
+		Image:           "registry.example.com/web:1.0",
+		SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+	}
+	labels := map[string]string{"app": "web-00001"}
+
+	// Service -> Configuration -> Revision -> Deployment -> ReplicaSet -> Pod.
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-00001-deployment", Namespace: "shop", OwnerReferences: controlledBy("serving.knative.dev/v1", "Revision", "web-00001")},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+		}},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-00001-deployment-7d4b9", Namespace: "shop", OwnerReferences: controlledBy("apps/v1", "Deployment", deployment.Name)},
+		Spec:       appsv1.ReplicaSetSpec{Template: deployment.Spec.Template},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-00001-deployment-7d4b9-x2x9k", Namespace: "shop", Labels: labels, OwnerReferences: controlledBy("apps/v1", "ReplicaSet", replicaSet.Name)},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+	}
+
+	template := map[string]interface{}{"template": map[string]interface{}{
+		"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{
+			"name":            "user-container",
+			"image":           "registry.example.com/web:1.0",
+			"securityContext": map[string]interface{}{"privileged": true},
+		}}},
+	}}
+	service := knativeObject("Service", "web", nil, template)
+	configuration := knativeObject("Configuration", "web", controlledBy("serving.knative.dev/v1", "Service", "web"), template)
+	revision := knativeObject("Revision", "web-00001", controlledBy("serving.knative.dev/v1", "Configuration", "web"), map[string]interface{}{})
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}:         "RolloutList",
+		{Group: "serving.knative.dev", Version: "v1", Resource: "services"}:       "ServiceList",
+		{Group: "serving.knative.dev", Version: "v1", Resource: "configurations"}: "ConfigurationList",
+		{Group: "serving.knative.dev", Version: "v1", Resource: "revisions"}:      "RevisionList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, service, configuration, revision)
+	client := k8s.NewClientFromInterface(fake.NewSimpleClientset(deployment, replicaSet, pod), "test", nil).WithDynamicClient(dynamicClient)
+
+	c := NewChecker(client, nil)
+	workloads, err := c.collectWorkloads(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("collectWorkloads() error = %v", err)
+	}
+	findings, evaluated := c.evaluateWorkloads(workloads, time.Now())
+
+	if evaluated != 1 {
+		t.Errorf("evaluated = %d, want 1", evaluated)
+	}
+	var got []string
+	for _, f := range findings {
+		if f.ID == "PSS-B001" {
+			got = append(got, f.Resource+" pods="+f.Details[DetailPods])
+		}
+	}
+	sort.Strings(got)
+	want := []string{"Service/shop/web pods=" + pod.Name}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PSS-B001 findings = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package pss
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// PodTemplateSource locates pod templates embedded in a custom resource.
+type PodTemplateSource struct {
+	GVR schema.GroupVersionResource
+	// Path is a JSONPath expression selecting the embedded pod template, an
+	// object with metadata and spec, e.g. "{.spec.template}". Expressions
+	// matching several templates evaluate each of them.
+	Path string
+	// Intermediates are resources the custom resource controls on the way to
+	// its pods, such as Knative Configurations and Revisions. They are only
+	// listed to attribute pods and controlled templates to the resource.
+	Intermediates []schema.GroupVersionResource
+}
+
+// DefaultPodTemplateSources are the custom resources evaluated by default.
+// Resources whose CRDs are not installed are skipped.
+var DefaultPodTemplateSources = []PodTemplateSource{
+	// Argo Rollouts; rollouts using workloadRef have no template and are
+	// covered by the referenced Deployment.
+	{GVR: schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}, Path: "{.spec.template}"},
+	// Knative Services; the revision template inlines the PodSpec fields.
+	// Each Revision controls a Deployment, and is controlled by a
+	// Configuration the Service controls.
+	{
+		GVR:  schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"},
+		Path: "{.spec.template}",
+		Intermediates: []schema.GroupVersionResource{
+			{Group: "serving.knative.dev", Version: "v1", Resource: "configurations"},
+			{Group: "serving.knative.dev", Version: "v1", Resource: "revisions"},
+		},
+	},
+}
+
+// ParsePodTemplateSource parses "resource.version.group=<jsonpath>", e.g.
+// "rollouts.v1alpha1.argoproj.io={.spec.template}".
+func ParsePodTemplateSource(s string) (PodTemplateSource, error) {
+	resource, path, ok := strings.Cut(s, "=")
+	if !ok || resource == "" || path == "" {
+		return PodTemplateSource{}, fmt.Errorf("invalid pod template source %q: expected resource.version.group=<jsonpath>", s)
+	}
+	gvr, _ := schema.ParseResourceArg(resource)
+	if gvr == nil {
+		return PodTemplateSource{}, fmt.Errorf("invalid pod template source %q: resource must be fully qualified as resource.version.group", s)
+	}
+	if err := jsonpath.New("pod-template").Parse(path); err != nil {
+		return PodTemplateSource{}, fmt.Errorf("invalid JSONPath in pod template source %q: %w", s, err)
+	}
+	return PodTemplateSource{GVR: *gvr, Path: path}, nil
+}
+
+// Templates extracts the pod templates selected by the source's path.
+func (s PodTemplateSource) Templates(obj *unstructured.Unstructured) ([]corev1.PodTemplateSpec, error) {
+	jp := jsonpath.New(s.GVR.Resource).AllowMissingKeys(true)
+	if err := jp.Parse(s.Path); err != nil {
+		return nil, fmt.Errorf("parsing JSONPath %q: %w", s.Path, err)
+	}
+	results, err := jp.FindResults(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating JSONPath %q: %w", s.Path, err)
+	}
+
+	var templates []corev1.PodTemplateSpec
+	for _, values := range results {
+		for _, v := range values {
+			raw, ok := v.Interface().(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var tmpl corev1.PodTemplateSpec
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &tmpl); err != nil {
+				return nil, fmt.Errorf("decoding pod template at %q: %w", s.Path, err)
+			}
+			templates = append(templates, tmpl)
+		}
+	}
+	return templates, nil
+}
+
+// customWorkloads lists the pod templates embedded in the configured custom
+// resources of a namespace, and records the controllers of those resources
+// and their intermediates in owners. Failures are logged, since these
+// resources are optional.
+func (c *Checker) customWorkloads(ctx context.Context, ns string, owners k8s.OwnerIndex) []workload {
+	var workloads []workload
+	for _, source := range c.templateSources {
+		for _, gvr := range source.Intermediates {
+			objects, err := c.client.ListCustomResources(ctx, gvr, ns)
+			if err != nil {
+				c.logger.Warn("failed to list custom resources", "resource", gvr.String(), "namespace", ns, "error", err)
+				continue
+			}
+			for i := range objects {
+				owners.Add(objects[i].GetKind(), &objects[i])
+			}
+		}
+
+		objects, err := c.client.ListCustomResources(ctx, source.GVR, ns)
+		if err != nil {
+			c.logger.Warn("failed to list custom resources", "resource", source.GVR.String(), "namespace", ns, "error", err)
+			continue
+		}
+		for i := range objects {
+			obj := &objects[i]
+			controlled := owners.Add(obj.GetKind(), obj)
+			templates, err := source.Templates(obj)
+			if err != nil {
+				c.logger.Warn("failed to extract pod template", "resource", source.GVR.String(), "name", obj.GetName(), "error", err)
+				continue
+			}
+			for j := range templates {
+				workloads = append(workloads, workload{
+					kind:        obj.GetKind(),
+					namespace:   obj.GetNamespace(),
+					name:        obj.GetName(),
+					spec:        &templates[j].Spec,
+					annotations: templates[j].Annotations,
+					controlled:  controlled,
+				})
+			}
+		}
+	}
+	return workloads
+}
@@ -0,0 +1,137 @@
+package rbac
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// bootstrapLabel marks the default roles the API server reconciles at startup.
+const bootstrapLabel = "kubernetes.io/bootstrapping"
+
+// defaultUnusedRoleAllowlist lists the bootstrap and controller-managed roles
+// that are expected to exist without direct bindings.
+var defaultUnusedRoleAllowlist = []string{
+	"system:*",
+	"cluster-admin",
+	"admin",
+	"edit",
+	"view",
+}
+
+// isIgnoredRole reports whether a role is exempt from unused-role detection,
+// either because it carries the bootstrap label or matches the allowlist.
+func (a *Analyzer) isIgnoredRole(name string, roleLabels map[string]string) bool {
+	if roleLabels[bootstrapLabel] == "rbac-defaults" {
+		return true
+	}
+	for _, pattern := range a.unusedRoleAllowlist {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+		if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregationIndex maps each ClusterRole to the aggregated ClusterRoles whose
+// aggregationRule selects it.
+type aggregationIndex struct {
+	parents map[string][]string
+	used    map[string]bool
+}
+
+// newAggregationIndex resolves the aggregationRule label selectors of all
+// ClusterRoles against the labels of every other ClusterRole.
+func newAggregationIndex(clusterRoles []rbacv1.ClusterRole, logger *slog.Logger) *aggregationIndex {
+	idx := &aggregationIndex{
+		parents: make(map[string][]string),
+		used:    make(map[string]bool),
+	}
+
+	for _, parent := range clusterRoles {
+		if parent.AggregationRule == nil {
+			continue
+		}
+		for _, ls := range parent.AggregationRule.ClusterRoleSelectors {
+			selector, err := metav1.LabelSelectorAsSelector(&ls)
+			if err != nil {
+				logger.Warn("invalid aggregation selector", "clusterRole", parent.Name, "error", err)
+				continue
+			}
+			for _, child := range clusterRoles {
+				if child.Name == parent.Name || !selector.Matches(labels.Set(child.Labels)) {
+					continue
+				}
+				if !containsString(idx.parents[child.Name], parent.Name) {
+					idx.parents[child.Name] = append(idx.parents[child.Name], parent.Name)
+				}
+			}
+		}
+	}
+
+	for name := range idx.parents {
+		sort.Strings(idx.parents[name])
+	}
+
+	return idx
+}
+
+// isUsed reports whether a ClusterRole is bound directly, or feeds (possibly
+// transitively) an aggregated ClusterRole that is bound or ignored.
+func (idx *aggregationIndex) isUsed(name string, bound, ignored map[string]bool) bool {
+	visiting := map[string]bool{}
+	used := idx.resolve(name, bound, ignored, visiting)
+	if !used {
+		// Everything reachable from name was explored without finding a
+		// bound or ignored parent, so none of it is used either.
+		for visited := range visiting {
+			idx.used[visited] = false
+		}
+	}
+	return used
+}
+
+// resolve searches the parents of name depth-first. Only positive results are
+// memoized here: a negative one may stem from cutting an aggregation cycle
+// whose other members are still being resolved, and is only final once the
+// whole search from isUsed has failed.
+func (idx *aggregationIndex) resolve(name string, bound, ignored, visiting map[string]bool) bool {
+	if bound[name] {
+		return true
+	}
+	if used, ok := idx.used[name]; ok {
+		return used
+	}
+	if visiting[name] {
+		return false
+	}
+	visiting[name] = true
+
+	for _, parent := range idx.parents[name] {
+		if ignored[parent] || idx.resolve(parent, bound, ignored, visiting) {
+			idx.used[name] = true
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether items contains s.
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
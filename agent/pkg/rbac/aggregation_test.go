@@ -0,0 +1,177 @@
+package rbac
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// aggregated returns a ClusterRole with labels that aggregates the
+// ClusterRoles labelled with any of selects.
+func aggregated(name string, roleLabels map[string]string, selects ...string) rbacv1.ClusterRole {
+	cr := rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: roleLabels}}
+	if len(selects) > 0 {
+		cr.AggregationRule = &rbacv1.AggregationRule{}
+		for _, label := range selects {
+			cr.AggregationRule.ClusterRoleSelectors = append(cr.AggregationRule.ClusterRoleSelectors,
+				metav1.LabelSelector{MatchLabels: map[string]string{label: "true"}})
+		}
+	}
+	return cr
+}
+
+func TestIsIgnoredRole(t *testing.T) {
+	a := NewAnalyzer(nil, nil, WithUnusedRoleAllowlist("kubeadm:*", "monitoring"))
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{name: "system:controller:job-controller", want: true},
+		{name: "admin", want: true},
+		{name: "kubeadm:get-nodes", want: true},
+		{name: "monitoring", want: true},
+		{name: "monitoring-extra", want: false},
+		{name: "custom", labels: map[string]string{bootstrapLabel: "rbac-defaults"}, want: true},
+		{name: "custom", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.isIgnoredRole(tt.name, tt.labels); got != tt.want {
+				t.Errorf("isIgnoredRole(%q) = %t, want %t", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregationIsUsed(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []rbacv1.ClusterRole
+		bound   []string
+		ignored []string
+		order   []string
+		want    []string
+	}{
+		{
+			name: "aggregated into a bound role",
+			roles: []rbacv1.ClusterRole{
+				aggregated("monitoring", nil, "aggregate-to-monitoring"),
+				aggregated("metrics-reader", map[string]string{"aggregate-to-monitoring": "true"}),
+			},
+			bound: []string{"monitoring"},
+			order: []string{"metrics-reader", "monitoring"},
+			want:  []string{"metrics-reader", "monitoring"},
+		},
+		{
+			name: "aggregated into an unbound role",
+			roles: []rbacv1.ClusterRole{
+				aggregated("monitoring", nil, "aggregate-to-monitoring"),
+				aggregated("metrics-reader", map[string]string{"aggregate-to-monitoring": "true"}),
+			},
+			order: []string{"metrics-reader", "monitoring"},
+		},
+		{
+			name: "aggregated into an ignored role",
+			roles: []rbacv1.ClusterRole{
+				aggregated("view", nil, "aggregate-to-view"),
+				aggregated("crd-viewer", map[string]string{"aggregate-to-view": "true"}),
+			},
+			ignored: []string{"view"},
+			order:   []string{"crd-viewer"},
+			want:    []string{"crd-viewer"},
+		},
+		{
+			name: "transitive aggregation",
+			roles: []rbacv1.ClusterRole{
+				aggregated("top", nil, "to-top"),
+				aggregated("middle", map[string]string{"to-top": "true"}, "to-middle"),
+				aggregated("leaf", map[string]string{"to-middle": "true"}),
+			},
+			bound: []string{"top"},
+			order: []string{"leaf", "middle", "top"},
+			want:  []string{"leaf", "middle", "top"},
+		},
+		{
+			// x and y aggregate each other and z aggregates y. Resolving y
+			// first reaches x while y is still on the stack; x must not be
+			// memoized as unused before y finds z.
+			name: "cycle resolved from inside",
+			roles: []rbacv1.ClusterRole{
+				aggregated("x", map[string]string{"x": "true"}, "y"),
+				aggregated("y", map[string]string{"y": "true"}, "x"),
+				aggregated("z", nil, "y"),
+			},
+			bound: []string{"z"},
+			order: []string{"y", "x", "z"},
+			want:  []string{"x", "y", "z"},
+		},
+		{
+			name: "unbound cycle",
+			roles: []rbacv1.ClusterRole{
+				aggregated("x", map[string]string{"x": "true"}, "y"),
+				aggregated("y", map[string]string{"y": "true"}, "x"),
+			},
+			order: []string{"y", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toSet := func(names []string) map[string]bool {
+				set := make(map[string]bool)
+				for _, n := range names {
+					set[n] = true
+				}
+				return set
+			}
+			idx := newAggregationIndex(tt.roles, NewAnalyzer(nil, nil).logger)
+
+			var got []string
+			for _, name := range tt.order {
+				if idx.isUsed(name, toSet(tt.bound), toSet(tt.ignored)) {
+					got = append(got, name)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("used = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckUnusedRoles(t *testing.T) {
+	clusterRoles := []rbacv1.ClusterRole{
+		aggregated("monitoring", nil, "aggregate-to-monitoring"),
+		aggregated("metrics-reader", map[string]string{"aggregate-to-monitoring": "true"}),
+		aggregated("orphan", nil),
+		aggregated("edit", nil),
+		aggregated("kubeadm:get-nodes", nil),
+	}
+	roles := []rbacv1.Role{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "deployer"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "stale"}},
+	}
+	roleBindings := []rbacv1.RoleBinding{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "deployer"},
+		RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "deployer"},
+	}}
+
+	a := NewAnalyzer(nil, nil, WithUnusedRoleAllowlist("kubeadm:*"))
+	var got []string
+	for _, f := range a.checkUnusedRoles(clusterRoles, nil, roles, roleBindings, time.Now()) {
+		got = append(got, f.Resource)
+	}
+	sort.Strings(got)
+	want := []string{"ClusterRole/metrics-reader", "ClusterRole/monitoring", "ClusterRole/orphan", "Role/shop/stale"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkUnusedRoles() = %v, want %v", got, want)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kubecomply/kubecomply/pkg/k8s"
 	"github.com/kubecomply/kubecomply/pkg/scanner"
@@ -18,78 +20,153 @@ import (
 // Analyzer performs RBAC security analysis on a Kubernetes cluster.
 // It implements the scanner.Analyzer interface.
 type Analyzer struct {
-	client *k8s.Client
-	logger *slog.Logger
+	client              *k8s.Client
+	logger              *slog.Logger
+	unusedRoleAllowlist []string
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithUnusedRoleAllowlist adds role name patterns that are never reported as
+// unused. A trailing "*" matches any suffix (e.g. "kubeadm:*").
+func WithUnusedRoleAllowlist(patterns ...string) Option {
+	return func(a *Analyzer) {
+		a.unusedRoleAllowlist = append(a.unusedRoleAllowlist, patterns...)
+	}
 }
 
 // Name returns the analyzer name.
 func (a *Analyzer) Name() string { return "rbac" }
 
-// NewAnalyzer creates a new RBAC analyzer.
-func NewAnalyzer(client *k8s.Client, logger *slog.Logger) *Analyzer {
+// NewAnalyzer creates a new RBAC analyzer. Bootstrap roles are excluded from
+// unused-role detection by default.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Analyzer{
-		client: client,
-		logger: logger,
+	a := &Analyzer{
+		client:              client,
+		logger:              logger,
+		unusedRoleAllowlist: append([]string(nil), defaultUnusedRoleAllowlist...),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	return a
+}
+
+// inventory holds the RBAC objects and workloads fetched for a single analysis run.
+type inventory struct {
+	clusterRoles        []rbacv1.ClusterRole
+	clusterRoleBindings []rbacv1.ClusterRoleBinding
+	roles               []rbacv1.Role
+	roleBindings        []rbacv1.RoleBinding
+	serviceAccounts     []corev1.ServiceAccount
+	pods                []corev1.Pod
+	services            []corev1.Service
+	// tokenSecrets holds the metadata of legacy ServiceAccount token Secrets.
+	tokenSecrets []metav1.PartialObjectMetadata
 }
 
 // Analyze runs all RBAC checks and returns findings.
 func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
 	a.logger.Info("starting RBAC analysis")
 
-	clusterRoles, err := a.client.ListClusterRoles(ctx)
+	inv, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	// Check 1: Cluster-admin bindings.
+	findings = append(findings, a.checkClusterAdminBindings(inv.clusterRoleBindings, now)...)
+
+	// Check 2: Wildcard permissions.
+	findings = append(findings, a.checkWildcardPermissions(inv.clusterRoles, inv.roles, now)...)
+
+	// Check 3: Unused roles (roles with no bindings).
+	findings = append(findings, a.checkUnusedRoles(inv.clusterRoles, inv.clusterRoleBindings, inv.roles, inv.roleBindings, now)...)
+
+	// Check 4: Stale service accounts in bindings.
+	findings = append(findings, a.checkStaleServiceAccounts(inv.clusterRoleBindings, inv.roleBindings, now)...)
+
+	// Check 5: Roles that can escalate privileges.
+	findings = append(findings, a.checkPrivilegeEscalation(inv.clusterRoles, inv.roles, now)...)
+
+	// Check 6: Multi-step escalation paths to cluster-admin-equivalent power.
+	graph := newEscalationGraph(inv)
+	findings = append(findings, a.checkEscalationPaths(graph, now)...)
+
+	// Check 7: ServiceAccount hygiene (unused accounts, token mounts, legacy tokens, exposure).
+	findings = append(findings, a.checkServiceAccountHygiene(inv, graph, now)...)
+
+	a.logger.Info("RBAC analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect fetches cluster-scoped RBAC objects plus the namespaced roles,
+// bindings, ServiceAccounts, pods, Services and legacy token Secrets of the
+// given namespaces. A failed namespaced list is logged and the remaining
+// lists still run, so one forbidden resource does not hide the others.
+func (a *Analyzer) collect(ctx context.Context, namespaces []string) (*inventory, error) {
+	inv := &inventory{}
+
+	var err error
+	inv.clusterRoles, err = a.client.ListClusterRoles(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("listing cluster roles: %w", err)
 	}
 
-	clusterRoleBindings, err := a.client.ListClusterRoleBindings(ctx)
+	inv.clusterRoleBindings, err = a.client.ListClusterRoleBindings(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("listing cluster role bindings: %w", err)
 	}
 
-	var allRoles []rbacv1.Role
-	var allRoleBindings []rbacv1.RoleBinding
-
 	// Collect namespace-scoped roles and bindings.
 	for _, ns := range namespaces {
 		roles, err := a.client.ListRoles(ctx, ns)
 		if err != nil {
 			a.logger.Warn("failed to list roles", "namespace", ns, "error", err)
-			continue
 		}
-		allRoles = append(allRoles, roles...)
+		inv.roles = append(inv.roles, roles...)
 
 		bindings, err := a.client.ListRoleBindings(ctx, ns)
 		if err != nil {
 			a.logger.Warn("failed to list role bindings", "namespace", ns, "error", err)
-			continue
 		}
-		allRoleBindings = append(allRoleBindings, bindings...)
-	}
-
-	now := time.Now()
-	var findings []scanner.Finding
-
-	// Check 1: Cluster-admin bindings.
-	findings = append(findings, a.checkClusterAdminBindings(clusterRoleBindings, now)...)
+		inv.roleBindings = append(inv.roleBindings, bindings...)
 
-	// Check 2: Wildcard permissions.
-	findings = append(findings, a.checkWildcardPermissions(clusterRoles, allRoles, now)...)
+		serviceAccounts, err := a.client.ListServiceAccounts(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list service accounts", "namespace", ns, "error", err)
+		}
+		inv.serviceAccounts = append(inv.serviceAccounts, serviceAccounts...)
 
-	// Check 3: Unused roles (roles with no bindings).
-	findings = append(findings, a.checkUnusedRoles(clusterRoles, clusterRoleBindings, allRoles, allRoleBindings, now)...)
+		pods, err := a.client.ListPods(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list pods", "namespace", ns, "error", err)
+		}
+		inv.pods = append(inv.pods, pods...)
 
-	// Check 4: Stale service accounts in bindings.
-	findings = append(findings, a.checkStaleServiceAccounts(clusterRoleBindings, allRoleBindings, now)...)
+		services, err := a.client.ListServices(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list services", "namespace", ns, "error", err)
+		}
+		inv.services = append(inv.services, services...)
 
-	// Check 5: Roles that can escalate privileges.
-	findings = append(findings, a.checkPrivilegeEscalation(clusterRoles, allRoles, now)...)
+		// Only the metadata of token Secrets is needed: the ServiceAccount
+		// they belong to is an annotation.
+		tokenSecrets, err := a.client.ListSecretMetadata(ctx, ns, "type="+string(corev1.SecretTypeServiceAccountToken))
+		if err != nil {
+			a.logger.Warn("failed to list service account token secrets", "namespace", ns, "error", err)
+		}
+		inv.tokenSecrets = append(inv.tokenSecrets, tokenSecrets...)
+	}
 
-	a.logger.Info("RBAC analysis complete", "findings", len(findings))
-	return findings, nil
+	return inv, nil
 }
 
 // checkClusterAdminBindings identifies bindings to the cluster-admin role.
@@ -201,7 +278,9 @@ func (a *Analyzer) checkWildcardPermissions(clusterRoles []rbacv1.ClusterRole, r
 	return findings
 }
 
-// checkUnusedRoles finds roles that have no associated bindings.
+// checkUnusedRoles finds roles that have no associated bindings. ClusterRoles
+// that only feed an aggregated ClusterRole count as used when their aggregation
+// parent is bound, and bootstrap or allowlisted roles are ignored.
 func (a *Analyzer) checkUnusedRoles(
 	clusterRoles []rbacv1.ClusterRole,
 	clusterRoleBindings []rbacv1.ClusterRoleBinding,
@@ -225,23 +304,43 @@ func (a *Analyzer) checkUnusedRoles(
 		}
 	}
 
+	agg := newAggregationIndex(clusterRoles, a.logger)
+	ignored := make(map[string]bool)
 	for _, cr := range clusterRoles {
-		if strings.HasPrefix(cr.Name, "system:") {
+		if a.isIgnoredRole(cr.Name, cr.Labels) {
+			ignored[cr.Name] = true
+		}
+	}
+
+	for _, cr := range clusterRoles {
+		if ignored[cr.Name] {
 			continue
 		}
-		if !boundClusterRoles[cr.Name] {
-			findings = append(findings, scanner.Finding{
-				ID:          "RBAC-003",
-				Title:       "Unused ClusterRole",
-				Description: fmt.Sprintf("ClusterRole %q has no associated bindings", cr.Name),
-				Severity:    scanner.SeverityLow,
-				Status:      scanner.StatusWarning,
-				Category:    "rbac",
-				Resource:    fmt.Sprintf("ClusterRole/%s", cr.Name),
-				Remediation: "Remove unused ClusterRoles to reduce attack surface and simplify RBAC management.",
-				Timestamp:   now,
-			})
+		if agg.isUsed(cr.Name, boundClusterRoles, ignored) {
+			continue
 		}
+
+		description := fmt.Sprintf("ClusterRole %q has no associated bindings", cr.Name)
+		var details map[string]string
+		if parents := agg.parents[cr.Name]; len(parents) > 0 {
+			description = fmt.Sprintf("ClusterRole %q is only aggregated into %s, none of which are bound", cr.Name, strings.Join(parents, ", "))
+			details = map[string]string{
+				"aggregated_into": strings.Join(parents, ","),
+			}
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "RBAC-003",
+			Title:       "Unused ClusterRole",
+			Description: description,
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusWarning,
+			Category:    "rbac",
+			Resource:    fmt.Sprintf("ClusterRole/%s", cr.Name),
+			Remediation: "Remove unused ClusterRoles to reduce attack surface and simplify RBAC management.",
+			Details:     details,
+			Timestamp:   now,
+		})
 	}
 
 	// Build a set of bound namespaced Roles.
@@ -254,7 +353,7 @@ func (a *Analyzer) checkUnusedRoles(
 	}
 
 	for _, r := range roles {
-		if strings.HasPrefix(r.Name, "system:") {
+		if a.isIgnoredRole(r.Name, r.Labels) {
 			continue
 		}
 		key := fmt.Sprintf("%s/%s", r.Namespace, r.Name)
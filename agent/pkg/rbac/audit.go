@@ -0,0 +1,185 @@
+package rbac
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// auditEvent is the subset of an audit.k8s.io/v1 Event needed to reconstruct
+// the API requests a subject made.
+type auditEvent struct {
+	AuditID          string     `json:"auditID"`
+	Stage            string     `json:"stage"`
+	Verb             string     `json:"verb"`
+	User             auditUser  `json:"user"`
+	ImpersonatedUser *auditUser `json:"impersonatedUser,omitempty"`
+	ObjectRef        *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef,omitempty"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus,omitempty"`
+}
+
+// auditUser is the user information recorded in an audit event.
+type auditUser struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups"`
+}
+
+// responseStages are the audit stages recorded once a request has been
+// authorized. Long-running requests such as watch and exec log
+// ResponseStarted when the stream opens and ResponseComplete only when it
+// closes, which may fall outside the log.
+var responseStages = map[string]bool{
+	"":                 true,
+	"ResponseStarted":  true,
+	"ResponseComplete": true,
+}
+
+// unnamedVerbs are verbs whose authorization is never restricted by
+// resourceNames, so the object name of such a request is not recorded.
+var unnamedVerbs = map[string]bool{
+	"list":             true,
+	"watch":            true,
+	"deletecollection": true,
+}
+
+// APIRequest is a distinct resource request observed in an audit log.
+// An empty Namespace denotes a cluster-scoped request. Name is the object
+// requested, empty when the request was not for a single named object or
+// could not be restricted by resourceNames.
+type APIRequest struct {
+	Namespace string
+	APIGroup  string
+	Resource  string
+	Verb      string
+	Name      string
+}
+
+// AuditUsage is the set of distinct API requests a subject made.
+type AuditUsage struct {
+	Subject  string
+	Requests []APIRequest
+	// Groups are the groups the subject authenticated with, as recorded in
+	// the audit log.
+	Groups []string
+	// Events is the number of requests attributed to the subject.
+	Events int
+}
+
+// ParseAuditLog reads a JSON-lines Kubernetes audit log and returns the
+// distinct resource requests made by the given username, and the groups it
+// authenticated with. A request logged at several stages is counted once.
+// Requests rejected by the authorizer are ignored, as are non-resource URLs.
+func ParseAuditLog(r io.Reader, username string) (*AuditUsage, error) {
+	usage := &AuditUsage{Subject: username}
+	seen := make(map[APIRequest]bool)
+	seenEvents := make(map[string]bool)
+	groups := make(map[string]bool)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+
+		var ev auditEvent
+		if err := json.Unmarshal([]byte(text), &ev); err != nil {
+			return nil, fmt.Errorf("parsing audit event on line %d: %w", line, err)
+		}
+
+		actor := ev.User
+		if ev.ImpersonatedUser != nil && ev.ImpersonatedUser.Username != "" {
+			actor = *ev.ImpersonatedUser
+		}
+		if actor.Username != username || ev.ObjectRef == nil {
+			continue
+		}
+		if !responseStages[ev.Stage] {
+			continue
+		}
+		if ev.ResponseStatus != nil && ev.ResponseStatus.Code == 403 {
+			continue
+		}
+		for _, group := range actor.Groups {
+			groups[group] = true
+		}
+		if ev.AuditID != "" {
+			if seenEvents[ev.AuditID] {
+				continue
+			}
+			seenEvents[ev.AuditID] = true
+		}
+
+		usage.Events++
+		req := APIRequest{
+			Namespace: ev.ObjectRef.Namespace,
+			APIGroup:  ev.ObjectRef.APIGroup,
+			Resource:  ev.ObjectRef.Resource,
+			Verb:      ev.Verb,
+			Name:      ev.ObjectRef.Name,
+		}
+		if ev.ObjectRef.Subresource != "" {
+			req.Resource += "/" + ev.ObjectRef.Subresource
+		} else if ev.Verb == "create" {
+			// The name of a created object is not known when it is authorized.
+			req.Name = ""
+		}
+		if unnamedVerbs[ev.Verb] {
+			req.Name = ""
+		}
+		if !seen[req] {
+			seen[req] = true
+			usage.Requests = append(usage.Requests, req)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	if len(groups) > 0 {
+		usage.Groups = sortedKeys(groups)
+	}
+	sort.Slice(usage.Requests, func(i, j int) bool {
+		a, b := usage.Requests[i], usage.Requests[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.APIGroup != b.APIGroup {
+			return a.APIGroup < b.APIGroup
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Verb != b.Verb {
+			return a.Verb < b.Verb
+		}
+		return a.Name < b.Name
+	})
+
+	return usage, nil
+}
+
+// SubjectKeyForUsername converts an authenticated username (as it appears in
+// audit logs) into the subject notation used in RBAC findings.
+func SubjectKeyForUsername(username string) string {
+	if rest, ok := strings.CutPrefix(username, "system:serviceaccount:"); ok {
+		if ns, name, ok := strings.Cut(rest, ":"); ok {
+			return serviceAccountKey(ns, name)
+		}
+	}
+	return "User/" + username
+}
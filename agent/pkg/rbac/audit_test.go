@@ -0,0 +1,115 @@
+package rbac
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAuditLog(t *testing.T) {
+	const sa = "system:serviceaccount:shop:web"
+
+	tests := []struct {
+		name       string
+		log        string
+		wantReqs   []APIRequest
+		wantGroups []string
+		wantEvents int
+	}{
+		{
+			name: "response complete",
+			log:  `{"auditID":"1","stage":"ResponseComplete","verb":"get","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"configmaps","namespace":"shop","name":"app"},"responseStatus":{"code":200}}`,
+			wantReqs: []APIRequest{
+				{Namespace: "shop", Resource: "configmaps", Verb: "get", Name: "app"},
+			},
+			wantEvents: 1,
+		},
+		{
+			name: "other users, non-resource URLs and denied requests ignored",
+			log: strings.Join([]string{
+				`{"auditID":"1","stage":"ResponseComplete","verb":"get","user":{"username":"alice"},"objectRef":{"resource":"pods","namespace":"shop"}}`,
+				`{"auditID":"2","stage":"ResponseComplete","verb":"get","user":{"username":"system:serviceaccount:shop:web"}}`,
+				`{"auditID":"3","stage":"ResponseComplete","verb":"delete","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"pods","namespace":"shop","name":"x"},"responseStatus":{"code":403}}`,
+			}, "\n"),
+			wantEvents: 0,
+		},
+		{
+			name: "stages of one request counted once",
+			log: strings.Join([]string{
+				`{"auditID":"1","stage":"RequestReceived","verb":"list","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"pods","namespace":"shop"}}`,
+				`{"auditID":"1","stage":"ResponseComplete","verb":"list","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"pods","namespace":"shop"},"responseStatus":{"code":200}}`,
+			}, "\n"),
+			wantReqs: []APIRequest{
+				{Namespace: "shop", Resource: "pods", Verb: "list"},
+			},
+			wantEvents: 1,
+		},
+		{
+			name: "long-running requests logged at ResponseStarted",
+			log: strings.Join([]string{
+				`{"auditID":"1","stage":"ResponseStarted","verb":"watch","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"endpoints","namespace":"shop"},"responseStatus":{"code":200}}`,
+				`{"auditID":"2","stage":"ResponseStarted","verb":"create","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"pods","namespace":"shop","name":"web-0","subresource":"exec"},"responseStatus":{"code":101}}`,
+			}, "\n"),
+			wantReqs: []APIRequest{
+				{Namespace: "shop", Resource: "endpoints", Verb: "watch"},
+				{Namespace: "shop", Resource: "pods/exec", Verb: "create", Name: "web-0"},
+			},
+			wantEvents: 2,
+		},
+		{
+			name: "names dropped where resourceNames cannot apply",
+			log: strings.Join([]string{
+				`{"auditID":"1","stage":"ResponseComplete","verb":"create","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"configmaps","namespace":"shop","name":"new"}}`,
+				`{"auditID":"2","stage":"ResponseComplete","verb":"watch","user":{"username":"system:serviceaccount:shop:web"},"objectRef":{"resource":"configmaps","namespace":"shop","name":"app"}}`,
+			}, "\n"),
+			wantReqs: []APIRequest{
+				{Namespace: "shop", Resource: "configmaps", Verb: "create"},
+				{Namespace: "shop", Resource: "configmaps", Verb: "watch"},
+			},
+			wantEvents: 2,
+		},
+		{
+			name: "groups recorded",
+			log:  `{"auditID":"1","stage":"ResponseComplete","verb":"list","user":{"username":"system:serviceaccount:shop:web","groups":["system:serviceaccounts","system:serviceaccounts:shop","system:authenticated"]},"objectRef":{"resource":"pods","namespace":"shop"}}`,
+			wantReqs: []APIRequest{
+				{Namespace: "shop", Resource: "pods", Verb: "list"},
+			},
+			wantGroups: []string{"system:authenticated", "system:serviceaccounts", "system:serviceaccounts:shop"},
+			wantEvents: 1,
+		},
+		{
+			name: "impersonated user and groups",
+			log:  `{"auditID":"1","stage":"ResponseComplete","verb":"get","user":{"username":"admin","groups":["system:masters"]},"impersonatedUser":{"username":"system:serviceaccount:shop:web","groups":["ops"]},"objectRef":{"resource":"secrets","namespace":"shop","name":"db"}}`,
+			wantReqs: []APIRequest{
+				{Namespace: "shop", Resource: "secrets", Verb: "get", Name: "db"},
+			},
+			wantGroups: []string{"ops"},
+			wantEvents: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage, err := ParseAuditLog(strings.NewReader(tt.log), sa)
+			if err != nil {
+				t.Fatalf("ParseAuditLog() error = %v", err)
+			}
+			if !reflect.DeepEqual(usage.Requests, tt.wantReqs) {
+				t.Errorf("Requests = %+v, want %+v", usage.Requests, tt.wantReqs)
+			}
+			if !reflect.DeepEqual(usage.Groups, tt.wantGroups) {
+				t.Errorf("Groups = %v, want %v", usage.Groups, tt.wantGroups)
+			}
+			if usage.Events != tt.wantEvents {
+				t.Errorf("Events = %d, want %d", usage.Events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestParseAuditLogInvalidLine(t *testing.T) {
+	_, err := ParseAuditLog(strings.NewReader("{}\nnot json\n"), "alice")
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("ParseAuditLog() error = %v, want error on line 2", err)
+	}
+}
@@ -0,0 +1,83 @@
+package rbac
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// Binding is a ClusterRoleBinding or RoleBinding resolved to the rules of the
+// role it references.
+type Binding struct {
+	// Resource is "ClusterRoleBinding/<name>" or "RoleBinding/<namespace>/<name>".
+	Resource string
+	// Namespace is empty for ClusterRoleBindings, whose rules apply
+	// cluster-wide.
+	Namespace string
+	RoleRef   rbacv1.RoleRef
+	// Subjects are the binding's subjects. ServiceAccount subjects of a
+	// RoleBinding that omit the namespace default to the binding's.
+	Subjects []rbacv1.Subject
+	Rules    []rbacv1.PolicyRule
+}
+
+// ResolveBindings resolves ClusterRoleBindings and RoleBindings to the rules
+// of their roles. Bindings to roles that do not exist, and ClusterRoleBindings
+// to anything but a ClusterRole, are left out.
+func ResolveBindings(clusterRoles []rbacv1.ClusterRole, clusterRoleBindings []rbacv1.ClusterRoleBinding, roles []rbacv1.Role, roleBindings []rbacv1.RoleBinding) []Binding {
+	clusterRoleRules := make(map[string][]rbacv1.PolicyRule, len(clusterRoles))
+	for _, cr := range clusterRoles {
+		clusterRoleRules[cr.Name] = cr.Rules
+	}
+	roleRules := make(map[string][]rbacv1.PolicyRule, len(roles))
+	for _, r := range roles {
+		roleRules[r.Namespace+"/"+r.Name] = r.Rules
+	}
+
+	var bindings []Binding
+	for _, crb := range clusterRoleBindings {
+		if crb.RoleRef.Kind != "ClusterRole" {
+			continue
+		}
+		rules, ok := clusterRoleRules[crb.RoleRef.Name]
+		if !ok {
+			continue
+		}
+		bindings = append(bindings, Binding{
+			Resource: fmt.Sprintf("ClusterRoleBinding/%s", crb.Name),
+			RoleRef:  crb.RoleRef,
+			Subjects: crb.Subjects,
+			Rules:    rules,
+		})
+	}
+
+	for _, rb := range roleBindings {
+		var rules []rbacv1.PolicyRule
+		var ok bool
+		switch rb.RoleRef.Kind {
+		case "ClusterRole":
+			rules, ok = clusterRoleRules[rb.RoleRef.Name]
+		case "Role":
+			rules, ok = roleRules[rb.Namespace+"/"+rb.RoleRef.Name]
+		}
+		if !ok {
+			continue
+		}
+		subjects := make([]rbacv1.Subject, len(rb.Subjects))
+		for i, subject := range rb.Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == "" {
+				subject.Namespace = rb.Namespace
+			}
+			subjects[i] = subject
+		}
+		bindings = append(bindings, Binding{
+			Resource:  fmt.Sprintf("RoleBinding/%s/%s", rb.Namespace, rb.Name),
+			Namespace: rb.Namespace,
+			RoleRef:   rb.RoleRef,
+			Subjects:  subjects,
+			Rules:     rules,
+		})
+	}
+
+	return bindings
+}
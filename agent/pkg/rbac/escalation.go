@@ -0,0 +1,419 @@
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// clusterAdminNode is the terminal node of the escalation graph. Reaching it
+// means the starting subject can obtain cluster-admin-equivalent power.
+const clusterAdminNode = "cluster-admin"
+
+// maxEscalationHops bounds the length of reported escalation chains.
+const maxEscalationHops = 6
+
+// systemNamespaces are skipped as escalation starting points because their
+// ServiceAccounts belong to cluster components that are expected to be powerful.
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// workloadResources are the resources whose creation lets a subject run a pod
+// under an arbitrary ServiceAccount of the namespace.
+var workloadResources = []struct{ group, resource string }{
+	{"", "pods"},
+	{"", "replicationcontrollers"},
+	{"apps", "deployments"},
+	{"apps", "daemonsets"},
+	{"apps", "statefulsets"},
+	{"apps", "replicasets"},
+	{"batch", "jobs"},
+	{"batch", "cronjobs"},
+}
+
+// grant is a single policy rule held by a subject. An empty namespace means
+// the rule was conferred by a ClusterRoleBinding and applies cluster-wide.
+type grant struct {
+	rule      rbacv1.PolicyRule
+	namespace string
+	via       string
+}
+
+// escalationStep is a directed edge in the escalation graph.
+type escalationStep struct {
+	from   string
+	to     string
+	reason string
+	// direct marks an edge that only restates permissions the subject already
+	// holds (already reported by RBAC-001/RBAC-002).
+	direct bool
+}
+
+// escalationGraph links subjects to the subjects (or cluster-admin) they can
+// become by abusing the permissions they hold.
+type escalationGraph struct {
+	grants          map[string][]grant
+	serviceAccounts map[string][]string // namespace -> ServiceAccount node keys
+	tokenAccounts   map[string][]string // namespace -> ServiceAccounts with legacy token Secrets
+	pods            map[string][]corev1.Pod
+	namespaces      []string
+	starts          []string
+	steps           map[string][]escalationStep
+}
+
+// subjectKey returns the graph node key for an RBAC subject. ServiceAccount
+// subjects without a namespace inherit defaultNamespace.
+func subjectKey(subject rbacv1.Subject, defaultNamespace string) string {
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		ns := subject.Namespace
+		if ns == "" {
+			ns = defaultNamespace
+		}
+		return serviceAccountKey(ns, subject.Name)
+	}
+	return fmt.Sprintf("%s/%s", subject.Kind, subject.Name)
+}
+
+// serviceAccountKey returns the graph node key for a ServiceAccount.
+func serviceAccountKey(namespace, name string) string {
+	return fmt.Sprintf("ServiceAccount/%s/%s", namespace, name)
+}
+
+// newEscalationGraph builds the escalation graph from an RBAC inventory.
+func newEscalationGraph(inv *inventory) *escalationGraph {
+	g := &escalationGraph{
+		grants:          make(map[string][]grant),
+		serviceAccounts: make(map[string][]string),
+		tokenAccounts:   make(map[string][]string),
+		pods:            make(map[string][]corev1.Pod),
+		steps:           make(map[string][]escalationStep),
+	}
+
+	startSet := make(map[string]bool)
+	addStart := func(key string, subject rbacv1.Subject) {
+		if strings.HasPrefix(subject.Name, "system:") {
+			return
+		}
+		if subject.Kind == rbacv1.ServiceAccountKind && systemNamespaces[subject.Namespace] {
+			return
+		}
+		startSet[key] = true
+	}
+
+	for _, b := range ResolveBindings(inv.clusterRoles, inv.clusterRoleBindings, inv.roles, inv.roleBindings) {
+		via := fmt.Sprintf("%s (%s/%s)", b.Resource, b.RoleRef.Kind, b.RoleRef.Name)
+		for _, subject := range b.Subjects {
+			key := subjectKey(subject, b.Namespace)
+			for _, rule := range b.Rules {
+				g.grants[key] = append(g.grants[key], grant{rule: rule, namespace: b.Namespace, via: via})
+			}
+			addStart(key, subject)
+		}
+	}
+
+	nsSet := make(map[string]bool)
+	for _, sa := range inv.serviceAccounts {
+		key := serviceAccountKey(sa.Namespace, sa.Name)
+		g.serviceAccounts[sa.Namespace] = append(g.serviceAccounts[sa.Namespace], key)
+		nsSet[sa.Namespace] = true
+		addStart(key, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: sa.Namespace, Name: sa.Name})
+	}
+	for _, secret := range inv.tokenSecrets {
+		saName := secret.Annotations[corev1.ServiceAccountNameKey]
+		g.tokenAccounts[secret.Namespace] = append(g.tokenAccounts[secret.Namespace], serviceAccountKey(secret.Namespace, saName))
+	}
+	for _, pod := range inv.pods {
+		g.pods[pod.Namespace] = append(g.pods[pod.Namespace], pod)
+		nsSet[pod.Namespace] = true
+	}
+
+	for ns := range nsSet {
+		g.namespaces = append(g.namespaces, ns)
+	}
+	sort.Strings(g.namespaces)
+
+	for key := range startSet {
+		g.starts = append(g.starts, key)
+	}
+	sort.Strings(g.starts)
+
+	return g
+}
+
+// implicitGroups returns the groups Kubernetes assigns to every
+// authenticated ServiceAccount or user.
+func implicitGroups(key string) []string {
+	switch {
+	case strings.HasPrefix(key, "ServiceAccount/"):
+		namespace := strings.SplitN(key, "/", 3)[1]
+		return []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"}
+	case strings.HasPrefix(key, "User/"):
+		return []string{"system:authenticated"}
+	}
+	return nil
+}
+
+// effectiveGrants returns the grants held by a node, including those inherited
+// through the implicit groups Kubernetes assigns to ServiceAccounts and users.
+func (g *escalationGraph) effectiveGrants(key string) []grant {
+	grants := append([]grant(nil), g.grants[key]...)
+	for _, group := range implicitGroups(key) {
+		grants = append(grants, g.grants["Group/"+group]...)
+	}
+	return grants
+}
+
+// serviceAccountGrants returns the grants bound to a ServiceAccount node
+// directly or through the system:serviceaccounts and
+// system:serviceaccounts:<namespace> groups. Grants to system:authenticated,
+// which every identity holds, are left out.
+func (g *escalationGraph) serviceAccountGrants(key string) []grant {
+	namespace := strings.SplitN(key, "/", 3)[1]
+	grants := append([]grant(nil), g.grants[key]...)
+	grants = append(grants, g.grants["Group/system:serviceaccounts"]...)
+	grants = append(grants, g.grants["Group/system:serviceaccounts:"+namespace]...)
+	return grants
+}
+
+// can returns the first grant that allows any of the verbs on the resource in
+// the namespace. An empty namespace requires a cluster-wide grant.
+func can(grants []grant, namespace, apiGroup, resource string, verbs ...string) (grant, bool) {
+	for _, gr := range grants {
+		if gr.namespace != "" && gr.namespace != namespace {
+			continue
+		}
+		// Rules restricted to named objects are not treated as broad grants.
+		if len(gr.rule.ResourceNames) > 0 {
+			continue
+		}
+		if !MatchesAny(gr.rule.APIGroups, apiGroup) || !MatchesAny(gr.rule.Resources, resource) {
+			continue
+		}
+		for _, verb := range verbs {
+			if MatchesAny(gr.rule.Verbs, verb) {
+				return gr, true
+			}
+		}
+	}
+	return grant{}, false
+}
+
+// MatchesAny reports whether items contains want or the wildcard "*".
+func MatchesAny(items []string, want string) bool {
+	for _, item := range items {
+		if item == "*" || item == want {
+			return true
+		}
+	}
+	return false
+}
+
+// stepsFrom returns the escalation edges leaving a node. Results are memoized.
+func (g *escalationGraph) stepsFrom(key string) []escalationStep {
+	if steps, ok := g.steps[key]; ok {
+		return steps
+	}
+
+	grants := g.effectiveGrants(key)
+	var steps []escalationStep
+	seen := make(map[string]bool)
+	add := func(to, reason string, direct bool) {
+		if to == key || seen[to] {
+			return
+		}
+		seen[to] = true
+		steps = append(steps, escalationStep{from: key, to: to, reason: reason, direct: direct})
+	}
+
+	// Cluster-wide paths straight to cluster-admin-equivalent power.
+	if gr, ok := can(grants, "", "*", "*", "*"); ok {
+		add(clusterAdminNode, "holds wildcard permissions via "+gr.via, true)
+	}
+	if gr, ok := can(grants, "", "", "groups", "impersonate"); ok {
+		add(clusterAdminNode, "can impersonate any group (e.g. system:masters) via "+gr.via, false)
+	}
+	if gr, ok := can(grants, "", "", "users", "impersonate"); ok {
+		add(clusterAdminNode, "can impersonate any user via "+gr.via, false)
+	}
+	if gr, ok := can(grants, "", "rbac.authorization.k8s.io", "clusterrolebindings", "create", "update", "patch"); ok {
+		if _, bind := can(grants, "", "rbac.authorization.k8s.io", "clusterroles", "bind"); bind {
+			add(clusterAdminNode, "can bind any ClusterRole to itself cluster-wide via "+gr.via, false)
+		}
+	}
+	if gr, ok := can(grants, "", "rbac.authorization.k8s.io", "clusterroles", "escalate"); ok {
+		if _, update := can(grants, "", "rbac.authorization.k8s.io", "clusterroles", "update", "patch"); update {
+			add(clusterAdminNode, "can escalate ClusterRole permissions via "+gr.via, false)
+		}
+	}
+
+	// Namespaced paths to other ServiceAccounts.
+	for _, ns := range g.namespaces {
+		if gr, ok := can(grants, ns, "", "serviceaccounts", "impersonate"); ok {
+			for _, sa := range g.serviceAccounts[ns] {
+				add(sa, fmt.Sprintf("can impersonate ServiceAccounts in %s via %s", ns, gr.via), false)
+			}
+		}
+
+		for _, wr := range workloadResources {
+			gr, ok := can(grants, ns, wr.group, wr.resource, "create")
+			if !ok {
+				continue
+			}
+			for _, sa := range g.serviceAccounts[ns] {
+				add(sa, fmt.Sprintf("can create %s in %s and mount the ServiceAccount token via %s", wr.resource, ns, gr.via), false)
+			}
+			break
+		}
+
+		if gr, ok := can(grants, ns, "", "serviceaccounts/token", "create"); ok {
+			for _, sa := range g.serviceAccounts[ns] {
+				add(sa, fmt.Sprintf("can request ServiceAccount tokens in %s via %s", ns, gr.via), false)
+			}
+		}
+
+		if gr, ok := can(grants, ns, "", "secrets", "get", "list"); ok {
+			for _, sa := range g.tokenAccounts[ns] {
+				add(sa, fmt.Sprintf("can read legacy ServiceAccount token Secrets in %s via %s", ns, gr.via), false)
+			}
+		}
+
+		if gr, ok := can(grants, ns, "", "pods/exec", "create"); ok {
+			for _, pod := range g.pods[ns] {
+				if pod.Spec.AutomountServiceAccountToken != nil && !*pod.Spec.AutomountServiceAccountToken {
+					continue
+				}
+				saName := pod.Spec.ServiceAccountName
+				if saName == "" {
+					saName = "default"
+				}
+				add(serviceAccountKey(ns, saName), fmt.Sprintf("can exec into Pod/%s/%s running as ServiceAccount %q via %s", ns, pod.Name, saName, gr.via), false)
+			}
+		}
+
+		if gr, ok := can(grants, ns, "rbac.authorization.k8s.io", "rolebindings", "create", "update", "patch"); ok {
+			if _, bind := can(grants, ns, "rbac.authorization.k8s.io", "clusterroles", "bind"); bind {
+				for _, sa := range g.serviceAccounts[ns] {
+					add(sa, fmt.Sprintf("can bind any role in %s and run workloads as its ServiceAccounts via %s", ns, gr.via), false)
+				}
+			}
+		}
+	}
+
+	g.steps[key] = steps
+	return steps
+}
+
+// shortestPath returns the shortest chain of steps from start to the
+// cluster-admin node, or nil if none exists within maxEscalationHops.
+func (g *escalationGraph) shortestPath(start string) []escalationStep {
+	parent := map[string]escalationStep{}
+	visited := map[string]bool{start: true}
+	depth := map[string]int{start: 0}
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if depth[node] >= maxEscalationHops {
+			continue
+		}
+
+		for _, step := range g.stepsFrom(node) {
+			if visited[step.to] {
+				continue
+			}
+			visited[step.to] = true
+			parent[step.to] = step
+			depth[step.to] = depth[node] + 1
+
+			if step.to == clusterAdminNode {
+				var path []escalationStep
+				for cur := clusterAdminNode; cur != start; cur = parent[cur].from {
+					path = append([]escalationStep{parent[cur]}, path...)
+				}
+				return path
+			}
+			queue = append(queue, step.to)
+		}
+	}
+
+	return nil
+}
+
+// formatChain renders an escalation path as a single human-readable line.
+func formatChain(path []escalationStep) string {
+	var b strings.Builder
+	b.WriteString(path[0].from)
+	for _, step := range path {
+		fmt.Fprintf(&b, " -[%s]-> %s", step.reason, step.to)
+	}
+	return b.String()
+}
+
+// checkEscalationPaths searches the subject/role/ServiceAccount/workload graph
+// for chains that lead to cluster-admin-equivalent power.
+func (a *Analyzer) checkEscalationPaths(g *escalationGraph, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, start := range g.starts {
+		path := g.shortestPath(start)
+		if len(path) == 0 {
+			continue
+		}
+		// Subjects that simply hold wildcard permissions are covered by RBAC-001/002.
+		if len(path) == 1 && path[0].direct {
+			continue
+		}
+
+		details := map[string]string{
+			"subject": start,
+			"chain":   formatChain(path),
+			"hops":    fmt.Sprintf("%d", len(path)),
+		}
+		for i, step := range path {
+			details[fmt.Sprintf("step_%d", i+1)] = fmt.Sprintf("%s %s", step.from, step.reason)
+		}
+
+		var namespace string
+		if strings.HasPrefix(start, "ServiceAccount/") {
+			namespace = strings.SplitN(start, "/", 3)[1]
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "RBAC-006",
+			Title:       "Privilege escalation path to cluster-admin",
+			Description: fmt.Sprintf("%s can obtain cluster-admin-equivalent privileges in %d step(s): %s", start, len(path), formatChain(path)),
+			Severity:    scanner.SeverityCritical,
+			Status:      scanner.StatusFail,
+			Category:    "rbac",
+			Resource:    start,
+			Namespace:   namespace,
+			Remediation: "Break the chain at its weakest link: remove pod creation, exec, Secret read, impersonation or binding permissions the subject does not need, and avoid binding powerful roles to ServiceAccounts in namespaces where others can run workloads.",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, scanner.Finding{
+			ID:          "RBAC-006",
+			Title:       "No privilege escalation paths to cluster-admin",
+			Description: "No subject can reach cluster-admin-equivalent privileges through multi-step escalation.",
+			Severity:    scanner.SeverityCritical,
+			Status:      scanner.StatusPass,
+			Category:    "rbac",
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
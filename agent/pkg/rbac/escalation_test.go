@@ -0,0 +1,309 @@
+package rbac
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// rule returns a PolicyRule for one API group.
+func rule(group string, resources []string, verbs ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: resources, Verbs: verbs}
+}
+
+// clusterRole returns a ClusterRole with the given rules.
+func clusterRole(name string, rules ...rbacv1.PolicyRule) rbacv1.ClusterRole {
+	return rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}, Rules: rules}
+}
+
+// clusterRoleBinding binds a ClusterRole to subjects cluster-wide.
+func clusterRoleBinding(name, role string, subjects ...rbacv1.Subject) rbacv1.ClusterRoleBinding {
+	return rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+		Subjects:   subjects,
+	}
+}
+
+// roleBinding binds a ClusterRole to subjects in a namespace.
+func roleBinding(namespace, name, role string, subjects ...rbacv1.Subject) rbacv1.RoleBinding {
+	return rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+		Subjects:   subjects,
+	}
+}
+
+// serviceAccount returns a ServiceAccount.
+func serviceAccount(namespace, name string) corev1.ServiceAccount {
+	return corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+var (
+	alice = rbacv1.Subject{Kind: rbacv1.UserKind, Name: "alice"}
+	wild  = rule("*", []string{"*"}, "*")
+)
+
+func TestStepsFrom(t *testing.T) {
+	noAutomount := false
+
+	tests := []struct {
+		name    string
+		rules   []rbacv1.PolicyRule
+		cluster bool // bind cluster-wide instead of in namespace shop
+		want    []string
+	}{
+		{
+			name:    "wildcard",
+			rules:   []rbacv1.PolicyRule{wild},
+			cluster: true,
+			want:    []string{"ServiceAccount/dev/app", "ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web", "cluster-admin (direct)"},
+		},
+		{
+			name:    "impersonate groups",
+			rules:   []rbacv1.PolicyRule{rule("", []string{"groups"}, "impersonate")},
+			cluster: true,
+			want:    []string{"cluster-admin"},
+		},
+		{
+			name:    "impersonate users",
+			rules:   []rbacv1.PolicyRule{rule("", []string{"users"}, "impersonate")},
+			cluster: true,
+			want:    []string{"cluster-admin"},
+		},
+		{
+			name: "bind clusterroles",
+			rules: []rbacv1.PolicyRule{
+				rule("rbac.authorization.k8s.io", []string{"clusterrolebindings"}, "create"),
+				rule("rbac.authorization.k8s.io", []string{"clusterroles"}, "bind"),
+			},
+			cluster: true,
+			want:    []string{"cluster-admin"},
+		},
+		{
+			name:    "create clusterrolebindings without bind",
+			rules:   []rbacv1.PolicyRule{rule("rbac.authorization.k8s.io", []string{"clusterrolebindings"}, "create")},
+			cluster: true,
+		},
+		{
+			name:    "escalate clusterroles",
+			rules:   []rbacv1.PolicyRule{rule("rbac.authorization.k8s.io", []string{"clusterroles"}, "escalate", "update")},
+			cluster: true,
+			want:    []string{"cluster-admin"},
+		},
+		{
+			name:    "escalate without update",
+			rules:   []rbacv1.PolicyRule{rule("rbac.authorization.k8s.io", []string{"clusterroles"}, "escalate")},
+			cluster: true,
+		},
+		{
+			name:  "namespaced wildcard",
+			rules: []rbacv1.PolicyRule{wild},
+			want:  []string{"ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web"},
+		},
+		{
+			name:  "impersonate serviceaccounts",
+			rules: []rbacv1.PolicyRule{rule("", []string{"serviceaccounts"}, "impersonate")},
+			want:  []string{"ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web"},
+		},
+		{
+			name:  "create pods",
+			rules: []rbacv1.PolicyRule{rule("", []string{"pods"}, "create")},
+			want:  []string{"ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web"},
+		},
+		{
+			name:  "create deployments",
+			rules: []rbacv1.PolicyRule{rule("apps", []string{"deployments"}, "create")},
+			want:  []string{"ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web"},
+		},
+		{
+			name:  "create pods restricted by name",
+			rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"create"}, ResourceNames: []string{"debug"}}},
+		},
+		{
+			name:  "request tokens",
+			rules: []rbacv1.PolicyRule{rule("", []string{"serviceaccounts/token"}, "create")},
+			want:  []string{"ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web"},
+		},
+		{
+			name:  "read token secrets",
+			rules: []rbacv1.PolicyRule{rule("", []string{"secrets"}, "get")},
+			want:  []string{"ServiceAccount/shop/runner"},
+		},
+		{
+			// Pod api automounts web's token; pod batch opts out.
+			name:  "exec into pods",
+			rules: []rbacv1.PolicyRule{rule("", []string{"pods/exec"}, "create")},
+			want:  []string{"ServiceAccount/shop/web"},
+		},
+		{
+			name: "bind roles in namespace",
+			rules: []rbacv1.PolicyRule{
+				rule("rbac.authorization.k8s.io", []string{"rolebindings"}, "create"),
+				rule("rbac.authorization.k8s.io", []string{"clusterroles"}, "bind"),
+			},
+			want: []string{"ServiceAccount/shop/app", "ServiceAccount/shop/runner", "ServiceAccount/shop/web"},
+		},
+		{
+			name:  "read configmaps",
+			rules: []rbacv1.PolicyRule{rule("", []string{"configmaps"}, "get", "list")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &inventory{
+				clusterRoles:    []rbacv1.ClusterRole{clusterRole("granted", tt.rules...)},
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("shop", "app"), serviceAccount("shop", "web"), serviceAccount("shop", "runner"), serviceAccount("dev", "app")},
+				pods: []corev1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"}, Spec: corev1.PodSpec{ServiceAccountName: "web"}},
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "batch"}, Spec: corev1.PodSpec{ServiceAccountName: "app", AutomountServiceAccountToken: &noAutomount}},
+				},
+				tokenSecrets: []metav1.PartialObjectMetadata{{ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "shop",
+					Name:        "runner-token",
+					Annotations: map[string]string{corev1.ServiceAccountNameKey: "runner"},
+				}}},
+			}
+			if tt.cluster {
+				inv.clusterRoleBindings = []rbacv1.ClusterRoleBinding{clusterRoleBinding("alice", "granted", alice)}
+			} else {
+				inv.roleBindings = []rbacv1.RoleBinding{roleBinding("shop", "alice", "granted", alice)}
+			}
+
+			var got []string
+			for _, step := range newEscalationGraph(inv).stepsFrom("User/alice") {
+				to := step.to
+				if step.direct {
+					to += " (direct)"
+				}
+				got = append(got, to)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stepsFrom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEscalationPaths(t *testing.T) {
+	admin := clusterRole("cluster-admin", wild)
+	podCreator := clusterRole("pod-creator", rule("", []string{"pods"}, "create"))
+	ops := serviceAccount("shop", "ops")
+	opsSubject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "shop", Name: "ops"}
+
+	tests := []struct {
+		name string
+		inv  *inventory
+		want []string
+	}{
+		{
+			// Already reported by RBAC-001/RBAC-002.
+			name: "single direct wildcard",
+			inv: &inventory{
+				clusterRoles:        []rbacv1.ClusterRole{admin},
+				clusterRoleBindings: []rbacv1.ClusterRoleBinding{clusterRoleBinding("alice-admin", "cluster-admin", alice)},
+			},
+			want: []string{"pass"},
+		},
+		{
+			name: "pod creation with a powerful ServiceAccount",
+			inv: &inventory{
+				clusterRoles:        []rbacv1.ClusterRole{admin, podCreator},
+				clusterRoleBindings: []rbacv1.ClusterRoleBinding{clusterRoleBinding("ops-admin", "cluster-admin", opsSubject)},
+				roleBindings:        []rbacv1.RoleBinding{roleBinding("shop", "alice-pods", "pod-creator", alice)},
+				serviceAccounts:     []corev1.ServiceAccount{ops},
+			},
+			want: []string{"User/alice 2"},
+		},
+		{
+			name: "through the namespace ServiceAccount group",
+			inv: &inventory{
+				clusterRoles:        []rbacv1.ClusterRole{admin, podCreator},
+				clusterRoleBindings: []rbacv1.ClusterRoleBinding{clusterRoleBinding("ops-admin", "cluster-admin", opsSubject)},
+				roleBindings: []rbacv1.RoleBinding{roleBinding("shop", "sa-pods", "pod-creator",
+					rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:shop"})},
+				serviceAccounts: []corev1.ServiceAccount{ops, serviceAccount("shop", "web")},
+			},
+			want: []string{"ServiceAccount/shop/web 2"},
+		},
+		{
+			name: "system namespace and system subjects are not starting points",
+			inv: &inventory{
+				clusterRoles: []rbacv1.ClusterRole{admin, podCreator},
+				clusterRoleBindings: []rbacv1.ClusterRoleBinding{clusterRoleBinding("ops-admin", "cluster-admin",
+					rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "kube-system", Name: "ops"})},
+				roleBindings: []rbacv1.RoleBinding{roleBinding("kube-system", "pods", "pod-creator",
+					rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "kube-system", Name: "deployer"},
+					rbacv1.Subject{Kind: rbacv1.UserKind, Name: "system:kube-controller-manager"})},
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("kube-system", "ops"), serviceAccount("kube-system", "deployer")},
+			},
+			want: []string{"pass"},
+		},
+		{
+			name: "pod creation in another namespace",
+			inv: &inventory{
+				clusterRoles:        []rbacv1.ClusterRole{admin, podCreator},
+				clusterRoleBindings: []rbacv1.ClusterRoleBinding{clusterRoleBinding("ops-admin", "cluster-admin", opsSubject)},
+				roleBindings:        []rbacv1.RoleBinding{roleBinding("dev", "alice-pods", "pod-creator", alice)},
+				serviceAccounts:     []corev1.ServiceAccount{ops, serviceAccount("dev", "default")},
+			},
+			want: []string{"pass"},
+		},
+	}
+
+	a := NewAnalyzer(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range a.checkEscalationPaths(newEscalationGraph(tt.inv), time.Now()) {
+				if f.Status == scanner.StatusPass {
+					got = append(got, "pass")
+					continue
+				}
+				got = append(got, f.Resource+" "+f.Details["hops"])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkEscalationPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortestPathHopLimit(t *testing.T) {
+	// alice -> sa0 -> sa1 -> ... each ServiceAccount can impersonate the next
+	// namespace's, and the last is cluster-admin.
+	inv := &inventory{clusterRoles: []rbacv1.ClusterRole{
+		clusterRole("cluster-admin", wild),
+		clusterRole("impersonator", rule("", []string{"serviceaccounts"}, "impersonate")),
+	}}
+	chain := func(length int) *inventory {
+		inv := *inv
+		inv.roleBindings = nil
+		inv.serviceAccounts = nil
+		prev := alice
+		for i := 0; i < length; i++ {
+			ns := string(rune('a' + i))
+			inv.serviceAccounts = append(inv.serviceAccounts, serviceAccount(ns, "sa"))
+			inv.roleBindings = append(inv.roleBindings, roleBinding(ns, "impersonate", "impersonator", prev))
+			prev = rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: ns, Name: "sa"}
+		}
+		inv.clusterRoleBindings = []rbacv1.ClusterRoleBinding{clusterRoleBinding("last", "cluster-admin", prev)}
+		return &inv
+	}
+
+	if path := newEscalationGraph(chain(maxEscalationHops - 1)).shortestPath("User/alice"); len(path) != maxEscalationHops {
+		t.Errorf("shortestPath() has %d steps, want %d", len(path), maxEscalationHops)
+	}
+	if path := newEscalationGraph(chain(maxEscalationHops)).shortestPath("User/alice"); path != nil {
+		t.Errorf("shortestPath() = %d steps, want none beyond %d hops", len(path), maxEscalationHops)
+	}
+}
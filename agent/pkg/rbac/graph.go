@@ -0,0 +1,174 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/graph"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// highlightedChecks are the finding IDs whose resources are highlighted in
+// the exported RBAC graph.
+var highlightedChecks = map[string]bool{
+	"RBAC-001": true,
+	"RBAC-002": true,
+	"RBAC-005": true,
+}
+
+// Graph builds the subject -> binding -> role -> rule graph for the given
+// namespaces from the same listings Analyze uses. Nodes involved in
+// cluster-admin, wildcard and privilege-escalation findings are highlighted.
+func (a *Analyzer) Graph(ctx context.Context, namespaces []string) (*graph.Graph, error) {
+	inv, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+	findings = append(findings, a.checkClusterAdminBindings(inv.clusterRoleBindings, now)...)
+	findings = append(findings, a.checkWildcardPermissions(inv.clusterRoles, inv.roles, now)...)
+	findings = append(findings, a.checkPrivilegeEscalation(inv.clusterRoles, inv.roles, now)...)
+
+	return buildRBACGraph(inv, findings), nil
+}
+
+// buildRBACGraph assembles the RBAC graph and marks nodes referenced by findings.
+func buildRBACGraph(inv *inventory, findings []scanner.Finding) *graph.Graph {
+	g := graph.New("rbac")
+
+	clusterRoles := make(map[string]rbacv1.ClusterRole, len(inv.clusterRoles))
+	for _, cr := range inv.clusterRoles {
+		clusterRoles[cr.Name] = cr
+	}
+	roles := make(map[string]rbacv1.Role, len(inv.roles))
+	for _, r := range inv.roles {
+		roles[r.Namespace+"/"+r.Name] = r
+	}
+
+	addRole := func(kind, namespace, name string) string {
+		ref := fmt.Sprintf("%s/%s", kind, name)
+		var rules []rbacv1.PolicyRule
+		if kind == "Role" {
+			ref = fmt.Sprintf("Role/%s/%s", namespace, name)
+			rules = roles[namespace+"/"+name].Rules
+		} else {
+			namespace = ""
+			rules = clusterRoles[name].Rules
+		}
+
+		id := "role:" + ref
+		if g.Node(id) != nil {
+			return id
+		}
+		g.AddNode(graph.Node{ID: id, Label: ref, Kind: "role", Group: namespace})
+		for i, rule := range rules {
+			ruleID := fmt.Sprintf("%s#%d", id, i)
+			g.AddNode(graph.Node{ID: ruleID, Label: formatRule(rule), Kind: "rule", Group: namespace, Shape: "note"})
+			g.AddEdge(id, ruleID, "")
+		}
+		return id
+	}
+
+	addSubject := func(subject rbacv1.Subject, bindingNamespace string) string {
+		key := subjectKey(subject, bindingNamespace)
+		group := ""
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			group = strings.SplitN(key, "/", 3)[1]
+		}
+		id := "subject:" + key
+		g.AddNode(graph.Node{ID: id, Label: key, Kind: "subject", Group: group, Shape: "ellipse"})
+		return id
+	}
+
+	for _, crb := range inv.clusterRoleBindings {
+		bindingID := "binding:ClusterRoleBinding/" + crb.Name
+		g.AddNode(graph.Node{ID: bindingID, Label: "ClusterRoleBinding/" + crb.Name, Kind: "binding"})
+		for _, subject := range crb.Subjects {
+			g.AddEdge(addSubject(subject, ""), bindingID, "")
+		}
+		g.AddEdge(bindingID, addRole(crb.RoleRef.Kind, "", crb.RoleRef.Name), "")
+	}
+
+	for _, rb := range inv.roleBindings {
+		ref := fmt.Sprintf("RoleBinding/%s/%s", rb.Namespace, rb.Name)
+		bindingID := "binding:" + ref
+		g.AddNode(graph.Node{ID: bindingID, Label: ref, Kind: "binding", Group: rb.Namespace})
+		for _, subject := range rb.Subjects {
+			g.AddEdge(addSubject(subject, rb.Namespace), bindingID, "")
+		}
+		g.AddEdge(bindingID, addRole(rb.RoleRef.Kind, rb.Namespace, rb.RoleRef.Name), "")
+	}
+
+	for _, f := range findings {
+		if !highlightedChecks[f.ID] || f.Status == scanner.StatusPass {
+			continue
+		}
+
+		var ids []string
+		switch {
+		case strings.HasPrefix(f.Resource, "ClusterRoleBinding/"):
+			ids = append(ids, "binding:"+f.Resource)
+			if f.Details != nil {
+				subject := rbacv1.Subject{
+					Kind:      f.Details["subject_kind"],
+					Name:      f.Details["subject_name"],
+					Namespace: f.Details["subject_namespace"],
+				}
+				ids = append(ids, "subject:"+subjectKey(subject, ""))
+			}
+		case strings.HasPrefix(f.Resource, "ClusterRole/"):
+			ids = append(ids, "role:"+f.Resource)
+			// Flagged roles are shown even when nothing binds them.
+			addRole("ClusterRole", "", strings.TrimPrefix(f.Resource, "ClusterRole/"))
+		case strings.HasPrefix(f.Resource, "Role/"):
+			ids = append(ids, "role:"+f.Resource)
+			parts := strings.SplitN(f.Resource, "/", 3)
+			addRole("Role", parts[1], parts[2])
+		}
+
+		for _, id := range ids {
+			n := g.Node(id)
+			if n == nil {
+				continue
+			}
+			n.Highlight = true
+			if n.Details == nil {
+				n.Details = make(map[string]string)
+			}
+			if existing := n.Details["findings"]; existing == "" {
+				n.Details["findings"] = f.ID
+			} else if !strings.Contains(existing, f.ID) {
+				n.Details["findings"] = existing + "," + f.ID
+			}
+		}
+	}
+
+	return g
+}
+
+// formatRule renders a policy rule as a compact label.
+func formatRule(rule rbacv1.PolicyRule) string {
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Sprintf("%s on %s", strings.Join(rule.Verbs, ","), strings.Join(rule.NonResourceURLs, ","))
+	}
+
+	groups := make([]string, len(rule.APIGroups))
+	for i, g := range rule.APIGroups {
+		if g == "" {
+			g = "core"
+		}
+		groups[i] = g
+	}
+
+	label := fmt.Sprintf("%s on %s [%s]", strings.Join(rule.Verbs, ","), strings.Join(rule.Resources, ","), strings.Join(groups, ","))
+	if len(rule.ResourceNames) > 0 {
+		label += fmt.Sprintf(" names=%s", strings.Join(rule.ResourceNames, ","))
+	}
+	return label
+}
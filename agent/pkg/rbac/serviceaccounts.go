@@ -0,0 +1,244 @@
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// serviceAccountUsage aggregates the pods that run under a ServiceAccount.
+type serviceAccountUsage struct {
+	account *corev1.ServiceAccount
+	pods    []string
+	// mountedPods are pods that receive an automounted API token.
+	mountedPods []string
+	// exposedPods maps exposed pods with a mounted token to the Services exposing them.
+	exposedPods map[string][]string
+}
+
+// podServiceAccountName returns the ServiceAccount a pod runs as.
+func podServiceAccountName(pod *corev1.Pod) string {
+	if pod.Spec.ServiceAccountName != "" {
+		return pod.Spec.ServiceAccountName
+	}
+	return "default"
+}
+
+// tokenAutomounted reports whether the pod receives an API token. The pod
+// setting takes precedence over the ServiceAccount setting; the default is true.
+func tokenAutomounted(pod *corev1.Pod, sa *corev1.ServiceAccount) bool {
+	if pod.Spec.AutomountServiceAccountToken != nil {
+		return *pod.Spec.AutomountServiceAccountToken
+	}
+	if sa != nil && sa.AutomountServiceAccountToken != nil {
+		return *sa.AutomountServiceAccountToken
+	}
+	return true
+}
+
+// exposingServices maps "namespace/pod" to the internet-facing Services
+// (NodePort, LoadBalancer or externalIPs) that select the pod.
+func exposingServices(services []corev1.Service, pods []corev1.Pod) map[string][]string {
+	exposed := make(map[string][]string)
+	for _, svc := range services {
+		external := svc.Spec.Type == corev1.ServiceTypeNodePort ||
+			svc.Spec.Type == corev1.ServiceTypeLoadBalancer ||
+			len(svc.Spec.ExternalIPs) > 0
+		if !external || len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		for _, pod := range pods {
+			if pod.Namespace != svc.Namespace || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			key := pod.Namespace + "/" + pod.Name
+			exposed[key] = append(exposed[key], fmt.Sprintf("Service/%s/%s (%s)", svc.Namespace, svc.Name, svc.Spec.Type))
+		}
+	}
+	return exposed
+}
+
+// powerfulReason explains why a ServiceAccount is considered powerful, or
+// returns an empty string when it holds no sensitive permissions. Permissions
+// bound to the groups it belongs to are included.
+func (g *escalationGraph) powerfulReason(key string) string {
+	if path := g.shortestPath(key); len(path) > 0 {
+		return "can reach cluster-admin: " + formatChain(path)
+	}
+
+	namespace := strings.SplitN(key, "/", 3)[1]
+	grants := g.effectiveGrants(key)
+	if gr, ok := can(grants, namespace, "", "secrets", "get", "list"); ok {
+		return fmt.Sprintf("can read Secrets in %s via %s", namespace, gr.via)
+	}
+	if gr, ok := can(grants, namespace, "", "pods", "create"); ok {
+		return fmt.Sprintf("can create pods in %s via %s", namespace, gr.via)
+	}
+	if gr, ok := can(grants, namespace, "", "pods/exec", "create"); ok {
+		return fmt.Sprintf("can exec into pods in %s via %s", namespace, gr.via)
+	}
+	if gr, ok := can(grants, namespace, "rbac.authorization.k8s.io", "rolebindings", "create", "update", "patch"); ok {
+		return fmt.Sprintf("can modify RoleBindings in %s via %s", namespace, gr.via)
+	}
+	return ""
+}
+
+// checkServiceAccountHygiene cross-references ServiceAccounts with pods,
+// Services and token Secrets to find unused accounts, unnecessary token
+// mounts, legacy tokens and powerful accounts in internet-exposed workloads.
+func (a *Analyzer) checkServiceAccountHygiene(inv *inventory, g *escalationGraph, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	usage := make(map[string]*serviceAccountUsage)
+	var keys []string
+	for i := range inv.serviceAccounts {
+		sa := &inv.serviceAccounts[i]
+		key := serviceAccountKey(sa.Namespace, sa.Name)
+		usage[key] = &serviceAccountUsage{account: sa, exposedPods: make(map[string][]string)}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	exposed := exposingServices(inv.services, inv.pods)
+	for i := range inv.pods {
+		pod := &inv.pods[i]
+		key := serviceAccountKey(pod.Namespace, podServiceAccountName(pod))
+		u, ok := usage[key]
+		if !ok {
+			continue
+		}
+		podRef := pod.Namespace + "/" + pod.Name
+		u.pods = append(u.pods, podRef)
+		if !tokenAutomounted(pod, u.account) {
+			continue
+		}
+		u.mountedPods = append(u.mountedPods, podRef)
+		if services, ok := exposed[podRef]; ok {
+			u.exposedPods[podRef] = services
+		}
+	}
+
+	for _, key := range keys {
+		u := usage[key]
+		sa := u.account
+		resource := fmt.Sprintf("ServiceAccount/%s/%s", sa.Namespace, sa.Name)
+		// Bindings to the ServiceAccount groups count; system:authenticated,
+		// which every identity holds, does not.
+		bound := len(g.serviceAccountGrants(key)) > 0
+
+		// RBAC-007: ServiceAccount not used by any pod.
+		if len(u.pods) == 0 && sa.Name != "default" {
+			severity := scanner.SeverityLow
+			if bound {
+				severity = scanner.SeverityMedium
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "RBAC-007",
+				Title:       "ServiceAccount not used by any pod",
+				Description: fmt.Sprintf("ServiceAccount %s/%s is not referenced by any running pod", sa.Namespace, sa.Name),
+				Severity:    severity,
+				Status:      scanner.StatusWarning,
+				Category:    "rbac",
+				Resource:    resource,
+				Namespace:   sa.Namespace,
+				Remediation: "Delete ServiceAccounts that are no longer used, together with their bindings. If the account is used by an external system, document its owner.",
+				Details: map[string]string{
+					"has_bindings": fmt.Sprintf("%t", bound),
+				},
+				Timestamp: now,
+			})
+		}
+
+		// RBAC-008: API token automounted into pods that have no API permissions.
+		if len(u.mountedPods) > 0 && !bound {
+			findings = append(findings, scanner.Finding{
+				ID:          "RBAC-008",
+				Title:       "ServiceAccount token automounted without API permissions",
+				Description: fmt.Sprintf("%d pod(s) running as ServiceAccount %s/%s have an API token mounted, but the account has no RBAC bindings and likely never calls the API", len(u.mountedPods), sa.Namespace, sa.Name),
+				Severity:    scanner.SeverityLow,
+				Status:      scanner.StatusWarning,
+				Category:    "rbac",
+				Resource:    resource,
+				Namespace:   sa.Namespace,
+				Remediation: "Set automountServiceAccountToken: false on the ServiceAccount or the pod spec so a compromised container cannot reuse the token.",
+				Details: map[string]string{
+					"pods": strings.Join(u.mountedPods, ","),
+				},
+				Timestamp: now,
+			})
+		}
+
+		// RBAC-010: powerful ServiceAccount mounted into internet-exposed workloads.
+		if len(u.exposedPods) > 0 {
+			reason := g.powerfulReason(key)
+			if reason == "" {
+				continue
+			}
+
+			var pods, services []string
+			seenSvc := make(map[string]bool)
+			for pod, svcs := range u.exposedPods {
+				pods = append(pods, pod)
+				for _, svc := range svcs {
+					if !seenSvc[svc] {
+						seenSvc[svc] = true
+						services = append(services, svc)
+					}
+				}
+			}
+			sort.Strings(pods)
+			sort.Strings(services)
+
+			severity := scanner.SeverityHigh
+			if strings.HasPrefix(reason, "can reach cluster-admin") {
+				severity = scanner.SeverityCritical
+			}
+
+			findings = append(findings, scanner.Finding{
+				ID:          "RBAC-010",
+				Title:       "Powerful ServiceAccount mounted in internet-exposed workload",
+				Description: fmt.Sprintf("ServiceAccount %s/%s %s and its token is mounted into pods exposed by %s", sa.Namespace, sa.Name, reason, strings.Join(services, ", ")),
+				Severity:    severity,
+				Status:      scanner.StatusFail,
+				Category:    "rbac",
+				Resource:    resource,
+				Namespace:   sa.Namespace,
+				Remediation: "Run internet-facing workloads under a dedicated ServiceAccount with minimal permissions, or disable token automounting for them.",
+				Details: map[string]string{
+					"reason":   reason,
+					"pods":     strings.Join(pods, ","),
+					"services": strings.Join(services, ","),
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	// RBAC-009: legacy long-lived ServiceAccount token Secrets.
+	for _, secret := range inv.tokenSecrets {
+		findings = append(findings, scanner.Finding{
+			ID:          "RBAC-009",
+			Title:       "Legacy long-lived ServiceAccount token Secret",
+			Description: fmt.Sprintf("Secret %s/%s holds a non-expiring token for ServiceAccount %q", secret.Namespace, secret.Name, secret.Annotations[corev1.ServiceAccountNameKey]),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "rbac",
+			Resource:    fmt.Sprintf("Secret/%s/%s", secret.Namespace, secret.Name),
+			Namespace:   secret.Namespace,
+			Remediation: "Delete the token Secret and use short-lived tokens from the TokenRequest API (projected volumes or 'kubectl create token') instead.",
+			Details: map[string]string{
+				"service_account": secret.Annotations[corev1.ServiceAccountNameKey],
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,157 @@
+package rbac
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTokenAutomounted(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name string
+		pod  *bool
+		sa   *bool
+		want bool
+	}{
+		{name: "default", want: true},
+		{name: "disabled on ServiceAccount", sa: &no, want: false},
+		{name: "pod overrides ServiceAccount", pod: &yes, sa: &no, want: true},
+		{name: "disabled on pod", pod: &no, sa: &yes, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{AutomountServiceAccountToken: tt.pod}}
+			sa := &corev1.ServiceAccount{AutomountServiceAccountToken: tt.sa}
+			if got := tokenAutomounted(pod, sa); got != tt.want {
+				t.Errorf("tokenAutomounted() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckServiceAccountHygiene(t *testing.T) {
+	no := false
+	pod := func(name, sa string, podLabels map[string]string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: podLabels},
+			Spec:       corev1.PodSpec{ServiceAccountName: sa},
+		}
+	}
+	service := func(name string, typ corev1.ServiceType, selector map[string]string) corev1.Service {
+		return corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec:       corev1.ServiceSpec{Type: typ, Selector: selector},
+		}
+	}
+	secretReader := clusterRole("secret-reader", rule("", []string{"secrets"}, "get"))
+
+	tests := []struct {
+		name string
+		inv  *inventory
+		want []string
+	}{
+		{
+			name: "unused ServiceAccount",
+			inv: &inventory{
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("shop", "default"), serviceAccount("shop", "old")},
+			},
+			want: []string{"RBAC-007 ServiceAccount/shop/old low"},
+		},
+		{
+			name: "unused ServiceAccount with bindings",
+			inv: &inventory{
+				clusterRoles:    []rbacv1.ClusterRole{secretReader},
+				roleBindings:    []rbacv1.RoleBinding{roleBinding("shop", "old", "secret-reader", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "old"})},
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("shop", "old")},
+			},
+			want: []string{"RBAC-007 ServiceAccount/shop/old medium"},
+		},
+		{
+			name: "token mounted without permissions",
+			inv: &inventory{
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("shop", "default")},
+				pods:            []corev1.Pod{pod("web", "", nil)},
+			},
+			want: []string{"RBAC-008 ServiceAccount/shop/default low"},
+		},
+		{
+			name: "token not mounted",
+			inv: &inventory{
+				serviceAccounts: []corev1.ServiceAccount{{
+					ObjectMeta:                   metav1.ObjectMeta{Namespace: "shop", Name: "default"},
+					AutomountServiceAccountToken: &no,
+				}},
+				pods: []corev1.Pod{pod("web", "", nil)},
+			},
+		},
+		{
+			// Group grants count as bindings for RBAC-007 and RBAC-008.
+			name: "permissions through the namespace group",
+			inv: &inventory{
+				clusterRoles: []rbacv1.ClusterRole{secretReader},
+				roleBindings: []rbacv1.RoleBinding{roleBinding("shop", "all-sa", "secret-reader",
+					rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:shop"})},
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("shop", "web")},
+				pods:            []corev1.Pod{pod("web", "web", nil)},
+			},
+		},
+		{
+			name: "powerful ServiceAccount exposed by a LoadBalancer",
+			inv: &inventory{
+				clusterRoles:    []rbacv1.ClusterRole{secretReader},
+				roleBindings:    []rbacv1.RoleBinding{roleBinding("shop", "web", "secret-reader", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "web"})},
+				serviceAccounts: []corev1.ServiceAccount{serviceAccount("shop", "web")},
+				pods:            []corev1.Pod{pod("web", "web", map[string]string{"app": "web"})},
+				services: []corev1.Service{
+					service("web", corev1.ServiceTypeLoadBalancer, map[string]string{"app": "web"}),
+					service("internal", corev1.ServiceTypeClusterIP, map[string]string{"app": "web"}),
+				},
+			},
+			want: []string{"RBAC-010 ServiceAccount/shop/web high"},
+		},
+		{
+			name: "cluster-admin ServiceAccount exposed by a NodePort",
+			inv: &inventory{
+				clusterRoles:        []rbacv1.ClusterRole{clusterRole("cluster-admin", wild)},
+				clusterRoleBindings: []rbacv1.ClusterRoleBinding{clusterRoleBinding("web", "cluster-admin", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "shop", Name: "web"})},
+				serviceAccounts:     []corev1.ServiceAccount{serviceAccount("shop", "web")},
+				pods:                []corev1.Pod{pod("web", "web", map[string]string{"app": "web"})},
+				services:            []corev1.Service{service("web", corev1.ServiceTypeNodePort, map[string]string{"app": "web"})},
+			},
+			want: []string{"RBAC-010 ServiceAccount/shop/web critical"},
+		},
+		{
+			name: "legacy token Secret",
+			inv: &inventory{
+				tokenSecrets: []metav1.PartialObjectMetadata{{ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "shop",
+					Name:        "ci-token",
+					Annotations: map[string]string{corev1.ServiceAccountNameKey: "ci"},
+				}}},
+			},
+			want: []string{"RBAC-009 Secret/shop/ci-token medium"},
+		},
+	}
+
+	a := NewAnalyzer(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range a.checkServiceAccountHygiene(tt.inv, newEscalationGraph(tt.inv), time.Now()) {
+				got = append(got, f.ID+" "+f.Resource+" "+string(f.Severity))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkServiceAccountHygiene() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
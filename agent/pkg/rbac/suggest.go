@@ -0,0 +1,403 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Permission is a policy rule held by a subject. An empty Namespace means the
+// rule applies cluster-wide.
+type Permission struct {
+	Rule      rbacv1.PolicyRule
+	Namespace string
+	Via       string
+	// Inherited is true for rules held through groups, implicit ones such
+	// as system:authenticated or system:serviceaccounts included.
+	Inherited bool
+}
+
+// Suggestion is the result of comparing a subject's effective permissions to
+// its observed API usage.
+type Suggestion struct {
+	Subject     string
+	Permissions []Permission
+	Findings    []scanner.Finding
+	ClusterRole *rbacv1.ClusterRole
+	Roles       []rbacv1.Role
+}
+
+// EffectivePermissions returns the rules granted to an authenticated username
+// by the ClusterRoleBindings and the RoleBindings of the given namespaces,
+// directly or through its implicit groups and the given groups.
+func (a *Analyzer) EffectivePermissions(ctx context.Context, namespaces []string, username string, groups []string) ([]Permission, error) {
+	inv, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newEscalationGraph(inv)
+	key := SubjectKeyForUsername(username)
+	direct := len(g.grants[key])
+
+	grants := g.effectiveGrants(key)
+	implicit := make(map[string]bool)
+	for _, group := range implicitGroups(key) {
+		implicit[group] = true
+	}
+	for _, group := range groups {
+		if !implicit[group] {
+			grants = append(grants, g.grants["Group/"+group]...)
+		}
+	}
+
+	var perms []Permission
+	for i, gr := range grants {
+		perms = append(perms, Permission{
+			Rule:      gr.rule,
+			Namespace: gr.namespace,
+			Via:       gr.via,
+			Inherited: i >= direct,
+		})
+	}
+	return perms, nil
+}
+
+// SuggestLeastPrivilege compares the subject's effective permissions with the
+// API requests recorded in usage, reports grants that were never exercised,
+// and builds the minimal Role/ClusterRole covering the observed requests.
+func (a *Analyzer) SuggestLeastPrivilege(ctx context.Context, namespaces []string, usage *AuditUsage) (*Suggestion, error) {
+	perms, err := a.EffectivePermissions(ctx, namespaces, usage.Subject, usage.Groups)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Suggestion{
+		Subject:     usage.Subject,
+		Permissions: perms,
+		Findings:    checkUnusedGrants(usage, perms, time.Now()),
+	}
+	s.ClusterRole, s.Roles = minimalRoles(usage)
+
+	a.logger.Info("least-privilege suggestion complete",
+		"subject", usage.Subject,
+		"requests", len(usage.Requests),
+		"permissions", len(perms),
+		"findings", len(s.Findings),
+	)
+	return s, nil
+}
+
+// permits reports whether a permission allows an observed request. A rule
+// restricted to resourceNames only allows requests for those objects.
+func (p Permission) permits(req APIRequest) bool {
+	if p.Namespace != "" && p.Namespace != req.Namespace {
+		return false
+	}
+	if len(p.Rule.ResourceNames) > 0 && (req.Name == "" || !slices.Contains(p.Rule.ResourceNames, req.Name)) {
+		return false
+	}
+	return MatchesAny(p.Rule.APIGroups, req.APIGroup) &&
+		MatchesAny(p.Rule.Resources, req.Resource) &&
+		MatchesAny(p.Rule.Verbs, req.Verb)
+}
+
+// checkUnusedGrants reports directly bound rules, or parts of rules, that no
+// observed request relied on.
+func checkUnusedGrants(usage *AuditUsage, perms []Permission, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+	subject := SubjectKeyForUsername(usage.Subject)
+
+	for _, p := range perms {
+		// Rules inherited from implicit groups are shared and not trimmed per subject.
+		if p.Inherited || len(p.Rule.NonResourceURLs) > 0 {
+			continue
+		}
+
+		usedVerbs := make(map[string]bool)
+		usedResources := make(map[string]bool)
+		for _, req := range usage.Requests {
+			if p.permits(req) {
+				usedVerbs[req.Verb] = true
+				usedResources[req.Resource] = true
+			}
+		}
+
+		scope := p.Namespace
+		if scope == "" {
+			scope = "cluster-wide"
+		}
+		details := map[string]string{
+			"subject":   subject,
+			"binding":   p.Via,
+			"scope":     scope,
+			"verbs":     strings.Join(p.Rule.Verbs, ","),
+			"resources": strings.Join(p.Rule.Resources, ","),
+		}
+
+		if len(usedVerbs) == 0 {
+			findings = append(findings, scanner.Finding{
+				ID:          "RBAC-011",
+				Title:       "RBAC grant never used",
+				Description: fmt.Sprintf("%s holds verbs=%v on resources=%v (%s) via %s but never used it in the audit log", subject, p.Rule.Verbs, p.Rule.Resources, scope, p.Via),
+				Severity:    scanner.SeverityLow,
+				Status:      scanner.StatusWarning,
+				Category:    "rbac",
+				Resource:    subject,
+				Namespace:   p.Namespace,
+				Remediation: "Remove the rule from the bound role, or bind the generated least-privilege role instead.",
+				Details:     details,
+				Timestamp:   now,
+			})
+			continue
+		}
+
+		unusedVerbs := unusedItems(p.Rule.Verbs, usedVerbs)
+		unusedResources := unusedItems(p.Rule.Resources, usedResources)
+		wildcard := hasWildcard(p.Rule.Verbs) || hasWildcard(p.Rule.Resources) || hasWildcard(p.Rule.APIGroups)
+		if len(unusedVerbs) == 0 && len(unusedResources) == 0 && !wildcard {
+			continue
+		}
+
+		details["used_verbs"] = strings.Join(sortedKeys(usedVerbs), ",")
+		details["used_resources"] = strings.Join(sortedKeys(usedResources), ",")
+		details["unused_verbs"] = strings.Join(unusedVerbs, ",")
+		details["unused_resources"] = strings.Join(unusedResources, ",")
+
+		severity := scanner.SeverityLow
+		description := fmt.Sprintf("%s only used verbs=%v on resources=%v of a rule granted via %s", subject, sortedKeys(usedVerbs), sortedKeys(usedResources), p.Via)
+		if wildcard {
+			severity = scanner.SeverityMedium
+			description = fmt.Sprintf("%s holds a wildcard rule via %s but only used verbs=%v on resources=%v", subject, p.Via, sortedKeys(usedVerbs), sortedKeys(usedResources))
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "RBAC-011",
+			Title:       "RBAC grant broader than observed usage",
+			Description: description,
+			Severity:    severity,
+			Status:      scanner.StatusWarning,
+			Category:    "rbac",
+			Resource:    subject,
+			Namespace:   p.Namespace,
+			Remediation: "Narrow the rule to the verbs and resources actually used, or bind the generated least-privilege role instead.",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, scanner.Finding{
+			ID:          "RBAC-011",
+			Title:       "All RBAC grants used",
+			Description: fmt.Sprintf("Every rule bound directly to %s was exercised in the audit log.", subject),
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusPass,
+			Category:    "rbac",
+			Resource:    subject,
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
+
+// unusedItems returns the non-wildcard items that are absent from used.
+func unusedItems(items []string, used map[string]bool) []string {
+	var result []string
+	for _, item := range items {
+		if item != "*" && !used[item] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// minimalRoles builds one Role per namespace and a ClusterRole for
+// cluster-scoped requests that cover exactly the observed usage.
+func minimalRoles(usage *AuditUsage) (*rbacv1.ClusterRole, []rbacv1.Role) {
+	byNamespace := make(map[string][]APIRequest)
+	for _, req := range usage.Requests {
+		byNamespace[req.Namespace] = append(byNamespace[req.Namespace], req)
+	}
+
+	name := roleNameForSubject(usage.Subject)
+
+	var clusterRole *rbacv1.ClusterRole
+	if reqs, ok := byNamespace[""]; ok {
+		clusterRole = &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      rulesForRequests(reqs),
+		}
+	}
+
+	var namespaces []string
+	for ns := range byNamespace {
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	var roles []rbacv1.Role
+	for _, ns := range namespaces {
+		roles = append(roles, rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Rules:      rulesForRequests(byNamespace[ns]),
+		})
+	}
+
+	return clusterRole, roles
+}
+
+// rulesForRequests groups requests by API group, verb set and object names,
+// merging the resources that share them into a single rule. A verb is
+// restricted to resourceNames when every request using it named an object.
+func rulesForRequests(reqs []APIRequest) []rbacv1.PolicyRule {
+	type groupResource struct{ group, resource string }
+	type verbUse struct {
+		names map[string]bool
+		// unnamed is set when some request was not for a named object.
+		unnamed bool
+	}
+	uses := make(map[groupResource]map[string]*verbUse)
+	for _, req := range reqs {
+		gr := groupResource{req.APIGroup, req.Resource}
+		if uses[gr] == nil {
+			uses[gr] = make(map[string]*verbUse)
+		}
+		u := uses[gr][req.Verb]
+		if u == nil {
+			u = &verbUse{names: make(map[string]bool)}
+			uses[gr][req.Verb] = u
+		}
+		if req.Name == "" {
+			u.unnamed = true
+		} else {
+			u.names[req.Name] = true
+		}
+	}
+
+	type ruleKey struct{ group, verbs, names string }
+	merged := make(map[ruleKey][]string)
+	var order []ruleKey
+	for gr, verbs := range uses {
+		// Verbs used on the same set of objects share a rule.
+		byNames := make(map[string]map[string]bool)
+		for verb, u := range verbs {
+			names := ""
+			if !u.unnamed {
+				names = strings.Join(sortedKeys(u.names), ",")
+			}
+			if byNames[names] == nil {
+				byNames[names] = make(map[string]bool)
+			}
+			byNames[names][verb] = true
+		}
+		for names, set := range byNames {
+			key := ruleKey{gr.group, strings.Join(sortedKeys(set), ","), names}
+			if _, ok := merged[key]; !ok {
+				order = append(order, key)
+			}
+			merged[key] = append(merged[key], gr.resource)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].group != order[j].group {
+			return order[i].group < order[j].group
+		}
+		if order[i].verbs != order[j].verbs {
+			return order[i].verbs < order[j].verbs
+		}
+		return order[i].names < order[j].names
+	})
+
+	var rules []rbacv1.PolicyRule
+	for _, key := range order {
+		resources := merged[key]
+		sort.Strings(resources)
+		rule := rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: resources,
+			Verbs:     strings.Split(key.verbs, ","),
+		}
+		if key.names != "" {
+			rule.ResourceNames = strings.Split(key.names, ",")
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// invalidNameChars matches characters not allowed in RBAC object names.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// roleNameForSubject derives a DNS-compatible role name from a username.
+func roleNameForSubject(username string) string {
+	name := username
+	if rest, ok := strings.CutPrefix(username, "system:serviceaccount:"); ok {
+		if _, sa, ok := strings.Cut(rest, ":"); ok {
+			name = sa
+		}
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if name == "" {
+		name = "subject"
+	}
+	return name + "-least-privilege"
+}
+
+// WriteYAML writes the suggested ClusterRole and Roles as a multi-document
+// YAML manifest.
+func (s *Suggestion) WriteYAML(w io.Writer) error {
+	serializer := k8sjson.NewSerializerWithOptions(k8sjson.DefaultMetaFactory, nil, nil, k8sjson.SerializerOptions{Yaml: true})
+
+	var objects []runtime.Object
+	if s.ClusterRole != nil {
+		objects = append(objects, s.ClusterRole)
+	}
+	for i := range s.Roles {
+		objects = append(objects, &s.Roles[i])
+	}
+
+	if len(objects) == 0 {
+		_, err := fmt.Fprintf(w, "# No API requests by %s were found in the audit log; no role is needed.\n", s.Subject)
+		return err
+	}
+
+	for i, obj := range objects {
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if err := serializer.Encode(obj, w); err != nil {
+			return fmt.Errorf("encoding %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+	}
+	return nil
+}
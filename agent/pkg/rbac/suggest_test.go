@@ -0,0 +1,86 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestPermissionPermits(t *testing.T) {
+	tests := []struct {
+		name string
+		perm Permission
+		req  APIRequest
+		want bool
+	}{
+		{
+			name: "matching rule",
+			perm: Permission{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}},
+			req:  APIRequest{Namespace: "shop", Resource: "configmaps", Verb: "get", Name: "app"},
+			want: true,
+		},
+		{
+			name: "other namespace",
+			perm: Permission{Namespace: "dev", Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}},
+			req:  APIRequest{Namespace: "shop", Resource: "configmaps", Verb: "get"},
+			want: false,
+		},
+		{
+			name: "wildcards",
+			perm: Permission{Rule: rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+			req:  APIRequest{Namespace: "shop", APIGroup: "apps", Resource: "deployments", Verb: "patch"},
+			want: true,
+		},
+		{
+			name: "listed resource name",
+			perm: Permission{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}, ResourceNames: []string{"app"}}},
+			req:  APIRequest{Namespace: "shop", Resource: "configmaps", Verb: "get", Name: "app"},
+			want: true,
+		},
+		{
+			name: "unlisted resource name",
+			perm: Permission{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}, ResourceNames: []string{"app"}}},
+			req:  APIRequest{Namespace: "shop", Resource: "configmaps", Verb: "get", Name: "other"},
+			want: false,
+		},
+		{
+			name: "unnamed request against resourceNames",
+			perm: Permission{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"list"}, ResourceNames: []string{"app"}}},
+			req:  APIRequest{Namespace: "shop", Resource: "configmaps", Verb: "list"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.perm.permits(tt.req); got != tt.want {
+				t.Errorf("permits() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesForRequests(t *testing.T) {
+	reqs := []APIRequest{
+		{Resource: "configmaps", Verb: "get", Name: "app"},
+		{Resource: "configmaps", Verb: "update", Name: "app"},
+		{Resource: "configmaps", Verb: "list"},
+		{Resource: "secrets", Verb: "get", Name: "db"},
+		{Resource: "secrets", Verb: "get"},
+		{APIGroup: "apps", Resource: "deployments", Verb: "get", Name: "web"},
+	}
+
+	// configmaps get/update are restricted to "app"; secrets get saw an
+	// unnamed request and is not restricted.
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "update"}, ResourceNames: []string{"app"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}, ResourceNames: []string{"web"}},
+	}
+
+	if got := rulesForRequests(reqs); !reflect.DeepEqual(got, want) {
+		t.Errorf("rulesForRequests() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -26,6 +26,7 @@ type htmlData struct {
 	PassedChecks int
 	FailedChecks int
 	Findings     []htmlFinding
+	Exposed      []htmlExposure
 	Critical     int
 	High         int
 	Medium       int
@@ -47,6 +48,15 @@ type htmlFinding struct {
 	Remediation   string
 }
 
+type htmlExposure struct {
+	Workload      string
+	Severity      string
+	SeverityClass string
+	NetworkPolicy string
+	TLS           string
+	ExposedVia    []string
+}
+
 // Generate writes a self-contained HTML report.
 func (r *HTMLReporter) Generate(w io.Writer, result *scanner.ScanResult) error {
 	data := buildHTMLData(result)
@@ -119,18 +129,7 @@ func buildHTMLData(result *scanner.ScanResult) htmlData {
 			Remediation: f.Remediation,
 		}
 
-		switch f.Severity {
-		case scanner.SeverityCritical:
-			hf.SeverityClass = "sev-critical"
-		case scanner.SeverityHigh:
-			hf.SeverityClass = "sev-high"
-		case scanner.SeverityMedium:
-			hf.SeverityClass = "sev-medium"
-		case scanner.SeverityLow:
-			hf.SeverityClass = "sev-low"
-		default:
-			hf.SeverityClass = "sev-info"
-		}
+		hf.SeverityClass = severityClass(f.Severity)
 
 		switch f.Status {
 		case scanner.StatusPass:
@@ -146,9 +145,35 @@ func buildHTMLData(result *scanner.ScanResult) htmlData {
 		data.Findings = append(data.Findings, hf)
 	}
 
+	for _, e := range result.ExposedWorkloads {
+		data.Exposed = append(data.Exposed, htmlExposure{
+			Workload:      e.Workload,
+			Severity:      string(e.Severity),
+			SeverityClass: severityClass(e.Severity),
+			NetworkPolicy: e.NetworkPolicy,
+			TLS:           e.TLS,
+			ExposedVia:    e.ExposedVia,
+		})
+	}
+
 	return data
 }
 
+func severityClass(s scanner.Severity) string {
+	switch s {
+	case scanner.SeverityCritical:
+		return "sev-critical"
+	case scanner.SeverityHigh:
+		return "sev-high"
+	case scanner.SeverityMedium:
+		return "sev-medium"
+	case scanner.SeverityLow:
+		return "sev-low"
+	default:
+		return "sev-info"
+	}
+}
+
 const htmlTemplate = `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -166,6 +191,7 @@ const htmlTemplate = `<!DOCTYPE html>
   body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background: var(--bg); color: var(--text); line-height: 1.6; padding: 2rem; }
   .container { max-width: 1200px; margin: 0 auto; }
   h1 { font-size: 1.8rem; margin-bottom: 0.5rem; }
+  h2 { font-size: 1.2rem; margin: 2rem 0 1rem; }
   .meta { color: var(--text-muted); font-size: 0.875rem; margin-bottom: 2rem; }
   .cards { display: grid; grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); gap: 1rem; margin-bottom: 2rem; }
   .card { background: var(--surface); border: 1px solid var(--border); border-radius: 8px; padding: 1.25rem; }
@@ -259,6 +285,32 @@ const htmlTemplate = `<!DOCTYPE html>
     </tbody>
   </table>
 
+  {{if .Exposed}}
+  <h2>Internet-Exposed Workloads</h2>
+  <table>
+    <thead>
+      <tr>
+        <th>Workload</th>
+        <th>Severity</th>
+        <th>NetworkPolicy</th>
+        <th>TLS</th>
+        <th>Exposed Via</th>
+      </tr>
+    </thead>
+    <tbody>
+      {{range .Exposed}}
+      <tr>
+        <td>{{.Workload}}</td>
+        <td><span class="sev-badge {{.SeverityClass}}">{{.Severity}}</span></td>
+        <td>{{.NetworkPolicy}}</td>
+        <td>{{.TLS}}</td>
+        <td>{{range .ExposedVia}}<div>{{.}}</div>{{end}}</td>
+      </tr>
+      {{end}}
+    </tbody>
+  </table>
+  {{end}}
+
   <footer>
     Generated by KubeComply &mdash; Kubernetes Compliance Scanner
   </footer>
@@ -150,6 +150,36 @@ func (r *TableReporter) Generate(w io.Writer, result *scanner.ScanResult) error
 		return fmt.Errorf("flushing table writer: %w", err)
 	}
 
+	fmt.Fprintln(w)
+	return writeExposureTable(w, result.ExposedWorkloads)
+}
+
+// writeExposureTable prints the internet-exposed workloads inventory.
+func writeExposureTable(w io.Writer, workloads []scanner.ExposedWorkload) error {
+	if len(workloads) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "  %sInternet-Exposed Workloads (%d):%s\n\n", colorBold, len(workloads), colorReset)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  %sWORKLOAD\tSEVERITY\tNETPOL\tTLS\tEXPOSED VIA%s\n", colorGray, colorReset)
+	fmt.Fprintf(tw, "  %s--------\t--------\t------\t---\t-----------%s\n", colorGray, colorReset)
+
+	for _, e := range workloads {
+		for i, via := range e.ExposedVia {
+			if i == 0 {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", e.Workload, colorSeverity(e.Severity), e.NetworkPolicy, e.TLS, via)
+				continue
+			}
+			fmt.Fprintf(tw, "  \t\t\t\t%s\n", via)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flushing table writer: %w", err)
+	}
+
 	fmt.Fprintln(w)
 	return nil
 }
@@ -145,7 +145,7 @@ func (s *Scanner) Run(ctx context.Context, config *ScanConfig) (*ScanResult, err
 	switch config.ScanType {
 	case "full":
 		s.runOPAPolicies(ctx, result, namespaces)
-		s.runAnalyzers(ctx, result, namespaces, "rbac", "network", "pss")
+		s.runAnalyzers(ctx, result, namespaces, "rbac", "network", "pss", "ingress", "workload", "secrets", "certificates", "webhooks", "versions", "governance", "controlplane")
 
 	case "cis":
 		s.runOPAPolicies(ctx, result, namespaces)
@@ -165,8 +165,48 @@ func (s *Scanner) Run(ctx context.Context, config *ScanConfig) (*ScanResult, err
 			return nil, fmt.Errorf("PSS check: %w", err)
 		}
 
+	case "ingress":
+		if err := s.runAnalyzer(ctx, result, namespaces, "ingress"); err != nil {
+			return nil, fmt.Errorf("ingress analysis: %w", err)
+		}
+
+	case "workload":
+		if err := s.runAnalyzer(ctx, result, namespaces, "workload"); err != nil {
+			return nil, fmt.Errorf("workload analysis: %w", err)
+		}
+
+	case "secrets":
+		if err := s.runAnalyzer(ctx, result, namespaces, "secrets"); err != nil {
+			return nil, fmt.Errorf("secrets analysis: %w", err)
+		}
+
+	case "certificates":
+		if err := s.runAnalyzer(ctx, result, namespaces, "certificates"); err != nil {
+			return nil, fmt.Errorf("certificates analysis: %w", err)
+		}
+
+	case "webhooks":
+		if err := s.runAnalyzer(ctx, result, namespaces, "webhooks"); err != nil {
+			return nil, fmt.Errorf("webhooks analysis: %w", err)
+		}
+
+	case "versions":
+		if err := s.runAnalyzer(ctx, result, namespaces, "versions"); err != nil {
+			return nil, fmt.Errorf("versions analysis: %w", err)
+		}
+
+	case "governance":
+		if err := s.runAnalyzer(ctx, result, namespaces, "governance"); err != nil {
+			return nil, fmt.Errorf("governance analysis: %w", err)
+		}
+
+	case "controlplane":
+		if err := s.runAnalyzer(ctx, result, namespaces, "controlplane"); err != nil {
+			return nil, fmt.Errorf("controlplane analysis: %w", err)
+		}
+
 	default:
-		return nil, fmt.Errorf("unknown scan type: %q (valid: full, cis, rbac, network, pss)", config.ScanType)
+		return nil, fmt.Errorf("unknown scan type: %q (valid: full, cis, rbac, network, pss, ingress, workload, secrets, certificates, webhooks, versions, governance, controlplane)", config.ScanType)
 	}
 
 	// Finalize results.
@@ -150,6 +150,42 @@ type ScanResult struct {
 
 	// Summary provides aggregated statistics.
 	Summary ScanSummary `json:"summary"`
+
+	// ExposedWorkloads is the internet-exposed workloads inventory, built
+	// from findings that carry exposure details.
+	ExposedWorkloads []ExposedWorkload `json:"exposedWorkloads,omitempty"`
+}
+
+// Details keys that mark a finding as an entry of the internet-exposed
+// workloads inventory. DetailExposedVia holds the exposure paths separated
+// by "; ".
+const (
+	DetailExposedVia    = "exposed_via"
+	DetailNetworkPolicy = "network_policy"
+	DetailTLS           = "tls"
+)
+
+// ExposedWorkload is a workload reachable from outside the cluster together
+// with the controls in front of it.
+type ExposedWorkload struct {
+	// Workload is the "Kind/namespace/name" of the exposed controller.
+	Workload string `json:"workload"`
+
+	// Namespace is the namespace of the workload.
+	Namespace string `json:"namespace,omitempty"`
+
+	// ExposedVia lists the Services, Ingresses and routes exposing it.
+	ExposedVia []string `json:"exposedVia"`
+
+	// NetworkPolicy describes ingress NetworkPolicy protection: none,
+	// allow-all or restricted.
+	NetworkPolicy string `json:"networkPolicy"`
+
+	// TLS describes TLS on HTTP exposure paths: all, partial, none or n/a.
+	TLS string `json:"tls"`
+
+	// Severity is the severity of the correlated finding.
+	Severity Severity `json:"severity"`
 }
 
 // ScanConfig controls how a scan is executed.
@@ -176,13 +212,26 @@ type ScanConfig struct {
 	SaaSToken string `json:"saasToken,omitempty"`
 }
 
-// ComputeSummary recalculates the Summary field from the Findings slice.
+// ComputeSummary recalculates the Summary and ExposedWorkloads fields from
+// the Findings slice.
 func (r *ScanResult) ComputeSummary() {
 	summary := ScanSummary{
 		FindingsBySeverity: make(map[Severity]int),
 	}
+	r.ExposedWorkloads = nil
 
 	for _, f := range r.Findings {
+		if via := f.Details[DetailExposedVia]; via != "" {
+			r.ExposedWorkloads = append(r.ExposedWorkloads, ExposedWorkload{
+				Workload:      f.Resource,
+				Namespace:     f.Namespace,
+				ExposedVia:    strings.Split(via, "; "),
+				NetworkPolicy: f.Details[DetailNetworkPolicy],
+				TLS:           f.Details[DetailTLS],
+				Severity:      f.Severity,
+			})
+		}
+
 		summary.TotalChecks++
 		switch f.Status {
 		case StatusPass:
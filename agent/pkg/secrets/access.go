@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/rbac"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// readVerbs are the verbs that return Secret values.
+var readVerbs = []string{"get", "list", "watch"}
+
+// broadSubject describes why a subject covers many identities and how
+// severe granting it Secret access is. ok is false for ordinary subjects.
+func broadSubject(subject rbacv1.Subject) (reason string, severity scanner.Severity, ok bool) {
+	switch {
+	case subject.Kind == rbacv1.GroupKind && subject.Name == "system:unauthenticated",
+		subject.Kind == rbacv1.UserKind && subject.Name == "system:anonymous":
+		return "anonymous requests", scanner.SeverityCritical, true
+	case subject.Kind == rbacv1.GroupKind && subject.Name == "system:authenticated":
+		return "every authenticated user and ServiceAccount", scanner.SeverityCritical, true
+	case subject.Kind == rbacv1.GroupKind && subject.Name == "system:serviceaccounts":
+		return "every ServiceAccount in the cluster", scanner.SeverityHigh, true
+	case subject.Kind == rbacv1.GroupKind && strings.HasPrefix(subject.Name, "system:serviceaccounts:"):
+		return fmt.Sprintf("every ServiceAccount in namespace %s", strings.TrimPrefix(subject.Name, "system:serviceaccounts:")), scanner.SeverityMedium, true
+	case subject.Kind == rbacv1.ServiceAccountKind && subject.Name == "default":
+		return "every pod that does not set a ServiceAccount", scanner.SeverityMedium, true
+	}
+	return "", "", false
+}
+
+// secretReadAccess reports whether the rules allow reading Secrets, and
+// returns the verbs granted and, when every such rule is limited by
+// resourceNames, the Secret names they are restricted to.
+func secretReadAccess(rules []rbacv1.PolicyRule) (verbs, names []string, ok bool) {
+	seenVerb := make(map[string]bool)
+	all := false
+	for _, rule := range rules {
+		if !rbac.MatchesAny(rule.APIGroups, "") || !rbac.MatchesAny(rule.Resources, "secrets") {
+			continue
+		}
+		granted := false
+		for _, verb := range readVerbs {
+			if rbac.MatchesAny(rule.Verbs, verb) {
+				granted = true
+				if !seenVerb[verb] {
+					seenVerb[verb] = true
+					verbs = append(verbs, verb)
+				}
+			}
+		}
+		if !granted {
+			continue
+		}
+		ok = true
+		if len(rule.ResourceNames) == 0 {
+			all = true
+		}
+		names = append(names, rule.ResourceNames...)
+	}
+	if all {
+		names = nil
+	}
+	return verbs, names, ok
+}
+
+// checkBroadAccess reports bindings that let broad subjects read Secrets
+// (SEC-004): anonymous or all authenticated users, all ServiceAccounts of the
+// cluster or a namespace, and default ServiceAccounts.
+func (a *Analyzer) checkBroadAccess(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, b := range rbac.ResolveBindings(inv.clusterRoles, inv.clusterRoleBindings, inv.roles, inv.roleBindings) {
+		verbs, names, ok := secretReadAccess(b.Rules)
+		if !ok {
+			continue
+		}
+		scope := "cluster-wide"
+		if b.Namespace != "" {
+			scope = "in " + b.Namespace
+		}
+		secrets, target := "all", "all Secrets"
+		if len(names) > 0 {
+			secrets = strings.Join(names, ",")
+			target = "Secret(s) " + strings.Join(names, ", ")
+		}
+		for _, subject := range b.Subjects {
+			reason, severity, broad := broadSubject(subject)
+			if !broad {
+				continue
+			}
+			subjectName := subject.Name
+			if subject.Kind == rbacv1.ServiceAccountKind {
+				subjectName = subject.Namespace + "/" + subject.Name
+				reason = fmt.Sprintf("%s in namespace %s", reason, subject.Namespace)
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "SEC-004",
+				Title:       "Secrets readable by broad RBAC subject",
+				Description: fmt.Sprintf("%s grants %s (%s) %s on %s %s via %s %q", b.Resource, subjectName, reason, strings.Join(verbs, "/"), target, scope, b.RoleRef.Kind, b.RoleRef.Name),
+				Severity:    severity,
+				Status:      scanner.StatusFail,
+				Category:    "secrets",
+				Resource:    b.Resource,
+				Namespace:   b.Namespace,
+				Remediation: "Bind Secret read access to dedicated ServiceAccounts or named groups only, and restrict it with resourceNames to the Secrets each one needs.",
+				Details: map[string]string{
+					"subject_kind": subject.Kind,
+					"subject_name": subjectName,
+					"role":         b.RoleRef.Kind + "/" + b.RoleRef.Name,
+					"verbs":        strings.Join(verbs, ","),
+					"secrets":      secrets,
+					"scope":        strings.TrimPrefix(scope, "in "),
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckBroadAccess(t *testing.T) {
+	readSecrets := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}}
+	inv := &inventory{
+		clusterRoles: []rbacv1.ClusterRole{
+			{ObjectMeta: metav1.ObjectMeta{Name: "secret-reader"}, Rules: readSecrets},
+			{ObjectMeta: metav1.ObjectMeta{Name: "everything"}, Rules: []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "pod-reader"}, Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		},
+		clusterRoleBindings: []rbacv1.ClusterRoleBinding{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "all-users"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "secret-reader"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:authenticated"}, {Kind: rbacv1.UserKind, Name: "alice"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pods-only"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "pod-reader"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:unauthenticated"}},
+			},
+		},
+		roles: []rbacv1.Role{
+			{ObjectMeta: metav1.ObjectMeta{Name: "db-creds", Namespace: "shop"}, Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"db"}, Verbs: []string{"get"}}}},
+		},
+		roleBindings: []rbacv1.RoleBinding{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "namespace-accounts", Namespace: "shop"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "everything"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:shop"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-db", Namespace: "shop"},
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "db-creds"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "default"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "missing-role", Namespace: "shop"},
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "absent"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:authenticated"}},
+			},
+		},
+	}
+
+	a := NewAnalyzer(nil, nil)
+	var got []string
+	for _, f := range a.checkBroadAccess(inv, time.Now()) {
+		got = append(got, f.Resource+" "+f.Details["subject_name"]+" "+f.Details["secrets"]+" "+string(f.Severity))
+	}
+	sort.Strings(got)
+	want := []string{
+		"ClusterRoleBinding/all-users system:authenticated all critical",
+		"RoleBinding/shop/default-db shop/default db medium",
+		"RoleBinding/shop/namespace-accounts system:serviceaccounts:shop all medium",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SEC-004 findings = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+// Package secrets analyzes how Secrets are consumed and who can read them,
+// and looks for credentials stored in ConfigMaps. Secret values are never
+// copied into findings: only object names, ConfigMap keys and detector names
+// are reported.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/pss"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Analyzer evaluates Secret and ConfigMap hygiene.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client          *k8s.Client
+	logger          *slog.Logger
+	templateSources []pss.PodTemplateSource
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithPodTemplateSources adds custom resources whose embedded pod templates
+// count as Secret references, in addition to pss.DefaultPodTemplateSources.
+func WithPodTemplateSources(sources ...pss.PodTemplateSource) Option {
+	return func(a *Analyzer) {
+		a.templateSources = append(a.templateSources, sources...)
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "secrets" }
+
+// NewAnalyzer creates a new secrets hygiene analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client:          client,
+		logger:          logger,
+		templateSources: append([]pss.PodTemplateSource(nil), pss.DefaultPodTemplateSources...),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// podTemplate is a controller or custom resource pod template, or a pod.
+type podTemplate struct {
+	resource  string
+	namespace string
+	spec      *corev1.PodSpec
+	// owned is set for pods, ReplicaSets, Jobs and custom resources created
+	// by a controller, whose template is reported instead.
+	owned bool
+}
+
+// inventory holds the objects fetched for a single analysis run.
+type inventory struct {
+	templates           []podTemplate
+	secrets             []metav1.PartialObjectMetadata
+	configMaps          []corev1.ConfigMap
+	serviceAccounts     []corev1.ServiceAccount
+	tlsConsumers        map[string][]string
+	clusterRoles        []rbacv1.ClusterRole
+	clusterRoleBindings []rbacv1.ClusterRoleBinding
+	roles               []rbacv1.Role
+	roleBindings        []rbacv1.RoleBinding
+	// incomplete holds the namespaces where a source of Secret references
+	// failed to list, so unreferenced Secrets cannot be told apart there.
+	incomplete map[string]bool
+}
+
+// Analyze runs all secrets checks and returns findings.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting secrets analysis")
+
+	inv, err := a.collect(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	// Check 1: Secrets exposed through environment variables.
+	findings = append(findings, a.checkEnvExposure(inv, now)...)
+
+	// Check 2: Secret-looking values in ConfigMaps.
+	findings = append(findings, a.checkConfigMaps(inv, now)...)
+
+	// Check 3: Secrets nothing references.
+	findings = append(findings, a.checkUnusedSecrets(inv, now)...)
+
+	// Check 4: Secrets readable by broad RBAC subjects.
+	findings = append(findings, a.checkBroadAccess(inv, now)...)
+
+	a.logger.Info("secrets analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect fetches the pod templates, Secret metadata, ConfigMaps,
+// ServiceAccounts, Ingresses and RBAC objects of the given namespaces, plus
+// cluster-scoped RBAC objects and Gateway listeners referencing Secrets.
+// Secret values are never read.
+func (a *Analyzer) collect(ctx context.Context, namespaces []string) (*inventory, error) {
+	inv := &inventory{
+		tlsConsumers: make(map[string][]string),
+		incomplete:   make(map[string]bool),
+	}
+
+	var err error
+	inv.clusterRoles, err = a.client.ListClusterRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster roles: %w", err)
+	}
+
+	inv.clusterRoleBindings, err = a.client.ListClusterRoleBindings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster role bindings: %w", err)
+	}
+
+	for _, ns := range namespaces {
+		templates, complete := a.collectTemplates(ctx, ns)
+		inv.templates = append(inv.templates, templates...)
+		if !complete {
+			inv.incomplete[ns] = true
+		}
+
+		secrets, err := a.client.ListSecretMetadata(ctx, ns, unusedSecretsSelector)
+		if err != nil {
+			a.logger.Warn("failed to list secrets", "namespace", ns, "error", err)
+		}
+		inv.secrets = append(inv.secrets, secrets...)
+
+		configMaps, err := a.client.ListConfigMaps(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list configmaps", "namespace", ns, "error", err)
+		}
+		inv.configMaps = append(inv.configMaps, configMaps...)
+
+		serviceAccounts, err := a.client.ListServiceAccounts(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list service accounts", "namespace", ns, "error", err)
+			inv.incomplete[ns] = true
+		}
+		inv.serviceAccounts = append(inv.serviceAccounts, serviceAccounts...)
+
+		ingresses, err := a.client.ListIngresses(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list ingresses", "namespace", ns, "error", err)
+			inv.incomplete[ns] = true
+		}
+		for _, ing := range ingresses {
+			for _, tls := range ing.Spec.TLS {
+				if tls.SecretName != "" {
+					key := ns + "/" + tls.SecretName
+					inv.tlsConsumers[key] = append(inv.tlsConsumers[key], fmt.Sprintf("Ingress/%s/%s", ns, ing.Name))
+				}
+			}
+		}
+
+		roles, err := a.client.ListRoles(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list roles", "namespace", ns, "error", err)
+		}
+		inv.roles = append(inv.roles, roles...)
+
+		bindings, err := a.client.ListRoleBindings(ctx, ns)
+		if err != nil {
+			a.logger.Warn("failed to list role bindings", "namespace", ns, "error", err)
+		}
+		inv.roleBindings = append(inv.roleBindings, bindings...)
+	}
+
+	// Gateway listeners may reference Secrets across namespaces.
+	gateways, err := a.client.ListGateways(ctx, "")
+	if err != nil {
+		a.logger.Warn("failed to list gateways", "error", err)
+		for _, ns := range namespaces {
+			inv.incomplete[ns] = true
+		}
+	}
+	for _, gw := range gateways {
+		for _, l := range gw.Spec.Listeners {
+			if l.TLS == nil {
+				continue
+			}
+			for _, ref := range l.TLS.CertificateRefs {
+				if ref.Kind != "" && ref.Kind != "Secret" {
+					continue
+				}
+				ns := ref.Namespace
+				if ns == "" {
+					ns = gw.Namespace
+				}
+				key := ns + "/" + ref.Name
+				inv.tlsConsumers[key] = append(inv.tlsConsumers[key], fmt.Sprintf("Gateway/%s/%s", gw.Namespace, gw.Name))
+			}
+		}
+	}
+
+	return inv, nil
+}
+
+// collectTemplates lists the pod templates of a namespace's controllers,
+// including those embedded in custom resources, and its pods. Controllers
+// scaled to zero are included, since their templates still reference
+// Secrets. complete is false when any of the listings failed.
+func (a *Analyzer) collectTemplates(ctx context.Context, ns string) (templates []podTemplate, complete bool) {
+	complete = true
+	add := func(kind, name string, spec *corev1.PodSpec, owned bool) {
+		templates = append(templates, podTemplate{
+			resource:  fmt.Sprintf("%s/%s/%s", kind, ns, name),
+			namespace: ns,
+			spec:      spec,
+			owned:     owned,
+		})
+	}
+	failed := func(resource string, err error) {
+		a.logger.Warn("failed to list "+resource, "namespace", ns, "error", err)
+		complete = false
+	}
+
+	deployments, err := a.client.ListDeployments(ctx, ns)
+	if err != nil {
+		failed("deployments", err)
+	}
+	for i := range deployments {
+		add("Deployment", deployments[i].Name, &deployments[i].Spec.Template.Spec, false)
+	}
+
+	daemonsets, err := a.client.ListDaemonSets(ctx, ns)
+	if err != nil {
+		failed("daemonsets", err)
+	}
+	for i := range daemonsets {
+		add("DaemonSet", daemonsets[i].Name, &daemonsets[i].Spec.Template.Spec, false)
+	}
+
+	statefulsets, err := a.client.ListStatefulSets(ctx, ns)
+	if err != nil {
+		failed("statefulsets", err)
+	}
+	for i := range statefulsets {
+		add("StatefulSet", statefulsets[i].Name, &statefulsets[i].Spec.Template.Spec, false)
+	}
+
+	replicasets, err := a.client.ListReplicaSets(ctx, ns)
+	if err != nil {
+		failed("replicasets", err)
+	}
+	for i := range replicasets {
+		add("ReplicaSet", replicasets[i].Name, &replicasets[i].Spec.Template.Spec, metav1.GetControllerOfNoCopy(&replicasets[i]) != nil)
+	}
+
+	rcs, err := a.client.ListReplicationControllers(ctx, ns)
+	if err != nil {
+		failed("replicationcontrollers", err)
+	}
+	for i := range rcs {
+		if rcs[i].Spec.Template != nil {
+			add("ReplicationController", rcs[i].Name, &rcs[i].Spec.Template.Spec, false)
+		}
+	}
+
+	cronjobs, err := a.client.ListCronJobs(ctx, ns)
+	if err != nil {
+		failed("cronjobs", err)
+	}
+	for i := range cronjobs {
+		add("CronJob", cronjobs[i].Name, &cronjobs[i].Spec.JobTemplate.Spec.Template.Spec, false)
+	}
+
+	jobs, err := a.client.ListJobs(ctx, ns)
+	if err != nil {
+		failed("jobs", err)
+	}
+	for i := range jobs {
+		add("Job", jobs[i].Name, &jobs[i].Spec.Template.Spec, metav1.GetControllerOfNoCopy(&jobs[i]) != nil)
+	}
+
+	pods, err := a.client.ListPods(ctx, ns)
+	if err != nil {
+		failed("pods", err)
+	}
+	for i := range pods {
+		add("Pod", pods[i].Name, &pods[i].Spec, metav1.GetControllerOfNoCopy(&pods[i]) != nil)
+	}
+
+	for _, source := range a.templateSources {
+		objects, err := a.client.ListCustomResources(ctx, source.GVR, ns)
+		if err != nil {
+			a.logger.Warn("failed to list custom resources", "resource", source.GVR.String(), "namespace", ns, "error", err)
+			complete = false
+			continue
+		}
+		for i := range objects {
+			obj := &objects[i]
+			specs, err := source.Templates(obj)
+			if err != nil {
+				a.logger.Warn("failed to extract pod template", "resource", source.GVR.String(), "name", obj.GetName(), "error", err)
+				complete = false
+				continue
+			}
+			for j := range specs {
+				add(obj.GetKind(), obj.GetName(), &specs[j].Spec, metav1.GetControllerOfNoCopy(obj) != nil)
+			}
+		}
+	}
+
+	return templates, complete
+}
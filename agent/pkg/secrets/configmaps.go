@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// credentialPattern recognises a well-known credential format.
+type credentialPattern struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// credentialPatterns are matched against every ConfigMap value. Private keys
+// embedded in JSON, such as GCP service account keys, match the PEM header.
+var credentialPatterns = []credentialPattern{
+	{"private_key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{"aws_access_key_id", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws_secret_access_key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"azure_storage_key", regexp.MustCompile(`(?i)AccountKey=[A-Za-z0-9+/]{86}==`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+}
+
+// certificateBlock matches PEM certificates, which are public and would
+// otherwise trip the entropy heuristic.
+var certificateBlock = regexp.MustCompile(`(?s)-----BEGIN CERTIFICATE-----.*?-----END CERTIFICATE-----`)
+
+// entropyCandidate matches base64- and token-like runs worth measuring.
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/=_\-]{32,}`)
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above which
+// a candidate token is treated as a likely secret. Random base64 reaches about
+// 4.6 at 32 characters; words, paths and hex digests stay below it.
+const minSecretEntropy = 4.5
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// mixedClasses reports whether s has upper-case letters, lower-case letters
+// and digits, as generated credentials do.
+func mixedClasses(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// detectSecrets returns the names of the detectors that match a value. The
+// value itself is never returned.
+func detectSecrets(value string) []string {
+	var matched []string
+	for _, p := range credentialPatterns {
+		if p.pattern.MatchString(value) {
+			matched = append(matched, p.name)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+
+	for _, token := range entropyCandidate.FindAllString(certificateBlock.ReplaceAllString(value, ""), -1) {
+		if mixedClasses(token) && shannonEntropy(token) >= minSecretEntropy {
+			return []string{"high_entropy"}
+		}
+	}
+	return nil
+}
+
+// checkConfigMaps reports ConfigMap entries that look like credentials
+// (SEC-002): private keys, cloud and SaaS key formats, and high-entropy
+// tokens. ConfigMaps are readable far more widely than Secrets and are not
+// encrypted at rest. Findings name the key and detector only.
+func (a *Analyzer) checkConfigMaps(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, cm := range inv.configMaps {
+		keys := make([]string, 0, len(cm.Data))
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			detectors := detectSecrets(cm.Data[key])
+			if len(detectors) == 0 {
+				continue
+			}
+
+			severity, status := scanner.SeverityHigh, scanner.StatusFail
+			description := fmt.Sprintf("ConfigMap %s/%s key %q contains what looks like a credential (%s)", cm.Namespace, cm.Name, key, strings.Join(detectors, ", "))
+			if detectors[0] == "high_entropy" {
+				severity, status = scanner.SeverityMedium, scanner.StatusWarning
+				description = fmt.Sprintf("ConfigMap %s/%s key %q contains a high-entropy string that may be a credential", cm.Namespace, cm.Name, key)
+			}
+
+			findings = append(findings, scanner.Finding{
+				ID:          "SEC-002",
+				Title:       "Secret-looking value in ConfigMap",
+				Description: description,
+				Severity:    severity,
+				Status:      status,
+				Category:    "secrets",
+				Resource:    fmt.Sprintf("ConfigMap/%s/%s", cm.Namespace, cm.Name),
+				Namespace:   cm.Namespace,
+				Remediation: "Move the value into a Secret (or an external secret store) and reference it from there, then rotate the credential, since it has been readable by everyone with ConfigMap access.",
+				Details: map[string]string{
+					"key":       key,
+					"detectors": strings.Join(detectors, ","),
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,181 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/pss"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// unusedSecretsSelector leaves out Secret types that are consumed by the API
+// server, kubelet or tooling rather than referenced from pods. Legacy
+// ServiceAccount tokens are reported by RBAC-009.
+const unusedSecretsSelector = "type!=" + string(corev1.SecretTypeServiceAccountToken) +
+	",type!=" + string(corev1.SecretTypeBootstrapToken) +
+	",type!=helm.sh/release.v1"
+
+// volumeSecrets returns the Secrets a pod volume mounts or uses to
+// authenticate to its storage backend.
+func volumeSecrets(v corev1.Volume) []string {
+	var names []string
+	switch {
+	case v.Secret != nil:
+		names = append(names, v.Secret.SecretName)
+	case v.Projected != nil:
+		for _, source := range v.Projected.Sources {
+			if source.Secret != nil {
+				names = append(names, source.Secret.Name)
+			}
+		}
+	case v.CSI != nil && v.CSI.NodePublishSecretRef != nil:
+		names = append(names, v.CSI.NodePublishSecretRef.Name)
+	case v.AzureFile != nil:
+		names = append(names, v.AzureFile.SecretName)
+	case v.CephFS != nil && v.CephFS.SecretRef != nil:
+		names = append(names, v.CephFS.SecretRef.Name)
+	case v.RBD != nil && v.RBD.SecretRef != nil:
+		names = append(names, v.RBD.SecretRef.Name)
+	case v.ISCSI != nil && v.ISCSI.SecretRef != nil:
+		names = append(names, v.ISCSI.SecretRef.Name)
+	case v.FlexVolume != nil && v.FlexVolume.SecretRef != nil:
+		names = append(names, v.FlexVolume.SecretRef.Name)
+	}
+	return names
+}
+
+// specSecrets returns every Secret a pod spec references, through volumes,
+// environment variables or image pull credentials.
+func specSecrets(spec *corev1.PodSpec) []string {
+	var names []string
+	for _, v := range spec.Volumes {
+		names = append(names, volumeSecrets(v)...)
+	}
+	for _, ref := range spec.ImagePullSecrets {
+		names = append(names, ref.Name)
+	}
+	for _, c := range pss.AllContainers(spec) {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names = append(names, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, from := range c.EnvFrom {
+			if from.SecretRef != nil {
+				names = append(names, from.SecretRef.Name)
+			}
+		}
+	}
+	return names
+}
+
+// checkEnvExposure reports containers that receive Secret values through
+// environment variables (SEC-001). Environment variables are inherited by
+// child processes, shown by debugging tools and often end up in logs and
+// crash reports, whereas mounted files are not. Pods and Jobs created by a
+// controller are covered by the controller's template.
+func (a *Analyzer) checkEnvExposure(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	for _, t := range inv.templates {
+		if t.owned {
+			continue
+		}
+		for _, c := range pss.AllContainers(t.spec) {
+			var envVars, secretNames []string
+			seen := make(map[string]bool)
+			addSecret := func(name string) {
+				if !seen[name] {
+					seen[name] = true
+					secretNames = append(secretNames, name)
+				}
+			}
+			for _, env := range c.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					envVars = append(envVars, env.Name)
+					addSecret(env.ValueFrom.SecretKeyRef.Name)
+				}
+			}
+			for _, from := range c.EnvFrom {
+				if from.SecretRef != nil {
+					envVars = append(envVars, from.Prefix+"*")
+					addSecret(from.SecretRef.Name)
+				}
+			}
+			if len(secretNames) == 0 {
+				continue
+			}
+
+			findings = append(findings, scanner.Finding{
+				ID:          "SEC-001",
+				Title:       "Secret exposed through environment variables",
+				Description: fmt.Sprintf("Container %q in %s reads Secret(s) %s into environment variables", c.Name, t.resource, strings.Join(secretNames, ", ")),
+				Severity:    scanner.SeverityMedium,
+				Status:      scanner.StatusFail,
+				Category:    "secrets",
+				Resource:    t.resource,
+				Namespace:   t.namespace,
+				Remediation: "Mount the Secret as a read-only volume and have the application read the file instead of using env valueFrom.secretKeyRef or envFrom.secretRef.",
+				Details: map[string]string{
+					"container": c.Name,
+					"secrets":   strings.Join(secretNames, ","),
+					"env_vars":  strings.Join(envVars, ","),
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	return findings
+}
+
+// checkUnusedSecrets reports Secrets that no pod, workload template,
+// ServiceAccount, Ingress or Gateway references (SEC-003). Secrets owned by
+// another object, types consumed outside pods and namespaces where a
+// reference source failed to list are skipped.
+func (a *Analyzer) checkUnusedSecrets(inv *inventory, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	referenced := make(map[string]bool)
+	for _, t := range inv.templates {
+		for _, name := range specSecrets(t.spec) {
+			referenced[t.namespace+"/"+name] = true
+		}
+	}
+	for _, sa := range inv.serviceAccounts {
+		for _, ref := range sa.Secrets {
+			referenced[sa.Namespace+"/"+ref.Name] = true
+		}
+		for _, ref := range sa.ImagePullSecrets {
+			referenced[sa.Namespace+"/"+ref.Name] = true
+		}
+	}
+	for key := range inv.tlsConsumers {
+		referenced[key] = true
+	}
+
+	for _, secret := range inv.secrets {
+		key := secret.Namespace + "/" + secret.Name
+		if referenced[key] || len(secret.OwnerReferences) > 0 || inv.incomplete[secret.Namespace] {
+			continue
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          "SEC-003",
+			Title:       "Unused Secret",
+			Description: fmt.Sprintf("Secret %s is not referenced by any pod, workload, ServiceAccount, Ingress or Gateway", key),
+			Severity:    scanner.SeverityLow,
+			Status:      scanner.StatusWarning,
+			Category:    "secrets",
+			Resource:    fmt.Sprintf("Secret/%s", key),
+			Namespace:   secret.Namespace,
+			Remediation: "Delete the Secret if it is no longer needed and revoke the credential it holds. Secrets read directly through the API by operators or external tools are not detected as references.",
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+func TestCheckUnusedSecrets(t *testing.T) {
+	zero := int32(0)
+	mounting := func(secret string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app:1.0"}},
+			Volumes:    []corev1.Volume{{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret}}}},
+		}}
+	}
+	legacyTemplate := mounting("legacy-creds")
+	rcTemplate := mounting("rc-creds")
+
+	clientset := fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "shop"},
+			Spec:       appsv1.ReplicaSetSpec{Replicas: &zero, Template: legacyTemplate},
+		},
+		&corev1.ReplicationController{
+			ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "shop"},
+			Spec:       corev1.ReplicationControllerSpec{Replicas: &zero, Template: &rcTemplate},
+		},
+	)
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "broken" {
+			return true, nil, errors.New("etcd timeout")
+		}
+		return false, nil, nil
+	})
+
+	rollout := &unstructured.Unstructured{}
+	rollout.SetAPIVersion("argoproj.io/v1alpha1")
+	rollout.SetKind("Rollout")
+	rollout.SetName("canary")
+	rollout.SetNamespace("shop")
+	rollout.Object["spec"] = map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+		"containers": []interface{}{map[string]interface{}{"name": "app", "image": "app:1.0"}},
+		"volumes":    []interface{}{map[string]interface{}{"name": "creds", "secret": map[string]interface{}{"secretName": "rollout-creds"}}},
+	}}}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}:   "RolloutList",
+		{Group: "serving.knative.dev", Version: "v1", Resource: "services"}: "ServiceList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, rollout)
+	client := k8s.NewClientFromInterface(clientset, "test", nil).WithDynamicClient(dynamicClient)
+
+	a := NewAnalyzer(client, nil)
+	inv := &inventory{incomplete: make(map[string]bool)}
+	for _, ns := range []string{"shop", "broken"} {
+		templates, complete := a.collectTemplates(context.Background(), ns)
+		inv.templates = append(inv.templates, templates...)
+		if complete != (ns == "shop") {
+			t.Errorf("collectTemplates(%s) complete = %v", ns, complete)
+		}
+		if !complete {
+			inv.incomplete[ns] = true
+		}
+	}
+	secret := func(ns, name string) metav1.PartialObjectMetadata {
+		return metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+	}
+	inv.secrets = []metav1.PartialObjectMetadata{
+		secret("shop", "legacy-creds"),
+		secret("shop", "rc-creds"),
+		secret("shop", "rollout-creds"),
+		secret("shop", "orphan"),
+		secret("broken", "orphan"),
+	}
+
+	var got []string
+	for _, f := range a.checkUnusedSecrets(inv, time.Now()) {
+		got = append(got, f.Resource)
+	}
+	sort.Strings(got)
+	want := []string{"Secret/shop/orphan"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SEC-003 resources = %v, want %v", got, want)
+	}
+}
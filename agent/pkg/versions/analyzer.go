@@ -0,0 +1,162 @@
+// Package versions checks the Kubernetes version of a cluster against the
+// upstream support window and version skew policy, and checks manifests for
+// API versions that are deprecated or removed in a target release.
+package versions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Analyzer evaluates Kubernetes versions and API deprecations.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client        *k8s.Client
+	logger        *slog.Logger
+	manifests     []string
+	targetVersion string
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithManifests switches the analyzer to manifest mode: instead of the
+// cluster's versions, the YAML and JSON manifests at the given files and
+// directories are checked for deprecated and removed API versions.
+func WithManifests(paths ...string) Option {
+	return func(a *Analyzer) {
+		a.manifests = append(a.manifests, paths...)
+	}
+}
+
+// WithTargetVersion sets the Kubernetes version, e.g. "1.32", manifests are
+// checked against. By default the cluster's API server version is used.
+func WithTargetVersion(v string) Option {
+	return func(a *Analyzer) {
+		a.targetVersion = v
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "versions" }
+
+// NewAnalyzer creates a new version and deprecation analyzer. The client may
+// be nil in manifest mode when a target version is set.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client: client,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// minor is a Kubernetes major.minor release.
+type minor struct {
+	major, minor int
+}
+
+func (m minor) String() string { return fmt.Sprintf("%d.%d", m.major, m.minor) }
+
+// before reports whether m is an earlier release than o.
+func (m minor) before(o minor) bool {
+	return m.major < o.major || (m.major == o.major && m.minor < o.minor)
+}
+
+// parseMinor extracts the release from a version such as "1.32",
+// "v1.32.4" or "v1.32.4-eks-1a2b3c".
+func parseMinor(s string) (minor, error) {
+	v, err := utilversion.ParseGeneric(s)
+	if err != nil {
+		return minor{}, err
+	}
+	return minor{major: int(v.Major()), minor: int(v.Minor())}, nil
+}
+
+// Analyze runs the cluster version checks, or in manifest mode the API
+// deprecation checks, and returns findings.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting version analysis")
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	if len(a.manifests) > 0 {
+		target, err := a.target(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check 1: Deprecated and removed API versions in manifests.
+		manifestFindings, err := a.checkManifests(target, now)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, manifestFindings...)
+	} else {
+		info, err := a.client.ServerVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		server, err := parseMinor(info.GitVersion)
+		if err != nil {
+			return nil, fmt.Errorf("parsing server version %q: %w", info.GitVersion, err)
+		}
+
+		nodes, err := a.client.ListNodes(ctx)
+		if err != nil {
+			a.logger.Warn("failed to list nodes", "error", err)
+		}
+
+		// Check 1: Control plane release past or near the end of upstream support.
+		findings = append(findings, a.checkSupport(server, info.GitVersion, now)...)
+
+		// Check 2: Kubelet versions outside the supported skew.
+		findings = append(findings, a.checkSkew(server, info.GitVersion, nodes, now)...)
+	}
+
+	a.logger.Info("version analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// target returns the release manifests are checked against: the configured
+// target version, or the cluster's.
+func (a *Analyzer) target(ctx context.Context) (minor, error) {
+	if a.targetVersion != "" {
+		m, err := parseMinor(a.targetVersion)
+		if err != nil {
+			return minor{}, fmt.Errorf("parsing target version %q: %w", a.targetVersion, err)
+		}
+		return m, nil
+	}
+	if a.client == nil {
+		return minor{}, fmt.Errorf("a target version is required when no cluster is available")
+	}
+	info, err := a.client.ServerVersion(ctx)
+	if err != nil {
+		return minor{}, err
+	}
+	m, err := parseMinor(info.GitVersion)
+	if err != nil {
+		return minor{}, fmt.Errorf("parsing server version %q: %w", info.GitVersion, err)
+	}
+	return m, nil
+}
+
+// kubeletVersion returns a node's kubelet release.
+func kubeletVersion(node *corev1.Node) (minor, error) {
+	return parseMinor(node.Status.NodeInfo.KubeletVersion)
+}
@@ -0,0 +1,239 @@
+package versions
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// deprecation records when an API version of a kind was deprecated and
+// removed, and what replaces it. An empty replacement means the kind was
+// removed without a successor.
+type deprecation struct {
+	apiVersion  string
+	kinds       []string
+	replacement string
+	deprecated  minor
+	removed     minor
+	note        string
+}
+
+// deprecations lists the API versions removed from Kubernetes since 1.16,
+// following the upstream deprecated API migration guide.
+var deprecations = []deprecation{
+	{"extensions/v1beta1", []string{"Deployment", "DaemonSet", "ReplicaSet"}, "apps/v1", minor{1, 9}, minor{1, 16}, ""},
+	{"apps/v1beta1", []string{"Deployment", "StatefulSet", "ReplicaSet"}, "apps/v1", minor{1, 9}, minor{1, 16}, ""},
+	{"apps/v1beta2", []string{"Deployment", "DaemonSet", "StatefulSet", "ReplicaSet"}, "apps/v1", minor{1, 9}, minor{1, 16}, ""},
+	{"extensions/v1beta1", []string{"NetworkPolicy"}, "networking.k8s.io/v1", minor{1, 9}, minor{1, 16}, ""},
+	{"extensions/v1beta1", []string{"PodSecurityPolicy"}, "policy/v1beta1", minor{1, 10}, minor{1, 16}, ""},
+
+	{"admissionregistration.k8s.io/v1beta1", []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, "admissionregistration.k8s.io/v1", minor{1, 16}, minor{1, 22}, "webhooks[*].admissionReviewVersions and sideEffects are now required"},
+	{"apiextensions.k8s.io/v1beta1", []string{"CustomResourceDefinition"}, "apiextensions.k8s.io/v1", minor{1, 16}, minor{1, 22}, "a structural schema is required for every version"},
+	{"apiregistration.k8s.io/v1beta1", []string{"APIService"}, "apiregistration.k8s.io/v1", minor{1, 19}, minor{1, 22}, ""},
+	{"authentication.k8s.io/v1beta1", []string{"TokenReview"}, "authentication.k8s.io/v1", minor{1, 19}, minor{1, 22}, ""},
+	{"authorization.k8s.io/v1beta1", []string{"LocalSubjectAccessReview", "SelfSubjectAccessReview", "SubjectAccessReview", "SelfSubjectRulesReview"}, "authorization.k8s.io/v1", minor{1, 19}, minor{1, 22}, ""},
+	{"certificates.k8s.io/v1beta1", []string{"CertificateSigningRequest"}, "certificates.k8s.io/v1", minor{1, 19}, minor{1, 22}, "spec.signerName is now required"},
+	{"coordination.k8s.io/v1beta1", []string{"Lease"}, "coordination.k8s.io/v1", minor{1, 19}, minor{1, 22}, ""},
+	{"extensions/v1beta1", []string{"Ingress"}, "networking.k8s.io/v1", minor{1, 14}, minor{1, 22}, "spec.backend is renamed spec.defaultBackend and backends use service.name and service.port"},
+	{"networking.k8s.io/v1beta1", []string{"Ingress"}, "networking.k8s.io/v1", minor{1, 19}, minor{1, 22}, "spec.backend is renamed spec.defaultBackend and backends use service.name and service.port"},
+	{"networking.k8s.io/v1beta1", []string{"IngressClass"}, "networking.k8s.io/v1", minor{1, 19}, minor{1, 22}, ""},
+	{"rbac.authorization.k8s.io/v1beta1", []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, "rbac.authorization.k8s.io/v1", minor{1, 17}, minor{1, 22}, ""},
+	{"scheduling.k8s.io/v1beta1", []string{"PriorityClass"}, "scheduling.k8s.io/v1", minor{1, 14}, minor{1, 22}, ""},
+	{"storage.k8s.io/v1beta1", []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, "storage.k8s.io/v1", minor{1, 19}, minor{1, 22}, ""},
+
+	{"batch/v1beta1", []string{"CronJob"}, "batch/v1", minor{1, 21}, minor{1, 25}, ""},
+	{"discovery.k8s.io/v1beta1", []string{"EndpointSlice"}, "discovery.k8s.io/v1", minor{1, 21}, minor{1, 25}, "topology is replaced by the per-endpoint zone field"},
+	{"events.k8s.io/v1beta1", []string{"Event"}, "events.k8s.io/v1", minor{1, 19}, minor{1, 25}, ""},
+	{"autoscaling/v2beta1", []string{"HorizontalPodAutoscaler"}, "autoscaling/v2", minor{1, 23}, minor{1, 25}, "targetAverageUtilization moves to target.averageUtilization"},
+	{"policy/v1beta1", []string{"PodDisruptionBudget"}, "policy/v1", minor{1, 21}, minor{1, 25}, "an empty spec.selector now selects every pod in the namespace"},
+	{"policy/v1beta1", []string{"PodSecurityPolicy"}, "", minor{1, 21}, minor{1, 25}, "enforce the Pod Security Standards with Pod Security Admission or a policy engine instead"},
+	{"node.k8s.io/v1beta1", []string{"RuntimeClass"}, "node.k8s.io/v1", minor{1, 20}, minor{1, 25}, ""},
+
+	{"flowcontrol.apiserver.k8s.io/v1beta1", []string{"FlowSchema", "PriorityLevelConfiguration"}, "flowcontrol.apiserver.k8s.io/v1", minor{1, 23}, minor{1, 26}, ""},
+	{"autoscaling/v2beta2", []string{"HorizontalPodAutoscaler"}, "autoscaling/v2", minor{1, 23}, minor{1, 26}, ""},
+	{"storage.k8s.io/v1beta1", []string{"CSIStorageCapacity"}, "storage.k8s.io/v1", minor{1, 24}, minor{1, 27}, ""},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", []string{"FlowSchema", "PriorityLevelConfiguration"}, "flowcontrol.apiserver.k8s.io/v1", minor{1, 26}, minor{1, 29}, ""},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", []string{"FlowSchema", "PriorityLevelConfiguration"}, "flowcontrol.apiserver.k8s.io/v1", minor{1, 29}, minor{1, 32}, ""},
+}
+
+// lookupDeprecation returns the deprecation entry for an apiVersion and kind.
+func lookupDeprecation(apiVersion, kind string) (deprecation, bool) {
+	for _, d := range deprecations {
+		if d.apiVersion != apiVersion {
+			continue
+		}
+		for _, k := range d.kinds {
+			if k == kind {
+				return d, true
+			}
+		}
+	}
+	return deprecation{}, false
+}
+
+// manifest is the part of a Kubernetes object needed to identify it. Lists,
+// such as the output of kubectl get -o yaml, carry their objects in items.
+type manifest struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Items []manifest `json:"items"`
+}
+
+// isManifest reports whether a file name has a manifest extension.
+func isManifest(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// manifestFiles expands the configured paths into manifest files,
+// descending into directories.
+func (a *Analyzer) manifestFiles() ([]string, error) {
+	var files []string
+	for _, root := range a.manifests {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("reading manifests: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isManifest(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walking %s: %w", root, err)
+		}
+	}
+	return files, nil
+}
+
+// readManifests decodes every YAML or JSON document in a file, flattening
+// lists.
+func readManifests(path string) ([]manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objects []manifest
+	var flatten func(m manifest)
+	flatten = func(m manifest) {
+		if m.APIVersion != "" && m.Kind != "" && len(m.Items) == 0 {
+			objects = append(objects, m)
+		}
+		for _, item := range m.Items {
+			flatten(item)
+		}
+	}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var m manifest
+		if err := decoder.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return objects, err
+		}
+		flatten(m)
+	}
+}
+
+// checkManifests reports objects whose apiVersion is removed in the target
+// release (VER-005) or deprecated in it (VER-006). Files that cannot be
+// parsed are skipped with a warning, as they may be templates.
+func (a *Analyzer) checkManifests(target minor, now time.Time) ([]scanner.Finding, error) {
+	files, err := a.manifestFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []scanner.Finding
+	for _, path := range files {
+		objects, err := readManifests(path)
+		if err != nil {
+			a.logger.Warn("failed to parse manifest", "file", path, "error", err)
+		}
+
+		for _, obj := range objects {
+			d, ok := lookupDeprecation(obj.APIVersion, obj.Kind)
+			if !ok || target.before(d.deprecated) {
+				continue
+			}
+
+			resource := fmt.Sprintf("%s/%s", obj.Kind, obj.Metadata.Name)
+			if obj.Metadata.Namespace != "" {
+				resource = fmt.Sprintf("%s/%s/%s", obj.Kind, obj.Metadata.Namespace, obj.Metadata.Name)
+			}
+
+			remediation := fmt.Sprintf("Change apiVersion to %s; kubectl convert can rewrite most manifests automatically.", d.replacement)
+			if d.replacement == "" {
+				remediation = fmt.Sprintf("%s %s has no replacement; remove the manifest.", obj.APIVersion, obj.Kind)
+			}
+			if d.note != "" {
+				remediation += fmt.Sprintf(" Note: %s.", d.note)
+			}
+
+			id, title := "VER-006", "Deprecated API version"
+			severity, status := scanner.SeverityMedium, scanner.StatusWarning
+			description := fmt.Sprintf("%s in %s uses %s, deprecated since Kubernetes %s and removed in %s", resource, path, obj.APIVersion, d.deprecated, d.removed)
+			if !target.before(d.removed) {
+				id, title = "VER-005", "Removed API version"
+				severity, status = scanner.SeverityHigh, scanner.StatusFail
+				description = fmt.Sprintf("%s in %s uses %s, removed in Kubernetes %s; the API server of a %s cluster rejects it", resource, path, obj.APIVersion, d.removed, target)
+			}
+
+			details := map[string]string{
+				"file":           path,
+				"api_version":    obj.APIVersion,
+				"kind":           obj.Kind,
+				"deprecated_in":  d.deprecated.String(),
+				"removed_in":     d.removed.String(),
+				"target_version": target.String(),
+			}
+			if d.replacement != "" {
+				details["replacement"] = d.replacement
+			}
+
+			findings = append(findings, scanner.Finding{
+				ID:          id,
+				Title:       title,
+				Description: description,
+				Severity:    severity,
+				Status:      status,
+				Category:    "versions",
+				Resource:    resource,
+				Namespace:   obj.Metadata.Namespace,
+				Remediation: remediation,
+				Details:     details,
+				Timestamp:   now,
+			})
+		}
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,184 @@
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestLookupDeprecation(t *testing.T) {
+	tests := []struct {
+		apiVersion  string
+		kind        string
+		found       bool
+		replacement string
+		removed     minor
+	}{
+		{"extensions/v1beta1", "Deployment", true, "apps/v1", minor{1, 16}},
+		{"extensions/v1beta1", "Ingress", true, "networking.k8s.io/v1", minor{1, 22}},
+		{"extensions/v1beta1", "NetworkPolicy", true, "networking.k8s.io/v1", minor{1, 16}},
+		{"batch/v1beta1", "CronJob", true, "batch/v1", minor{1, 25}},
+		{"policy/v1beta1", "PodDisruptionBudget", true, "policy/v1", minor{1, 25}},
+		{"policy/v1beta1", "PodSecurityPolicy", true, "", minor{1, 25}},
+		{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", true, "flowcontrol.apiserver.k8s.io/v1", minor{1, 32}},
+		{"apps/v1", "Deployment", false, "", minor{}},
+		{"batch/v1beta1", "Job", false, "", minor{}},
+		{"example.com/v1beta1", "CronJob", false, "", minor{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiVersion+"/"+tt.kind, func(t *testing.T) {
+			d, ok := lookupDeprecation(tt.apiVersion, tt.kind)
+			if ok != tt.found {
+				t.Fatalf("lookupDeprecation() found = %t, want %t", ok, tt.found)
+			}
+			if d.replacement != tt.replacement || d.removed != tt.removed {
+				t.Errorf("lookupDeprecation() = %s removed in %s, want %s removed in %s", d.replacement, d.removed, tt.replacement, tt.removed)
+			}
+		})
+	}
+}
+
+func TestParseMinor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    minor
+		wantErr bool
+	}{
+		{in: "1.32", want: minor{1, 32}},
+		{in: "v1.29.4", want: minor{1, 29}},
+		{in: "v1.30.2-eks-1a2b3c", want: minor{1, 30}},
+		{in: "latest", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseMinor(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMinor(%q) error = %v, wantErr %t", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseMinor(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadManifests(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "multi-document YAML",
+			content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+---
+# comment-only document
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+`,
+			want: []string{"apps/v1 Deployment shop/web", "batch/v1beta1 CronJob /report"},
+		},
+		{
+			name: "JSON list",
+			content: `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "extensions/v1beta1", "kind": "Ingress", "metadata": {"name": "web", "namespace": "shop"}},
+  {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web", "namespace": "shop"}}
+]}`,
+			want: []string{"extensions/v1beta1 Ingress shop/web", "v1 Service shop/web"},
+		},
+		{
+			name:    "document without kind",
+			content: "apiVersion: v1\nmetadata:\n  name: orphan\n",
+		},
+		{
+			name: "template after a valid document",
+			content: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: {{ .Values.apiVersion }
+`,
+			want:    []string{"v1 ConfigMap /settings"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			objects, err := readManifests(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readManifests() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			var got []string
+			for _, m := range objects {
+				got = append(got, m.APIVersion+" "+m.Kind+" "+m.Metadata.Namespace+"/"+m.Metadata.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readManifests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckManifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cronjob.yaml": "apiVersion: batch/v1beta1\nkind: CronJob\nmetadata:\n  name: report\n  namespace: shop\n",
+		"flow.json":    `{"apiVersion": "flowcontrol.apiserver.k8s.io/v1beta3", "kind": "FlowSchema", "metadata": {"name": "batch"}}`,
+		"current.yml":  "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+		"README.md":    "apiVersion: batch/v1beta1\nkind: CronJob\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		target minor
+		want   []string
+	}{
+		{target: minor{1, 20}},
+		{target: minor{1, 21}, want: []string{"VER-006 CronJob/shop/report"}},
+		{target: minor{1, 29}, want: []string{"VER-005 CronJob/shop/report", "VER-006 FlowSchema/batch"}},
+		{target: minor{1, 32}, want: []string{"VER-005 CronJob/shop/report", "VER-005 FlowSchema/batch"}},
+	}
+
+	a := NewAnalyzer(nil, nil, WithManifests(dir))
+	for _, tt := range tests {
+		t.Run(tt.target.String(), func(t *testing.T) {
+			findings, err := a.checkManifests(tt.target, time.Now())
+			if err != nil {
+				t.Fatalf("checkManifests() error = %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.ID+" "+f.Resource)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkManifests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
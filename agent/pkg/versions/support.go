@@ -0,0 +1,155 @@
+package versions
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// endOfLife holds the end of upstream patch support for each Kubernetes
+// release. Releases older than the first entry are out of support; releases
+// newer than the last are assumed to be supported.
+var endOfLife = []struct {
+	release minor
+	date    string
+}{
+	{minor{1, 23}, "2023-02-28"},
+	{minor{1, 24}, "2023-07-28"},
+	{minor{1, 25}, "2023-10-28"},
+	{minor{1, 26}, "2024-02-28"},
+	{minor{1, 27}, "2024-06-28"},
+	{minor{1, 28}, "2024-10-28"},
+	{minor{1, 29}, "2025-02-28"},
+	{minor{1, 30}, "2025-06-28"},
+	{minor{1, 31}, "2025-10-28"},
+	{minor{1, 32}, "2026-02-28"},
+	{minor{1, 33}, "2026-06-28"},
+	{minor{1, 34}, "2026-10-27"},
+	{minor{1, 35}, "2027-02-28"},
+}
+
+// endOfLifeWarning is how long before the end of support a release is
+// reported.
+const endOfLifeWarning = 60 * 24 * time.Hour
+
+// supportEnds returns the end of upstream support for a release. known is
+// false for releases newer than the table.
+func supportEnds(m minor) (end time.Time, known bool) {
+	if m.before(endOfLife[0].release) {
+		end, _ = time.Parse("2006-01-02", endOfLife[0].date)
+		return end, true
+	}
+	for _, e := range endOfLife {
+		if e.release == m {
+			end, _ = time.Parse("2006-01-02", e.date)
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// maxKubeletSkew returns how many minor releases a kubelet may lag behind
+// the API server. The window grew from two to three releases in 1.28.
+func maxKubeletSkew(server minor) int {
+	if server.before(minor{1, 28}) {
+		return 2
+	}
+	return 3
+}
+
+// checkSupport reports a control plane release past the end of upstream
+// support (VER-001) or within endOfLifeWarning of it (VER-002).
+func (a *Analyzer) checkSupport(server minor, gitVersion string, now time.Time) []scanner.Finding {
+	end, known := supportEnds(server)
+	if !known {
+		return nil
+	}
+	details := map[string]string{
+		"server_version": gitVersion,
+		"end_of_life":    end.Format("2006-01-02"),
+	}
+	remediation := "Upgrade the control plane, then the nodes, one minor release at a time to a supported Kubernetes release. Managed offerings (EKS, GKE, AKS) may publish their own support dates; check your provider's calendar."
+
+	switch {
+	case !now.Before(end):
+		return []scanner.Finding{{
+			ID:          "VER-001",
+			Title:       "Kubernetes release out of support",
+			Description: fmt.Sprintf("The API server runs Kubernetes %s (%s), whose upstream support ended on %s; it no longer receives security patches", server, gitVersion, end.Format("2006-01-02")),
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "versions",
+			Resource:    "Cluster/" + a.client.ClusterName(),
+			Remediation: remediation,
+			Details:     details,
+			Timestamp:   now,
+		}}
+	case end.Sub(now) <= endOfLifeWarning:
+		days := int(end.Sub(now).Hours() / 24)
+		details["days_remaining"] = fmt.Sprintf("%d", days)
+		return []scanner.Finding{{
+			ID:          "VER-002",
+			Title:       "Kubernetes release nearing end of support",
+			Description: fmt.Sprintf("The API server runs Kubernetes %s (%s), whose upstream support ends on %s, in %d days", server, gitVersion, end.Format("2006-01-02"), days),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusWarning,
+			Category:    "versions",
+			Resource:    "Cluster/" + a.client.ClusterName(),
+			Remediation: remediation,
+			Details:     details,
+			Timestamp:   now,
+		}}
+	}
+	return nil
+}
+
+// checkSkew reports kubelets newer than the API server (VER-003) or older
+// than the version skew policy allows (VER-004).
+func (a *Analyzer) checkSkew(server minor, gitVersion string, nodes []corev1.Node, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+	maxSkew := maxKubeletSkew(server)
+
+	for i := range nodes {
+		node := &nodes[i]
+		kubelet, err := kubeletVersion(node)
+		if err != nil {
+			a.logger.Debug("skipping node with unparseable kubelet version", "node", node.Name, "version", node.Status.NodeInfo.KubeletVersion)
+			continue
+		}
+
+		var id, title, description, remediation string
+		switch {
+		case server.before(kubelet):
+			id, title = "VER-003", "Kubelet newer than API server"
+			description = fmt.Sprintf("Node %s runs kubelet %s, newer than the API server's %s; kubelets must never be newer than kube-apiserver", node.Name, node.Status.NodeInfo.KubeletVersion, gitVersion)
+			remediation = "Upgrade the control plane before the nodes, or roll the node back to a kubelet matching the API server's release."
+		case kubelet.major == server.major && server.minor-kubelet.minor > maxSkew:
+			id, title = "VER-004", "Kubelet version skew exceeds policy"
+			description = fmt.Sprintf("Node %s runs kubelet %s, %d minor releases behind the API server's %s; at most %d are supported", node.Name, node.Status.NodeInfo.KubeletVersion, server.minor-kubelet.minor, gitVersion, maxSkew)
+			remediation = "Upgrade or replace the node so its kubelet is within the supported skew of the API server."
+		default:
+			continue
+		}
+
+		findings = append(findings, scanner.Finding{
+			ID:          id,
+			Title:       title,
+			Description: description,
+			Severity:    scanner.SeverityHigh,
+			Status:      scanner.StatusFail,
+			Category:    "versions",
+			Resource:    "Node/" + node.Name,
+			Remediation: remediation,
+			Details: map[string]string{
+				"kubelet_version": node.Status.NodeInfo.KubeletVersion,
+				"server_version":  gitVersion,
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
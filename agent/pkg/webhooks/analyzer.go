@@ -0,0 +1,193 @@
+// Package webhooks analyzes the configuration of mutating and validating
+// admission webhooks: failure policies, the namespaces they intercept,
+// timeouts, side effects and the network exposure of their backing Services.
+package webhooks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// DefaultMaxTimeout is the longest webhook timeout accepted when none is
+// configured. It equals the API server's default of 10 seconds.
+const DefaultMaxTimeout = 10 * time.Second
+
+// Analyzer evaluates admission webhook configurations.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client     *k8s.Client
+	logger     *slog.Logger
+	maxTimeout time.Duration
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithMaxTimeout sets the longest timeoutSeconds a webhook may use before it
+// is reported (WHK-004). Non-positive values leave the default in place.
+func WithMaxTimeout(d time.Duration) Option {
+	return func(a *Analyzer) {
+		if d > 0 {
+			a.maxTimeout = d
+		}
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "webhooks" }
+
+// NewAnalyzer creates a new admission webhook analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client:     client,
+		logger:     logger,
+		maxTimeout: DefaultMaxTimeout,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// hook is a single mutating or validating webhook. The two API types share
+// every field the checks need.
+type hook struct {
+	// resource is the owning configuration, e.g.
+	// "ValidatingWebhookConfiguration/gatekeeper-validating-webhook-configuration".
+	resource          string
+	mutating          bool
+	name              string
+	rules             []admissionregistrationv1.RuleWithOperations
+	failurePolicy     admissionregistrationv1.FailurePolicyType
+	timeout           time.Duration
+	namespaceSelector *metav1.LabelSelector
+	objectSelector    *metav1.LabelSelector
+	matchConditions   int
+	service           *admissionregistrationv1.ServiceReference
+}
+
+// newHook fills in the API defaults for unset fields: failurePolicy Fail
+// and a 10 second timeout.
+func newHook(kind, config, name string, rules []admissionregistrationv1.RuleWithOperations, failurePolicy *admissionregistrationv1.FailurePolicyType,
+	timeoutSeconds *int32, nsSelector, objSelector *metav1.LabelSelector,
+	matchConditions int, cc admissionregistrationv1.WebhookClientConfig) hook {
+	h := hook{
+		resource:          kind + "/" + config,
+		mutating:          kind == "MutatingWebhookConfiguration",
+		name:              name,
+		rules:             rules,
+		failurePolicy:     admissionregistrationv1.Fail,
+		timeout:           10 * time.Second,
+		namespaceSelector: nsSelector,
+		objectSelector:    objSelector,
+		matchConditions:   matchConditions,
+		service:           cc.Service,
+	}
+	if failurePolicy != nil {
+		h.failurePolicy = *failurePolicy
+	}
+	if timeoutSeconds != nil {
+		h.timeout = time.Duration(*timeoutSeconds) * time.Second
+	}
+	return h
+}
+
+// inventory holds the objects fetched for a single analysis run.
+type inventory struct {
+	hooks []hook
+	// namespaceLabels maps namespace names to their labels.
+	namespaceLabels map[string]map[string]string
+}
+
+// Analyze runs all webhook checks and returns findings. Webhook
+// configurations are cluster-scoped and always evaluated in full.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting webhook analysis")
+
+	inv, err := a.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	for _, h := range inv.hooks {
+		// Check 1: Security-relevant webhooks that fail open.
+		findings = append(findings, a.checkFailOpen(h, now)...)
+
+		// Check 2: Fail-closed webhooks intercepting kube-system or their own namespace.
+		findings = append(findings, a.checkSelfInterception(inv, h, now)...)
+
+		// Check 3: Webhooks without a namespaceSelector.
+		findings = append(findings, a.checkNamespaceSelector(h, now)...)
+
+		// Check 4: Excessive timeouts.
+		findings = append(findings, a.checkTimeout(h, now)...)
+	}
+
+	// Check 5: Webhook Services in namespaces no network policy applies to.
+	findings = append(findings, a.checkServiceIsolation(ctx, inv, now)...)
+
+	a.logger.Info("webhook analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect lists all admission webhook configurations and the labels of all
+// namespaces, which namespaceSelectors are evaluated against.
+func (a *Analyzer) collect(ctx context.Context) (*inventory, error) {
+	inv := &inventory{namespaceLabels: make(map[string]map[string]string)}
+
+	mutating, err := a.client.ListMutatingWebhookConfigurations(ctx)
+	if err != nil {
+		a.logger.Warn("failed to list mutating webhook configurations", "error", err)
+	}
+	for _, cfg := range mutating {
+		for _, wh := range cfg.Webhooks {
+			inv.hooks = append(inv.hooks, newHook("MutatingWebhookConfiguration", cfg.Name, wh.Name, wh.Rules, wh.FailurePolicy,
+				wh.TimeoutSeconds, wh.NamespaceSelector, wh.ObjectSelector, len(wh.MatchConditions), wh.ClientConfig))
+		}
+	}
+
+	validating, err := a.client.ListValidatingWebhookConfigurations(ctx)
+	if err != nil {
+		a.logger.Warn("failed to list validating webhook configurations", "error", err)
+	}
+	for _, cfg := range validating {
+		for _, wh := range cfg.Webhooks {
+			inv.hooks = append(inv.hooks, newHook("ValidatingWebhookConfiguration", cfg.Name, wh.Name, wh.Rules, wh.FailurePolicy,
+				wh.TimeoutSeconds, wh.NamespaceSelector, wh.ObjectSelector, len(wh.MatchConditions), wh.ClientConfig))
+		}
+	}
+
+	namespaces, err := a.client.ListNamespaces(ctx)
+	if err != nil {
+		a.logger.Warn("failed to list namespaces", "error", err)
+	}
+	for _, ns := range namespaces {
+		inv.namespaceLabels[ns.Name] = ns.Labels
+	}
+
+	return inv, nil
+}
+
+// labelsOf returns a namespace's labels, including the
+// kubernetes.io/metadata.name label the API server sets on every namespace.
+func (inv *inventory) labelsOf(ns string) map[string]string {
+	l := map[string]string{corev1.LabelMetadataName: ns}
+	for k, v := range inv.namespaceLabels[ns] {
+		l[k] = v
+	}
+	return l
+}
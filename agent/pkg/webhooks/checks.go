@@ -0,0 +1,338 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubecomply/kubecomply/pkg/network"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// securityResources are resources whose admission is security-relevant:
+// workloads, their execution subresources, credentials, RBAC and network
+// isolation.
+var securityResources = map[string]bool{
+	"pods":                   true,
+	"deployments":            true,
+	"daemonsets":             true,
+	"statefulsets":           true,
+	"replicasets":            true,
+	"replicationcontrollers": true,
+	"jobs":                   true,
+	"cronjobs":               true,
+	"secrets":                true,
+	"serviceaccounts":        true,
+	"roles":                  true,
+	"rolebindings":           true,
+	"clusterroles":           true,
+	"clusterrolebindings":    true,
+	"networkpolicies":        true,
+	"namespaces":             true,
+}
+
+// policyEngineConfigurations are the webhook configurations policy engines
+// register, which enforce security controls whatever resources they match.
+var policyEngineConfigurations = map[string]string{
+	"gatekeeper-validating-webhook-configuration": "Gatekeeper",
+	"gatekeeper-mutating-webhook-configuration":   "Gatekeeper",
+	"kyverno-resource-validating-webhook-cfg":     "Kyverno",
+	"kyverno-resource-mutating-webhook-cfg":       "Kyverno",
+	"kyverno-policy-validating-webhook-cfg":       "Kyverno",
+	"kyverno-policy-mutating-webhook-cfg":         "Kyverno",
+	"kyverno-exception-validating-webhook-cfg":    "Kyverno",
+	"jspolicy": "jsPolicy",
+}
+
+// policyEngineNamespaces are the namespaces policy engines are installed in
+// by default, matched against the namespace of a webhook's Service. Engines
+// installed elsewhere are only recognised by their configuration names.
+var policyEngineNamespaces = map[string]string{
+	"gatekeeper-system": "Gatekeeper",
+	"kyverno":           "Kyverno",
+	"kubewarden":        "Kubewarden",
+	"jspolicy":          "jsPolicy",
+}
+
+// securityRelevance describes why a webhook enforces security controls, or
+// returns "" when it does not appear to.
+func securityRelevance(h hook) string {
+	for _, rule := range h.rules {
+		for _, r := range rule.Resources {
+			base, _, _ := strings.Cut(r, "/")
+			if base == "*" {
+				return "all resources"
+			}
+			if securityResources[base] {
+				return r
+			}
+		}
+	}
+	_, config, _ := strings.Cut(h.resource, "/")
+	if engine, ok := policyEngineConfigurations[config]; ok {
+		return "requests checked by the " + engine + " policy engine"
+	}
+	if h.service != nil {
+		if engine, ok := policyEngineNamespaces[h.service.Namespace]; ok {
+			return "requests checked by the " + engine + " policy engine"
+		}
+	}
+	return ""
+}
+
+// interceptsNamespaced reports whether any rule matches namespaced objects,
+// which are the only ones a namespaceSelector filters.
+func interceptsNamespaced(h hook) bool {
+	for _, rule := range h.rules {
+		if rule.Scope == nil || *rule.Scope == admissionregistrationv1.AllScopes || *rule.Scope == admissionregistrationv1.NamespacedScope {
+			return true
+		}
+	}
+	return false
+}
+
+// selectsAll reports whether a namespaceSelector matches every namespace.
+func selectsAll(sel *metav1.LabelSelector) bool {
+	return sel == nil || (len(sel.MatchLabels) == 0 && len(sel.MatchExpressions) == 0)
+}
+
+// intercepts reports whether a webhook's namespaceSelector matches ns. An
+// invalid selector is rejected by the API server, so it is treated as
+// matching.
+func (inv *inventory) intercepts(h hook, ns string) bool {
+	if selectsAll(h.namespaceSelector) {
+		return true
+	}
+	sel, err := metav1.LabelSelectorAsSelector(h.namespaceSelector)
+	if err != nil {
+		return true
+	}
+	return sel.Matches(labels.Set(inv.labelsOf(ns)))
+}
+
+// conditional reports whether an objectSelector or matchConditions narrow
+// the requests a webhook sees beyond its rules and namespaceSelector.
+func (h hook) conditional() bool {
+	return h.matchConditions > 0 || !selectsAll(h.objectSelector)
+}
+
+// describe names the webhook and its configuration.
+func (h hook) describe() string {
+	return fmt.Sprintf("Webhook %s in %s", h.name, h.resource)
+}
+
+// details returns the finding details identifying the webhook.
+func (h hook) details() map[string]string {
+	details := map[string]string{
+		"webhook":        h.name,
+		"failure_policy": string(h.failurePolicy),
+	}
+	if h.service != nil {
+		details["service"] = h.service.Namespace + "/" + h.service.Name
+	}
+	return details
+}
+
+// checkFailOpen reports security-relevant webhooks with failurePolicy
+// Ignore (WHK-001). A validating webhook that fails open admits requests
+// unchecked whenever it is down or slow; a mutating one silently skips the
+// defaults it injects.
+func (a *Analyzer) checkFailOpen(h hook, now time.Time) []scanner.Finding {
+	if h.failurePolicy != admissionregistrationv1.Ignore {
+		return nil
+	}
+	reason := securityRelevance(h)
+	if reason == "" {
+		return nil
+	}
+
+	severity, effect := scanner.SeverityHigh, "requests are admitted without validation"
+	if h.mutating {
+		severity, effect = scanner.SeverityMedium, "requests are admitted without its mutations"
+	}
+	details := h.details()
+	details["matches"] = reason
+	return []scanner.Finding{{
+		ID:          "WHK-001",
+		Title:       "Security webhook fails open",
+		Description: fmt.Sprintf("%s intercepts %s but uses failurePolicy Ignore; whenever the webhook is unavailable or times out, %s", h.describe(), reason, effect),
+		Severity:    severity,
+		Status:      scanner.StatusFail,
+		Category:    "webhooks",
+		Resource:    h.resource,
+		Remediation: "Set failurePolicy: Fail, run the webhook with multiple replicas and a PodDisruptionBudget, and exclude kube-system and the webhook's own namespace with a namespaceSelector so failing closed cannot deadlock the cluster.",
+		Details:     details,
+		Timestamp:   now,
+	}}
+}
+
+// checkSelfInterception reports fail-closed webhooks whose namespaceSelector
+// matches kube-system or the namespace of their own Service (WHK-002). If
+// such a webhook goes down, the pods needed to bring it or the control plane
+// add-ons back cannot be admitted.
+func (a *Analyzer) checkSelfInterception(inv *inventory, h hook, now time.Time) []scanner.Finding {
+	if h.failurePolicy != admissionregistrationv1.Fail || !interceptsNamespaced(h) {
+		return nil
+	}
+
+	status := scanner.StatusFail
+	if h.conditional() {
+		status = scanner.StatusWarning
+	}
+
+	var findings []scanner.Finding
+	if inv.intercepts(h, "kube-system") {
+		details := h.details()
+		details["intercepted_namespace"] = "kube-system"
+		findings = append(findings, scanner.Finding{
+			ID:          "WHK-002",
+			Title:       "Fail-closed webhook intercepts critical namespace",
+			Description: fmt.Sprintf("%s uses failurePolicy Fail and intercepts requests in kube-system; while the webhook is unavailable, control plane add-ons such as CoreDNS and CNI pods cannot be created or updated", h.describe()),
+			Severity:    scanner.SeverityHigh,
+			Status:      status,
+			Category:    "webhooks",
+			Resource:    h.resource,
+			Remediation: "Exclude kube-system from the webhook with a namespaceSelector, e.g. a matchExpression on kubernetes.io/metadata.name NotIn [kube-system].",
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	if h.service != nil && h.service.Namespace != "kube-system" && inv.intercepts(h, h.service.Namespace) {
+		details := h.details()
+		details["intercepted_namespace"] = h.service.Namespace
+		findings = append(findings, scanner.Finding{
+			ID:          "WHK-002",
+			Title:       "Fail-closed webhook intercepts critical namespace",
+			Description: fmt.Sprintf("%s uses failurePolicy Fail and intercepts requests in its own namespace %s; if its pods go down they cannot be recreated, because admitting them requires the webhook", h.describe(), h.service.Namespace),
+			Severity:    scanner.SeverityHigh,
+			Status:      status,
+			Category:    "webhooks",
+			Resource:    h.resource,
+			Remediation: fmt.Sprintf("Exclude namespace %s from the webhook with a namespaceSelector, e.g. a matchExpression on kubernetes.io/metadata.name NotIn [%s].", h.service.Namespace, h.service.Namespace),
+			Details:     details,
+			Timestamp:   now,
+		})
+	}
+
+	return findings
+}
+
+// checkNamespaceSelector reports webhooks matching namespaced objects without
+// a namespaceSelector (WHK-003), which therefore sit in the path of every
+// request in every namespace.
+func (a *Analyzer) checkNamespaceSelector(h hook, now time.Time) []scanner.Finding {
+	if !interceptsNamespaced(h) || !selectsAll(h.namespaceSelector) {
+		return nil
+	}
+	return []scanner.Finding{{
+		ID:          "WHK-003",
+		Title:       "Webhook without namespaceSelector",
+		Description: fmt.Sprintf("%s has no namespaceSelector and intercepts matching requests in every namespace, including system namespaces", h.describe()),
+		Severity:    scanner.SeverityLow,
+		Status:      scanner.StatusWarning,
+		Category:    "webhooks",
+		Resource:    h.resource,
+		Remediation: "Add a namespaceSelector that limits the webhook to the namespaces it governs, or at least excludes kube-system and the webhook's own namespace.",
+		Details:     h.details(),
+		Timestamp:   now,
+	}}
+}
+
+// checkTimeout reports webhooks whose timeout exceeds the configured maximum
+// (WHK-004). Each matching request can block for up to that long, and a
+// fail-closed webhook rejects it at the end.
+func (a *Analyzer) checkTimeout(h hook, now time.Time) []scanner.Finding {
+	if h.timeout <= a.maxTimeout {
+		return nil
+	}
+	severity := scanner.SeverityLow
+	if h.failurePolicy == admissionregistrationv1.Fail {
+		severity = scanner.SeverityMedium
+	}
+	details := h.details()
+	details["timeout_seconds"] = fmt.Sprintf("%d", int(h.timeout.Seconds()))
+	details["max_timeout_seconds"] = fmt.Sprintf("%d", int(a.maxTimeout.Seconds()))
+	return []scanner.Finding{{
+		ID:          "WHK-004",
+		Title:       "Excessive webhook timeout",
+		Description: fmt.Sprintf("%s has a timeout of %s, above the maximum of %s; a slow or unreachable webhook stalls every matching API request for that long", h.describe(), h.timeout, a.maxTimeout),
+		Severity:    severity,
+		Status:      scanner.StatusWarning,
+		Category:    "webhooks",
+		Resource:    h.resource,
+		Remediation: fmt.Sprintf("Lower timeoutSeconds to %d or less and make the webhook respond quickly; admission runs synchronously in the API request path.", int(a.maxTimeout.Seconds())),
+		Details:     details,
+		Timestamp:   now,
+	}}
+}
+
+// checkServiceIsolation reports webhook Services in namespaces that no
+// NetworkPolicy, CiliumNetworkPolicy, CiliumClusterwideNetworkPolicy or Calico
+// GlobalNetworkPolicy applies to (WHK-005). Webhook servers only need to be
+// reachable from the API server; without policies every pod in the cluster
+// can reach them.
+func (a *Analyzer) checkServiceIsolation(ctx context.Context, inv *inventory, now time.Time) []scanner.Finding {
+	webhooksBySvc := make(map[string][]string)
+	for _, h := range inv.hooks {
+		if h.service == nil {
+			continue
+		}
+		key := h.service.Namespace + "/" + h.service.Name
+		ref := h.resource + "/" + h.name
+		if !slices.Contains(webhooksBySvc[key], ref) {
+			webhooksBySvc[key] = append(webhooksBySvc[key], ref)
+		}
+	}
+
+	services := make([]string, 0, len(webhooksBySvc))
+	for key := range webhooksBySvc {
+		services = append(services, key)
+	}
+	sort.Strings(services)
+
+	var namespaces []string
+	for _, key := range services {
+		ns, _, _ := strings.Cut(key, "/")
+		if !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	policies := network.NewAnalyzer(a.client, a.logger).Policies(ctx, namespaces)
+
+	var findings []scanner.Finding
+	for _, key := range services {
+		ns, name, _ := strings.Cut(key, "/")
+		// Namespaces whose policies could not be listed have unknown
+		// coverage and are not reported.
+		if nsPolicies, ok := policies[ns]; !ok || len(nsPolicies) > 0 {
+			continue
+		}
+
+		webhooks := webhooksBySvc[key]
+		findings = append(findings, scanner.Finding{
+			ID:          "WHK-005",
+			Title:       "Webhook Service not isolated by NetworkPolicy",
+			Description: fmt.Sprintf("Service %s backs %d admission webhook(s) but no NetworkPolicy or Cilium or Calico policy applies to namespace %s, so any pod in the cluster can reach the webhook server", key, len(webhooks), ns),
+			Severity:    scanner.SeverityMedium,
+			Status:      scanner.StatusFail,
+			Category:    "webhooks",
+			Resource:    fmt.Sprintf("Service/%s/%s", ns, name),
+			Namespace:   ns,
+			Remediation: fmt.Sprintf("Add a default-deny NetworkPolicy to namespace %s and allow ingress to the webhook pods on the webhook port only from the API server's addresses.", ns),
+			Details: map[string]string{
+				"webhooks": strings.Join(webhooks, ","),
+			},
+			Timestamp: now,
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+func TestSecurityRelevance(t *testing.T) {
+	rules := func(resources ...string) []admissionregistrationv1.RuleWithOperations {
+		return []admissionregistrationv1.RuleWithOperations{{Rule: admissionregistrationv1.Rule{Resources: resources}}}
+	}
+	service := func(namespace string) *admissionregistrationv1.ServiceReference {
+		return &admissionregistrationv1.ServiceReference{Namespace: namespace, Name: "webhook"}
+	}
+
+	tests := []struct {
+		name string
+		hook hook
+		want string
+	}{
+		{name: "all resources", hook: hook{rules: rules("*")}, want: "all resources"},
+		{name: "pod exec", hook: hook{rules: rules("pods/exec")}, want: "pods/exec"},
+		{name: "unrelated resource", hook: hook{rules: rules("certificates"), service: service("cert-manager")}},
+		{name: "Gatekeeper configuration", hook: hook{resource: "ValidatingWebhookConfiguration/gatekeeper-validating-webhook-configuration", rules: rules("constrainttemplates")}, want: "requests checked by the Gatekeeper policy engine"},
+		{name: "Kyverno namespace", hook: hook{resource: "ValidatingWebhookConfiguration/custom", rules: rules("configmaps"), service: service("kyverno")}, want: "requests checked by the Kyverno policy engine"},
+		{name: "name containing policy", hook: hook{resource: "ValidatingWebhookConfiguration/retention-policy", rules: rules("configmaps"), service: service("backup")}},
+		{name: "name containing security", hook: hook{resource: "MutatingWebhookConfiguration/security-labels", rules: rules("configmaps"), service: service("platform")}},
+		{name: "namespace containing policy engine name", hook: hook{resource: "ValidatingWebhookConfiguration/custom", rules: rules("configmaps"), service: service("kyverno-tests")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := securityRelevance(tt.hook); got != tt.want {
+				t.Errorf("securityRelevance() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckServiceIsolation(t *testing.T) {
+	hookFor := func(namespace string) hook {
+		return hook{
+			resource: "ValidatingWebhookConfiguration/" + namespace,
+			name:     namespace + ".example.com",
+			service:  &admissionregistrationv1.ServiceReference{Namespace: namespace, Name: "webhook"},
+		}
+	}
+	inv := &inventory{hooks: []hook{hookFor("open"), hookFor("native"), hookFor("cilium")}}
+
+	policy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "native"}}
+	cnp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"endpointSelector": map[string]interface{}{},
+			"ingress":          []interface{}{},
+		},
+	}}
+	cnp.SetAPIVersion("cilium.io/v2")
+	cnp.SetKind("CiliumNetworkPolicy")
+	cnp.SetNamespace("cilium")
+	cnp.SetName("default-deny")
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "cilium.io", Version: "v2", Resource: "ciliumnetworkpolicies"}:             "CiliumNetworkPolicyList",
+		{Group: "cilium.io", Version: "v2", Resource: "ciliumclusterwidenetworkpolicies"}:  "CiliumClusterwideNetworkPolicyList",
+		{Group: "crd.projectcalico.org", Version: "v1", Resource: "globalnetworkpolicies"}: "GlobalNetworkPolicyList",
+	}
+	client := k8s.NewClientFromInterface(fake.NewSimpleClientset(policy), "test", nil).
+		WithDynamicClient(dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, cnp))
+
+	var got []string
+	for _, f := range NewAnalyzer(client, nil).checkServiceIsolation(context.Background(), inv, time.Now()) {
+		got = append(got, f.ID+" "+f.Resource)
+	}
+	sort.Strings(got)
+	want := []string{"WHK-005 Service/open/webhook"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+}
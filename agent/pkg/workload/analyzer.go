@@ -0,0 +1,208 @@
+// Package workload analyzes pod templates for operational best practices:
+// resource requests and limits, health probes, image references and pull
+// policies.
+package workload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// Analyzer evaluates workloads against best practices.
+// It implements the scanner.Analyzer interface.
+type Analyzer struct {
+	client            *k8s.Client
+	logger            *slog.Logger
+	allowedRegistries []string
+}
+
+// Option configures an Analyzer instance.
+type Option func(*Analyzer)
+
+// WithAllowedRegistries restricts images to the given registries (WKL-007).
+// Entries are registry hosts such as "ghcr.io" or registry path prefixes such
+// as "registry.example.com/platform"; Docker Hub images match "docker.io".
+// Without an allowlist the check is skipped.
+func WithAllowedRegistries(registries ...string) Option {
+	return func(a *Analyzer) {
+		for _, r := range registries {
+			if r = strings.TrimSuffix(strings.TrimSpace(r), "/"); r != "" {
+				a.allowedRegistries = append(a.allowedRegistries, r)
+			}
+		}
+	}
+}
+
+// Name returns the analyzer name.
+func (a *Analyzer) Name() string { return "workload" }
+
+// NewAnalyzer creates a new workload best-practices analyzer.
+func NewAnalyzer(client *k8s.Client, logger *slog.Logger, opts ...Option) *Analyzer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	a := &Analyzer{
+		client: client,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// workload is a controller pod template or an unowned pod.
+type workload struct {
+	resource  string
+	namespace string
+	spec      *corev1.PodSpec
+	// runToCompletion is set for Jobs, CronJobs and pods that are expected
+	// to exit, which need no probes.
+	runToCompletion bool
+}
+
+// Analyze runs all workload checks and returns findings.
+func (a *Analyzer) Analyze(ctx context.Context, namespaces []string) ([]scanner.Finding, error) {
+	a.logger.Info("starting workload analysis")
+
+	now := time.Now()
+	var findings []scanner.Finding
+
+	for _, ns := range namespaces {
+		for _, w := range a.collect(ctx, ns) {
+			// Check 1: Resource requests and limits.
+			findings = append(findings, a.checkResources(w, now)...)
+
+			// Check 2: Liveness and readiness probes.
+			findings = append(findings, a.checkProbes(w, now)...)
+
+			// Check 3: Image tags, digests, registries and pull policies.
+			findings = append(findings, a.checkImages(w, now)...)
+		}
+	}
+
+	a.logger.Info("workload analysis complete", "findings", len(findings))
+	return findings, nil
+}
+
+// collect lists the pod templates of a namespace's controllers and its
+// pods that no listed controller covers. ReplicaSets and Jobs created by a
+// controller are covered by the controller's template; pods are skipped only
+// when their top-level controller is evaluated, so pods of controllers this
+// analyzer does not list, such as Argo Rollouts, are still checked.
+func (a *Analyzer) collect(ctx context.Context, ns string) []workload {
+	var workloads []workload
+	// evaluated holds the "Kind/namespace/name" keys of the listed templates
+	// and owners indexes the controllers of controlled ReplicaSets and Jobs.
+	evaluated := make(map[string]bool)
+	owners := make(k8s.OwnerIndex)
+	add := func(kind, name string, spec *corev1.PodSpec, runToCompletion bool) {
+		resource := fmt.Sprintf("%s/%s/%s", kind, ns, name)
+		evaluated[resource] = true
+		workloads = append(workloads, workload{
+			resource:        resource,
+			namespace:       ns,
+			spec:            spec,
+			runToCompletion: runToCompletion,
+		})
+	}
+	deployments, err := a.client.ListDeployments(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list deployments", "namespace", ns, "error", err)
+	}
+	for i := range deployments {
+		add("Deployment", deployments[i].Name, &deployments[i].Spec.Template.Spec, false)
+	}
+
+	daemonsets, err := a.client.ListDaemonSets(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list daemonsets", "namespace", ns, "error", err)
+	}
+	for i := range daemonsets {
+		add("DaemonSet", daemonsets[i].Name, &daemonsets[i].Spec.Template.Spec, false)
+	}
+
+	statefulsets, err := a.client.ListStatefulSets(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list statefulsets", "namespace", ns, "error", err)
+	}
+	for i := range statefulsets {
+		add("StatefulSet", statefulsets[i].Name, &statefulsets[i].Spec.Template.Spec, false)
+	}
+
+	replicasets, err := a.client.ListReplicaSets(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list replicasets", "namespace", ns, "error", err)
+	}
+	for i := range replicasets {
+		if !owners.Add("ReplicaSet", &replicasets[i]) {
+			add("ReplicaSet", replicasets[i].Name, &replicasets[i].Spec.Template.Spec, false)
+		}
+	}
+
+	rcs, err := a.client.ListReplicationControllers(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list replicationcontrollers", "namespace", ns, "error", err)
+	}
+	for i := range rcs {
+		if rcs[i].Spec.Template != nil {
+			add("ReplicationController", rcs[i].Name, &rcs[i].Spec.Template.Spec, false)
+		}
+	}
+
+	cronjobs, err := a.client.ListCronJobs(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list cronjobs", "namespace", ns, "error", err)
+	}
+	for i := range cronjobs {
+		add("CronJob", cronjobs[i].Name, &cronjobs[i].Spec.JobTemplate.Spec.Template.Spec, true)
+	}
+
+	jobs, err := a.client.ListJobs(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list jobs", "namespace", ns, "error", err)
+	}
+	for i := range jobs {
+		if !owners.Add("Job", &jobs[i]) {
+			add("Job", jobs[i].Name, &jobs[i].Spec.Template.Spec, true)
+		}
+	}
+
+	pods, err := a.client.ListPods(ctx, ns)
+	if err != nil {
+		a.logger.Warn("failed to list pods", "namespace", ns, "error", err)
+	}
+	for i := range pods {
+		if owner := owners.TopLevelOwner(&pods[i]); owner != "" && evaluated[owner] {
+			continue
+		}
+		add("Pod", pods[i].Name, &pods[i].Spec, pods[i].Spec.RestartPolicy != "" && pods[i].Spec.RestartPolicy != corev1.RestartPolicyAlways)
+	}
+
+	return workloads
+}
+
+// containers returns the init and regular containers of a pod spec with a
+// flag telling whether each keeps running alongside the pod.
+func containers(spec *corev1.PodSpec) ([]corev1.Container, []bool) {
+	var all []corev1.Container
+	var longRunning []bool
+	for _, c := range spec.InitContainers {
+		all = append(all, c)
+		// Sidecar containers are init containers that keep running.
+		longRunning = append(longRunning, c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways)
+	}
+	for _, c := range spec.Containers {
+		all = append(all, c)
+		longRunning = append(longRunning, true)
+	}
+	return all, longRunning
+}
@@ -0,0 +1,73 @@
+package workload
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubecomply/kubecomply/pkg/k8s"
+)
+
+// controlledBy returns a controller reference to the named object.
+func controlledBy(apiVersion, kind, name string) []metav1.OwnerReference {
+	yes := true
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, Controller: &yes}}
+}
+
+func TestCollect(t *testing.T) {
+	meta := func(name string, owners []metav1.OwnerReference) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "shop", OwnerReferences: owners}
+	}
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:1.0"}}}}
+	pod := func(name string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: meta(name, owners), Spec: template.Spec}
+	}
+
+	orphaned := pod("web-5c8f2-pqrst", controlledBy("apps/v1", "ReplicaSet", "web-5c8f2"))
+	orphaned.Labels = map[string]string{"pod-template-hash": "5c8f2"}
+
+	objects := []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: meta("web", nil), Spec: appsv1.DeploymentSpec{Template: template}},
+		&appsv1.ReplicaSet{ObjectMeta: meta("web-7d4b9", controlledBy("apps/v1", "Deployment", "web")), Spec: appsv1.ReplicaSetSpec{Template: template}},
+		pod("web-7d4b9-x2x9k", controlledBy("apps/v1", "ReplicaSet", "web-7d4b9")),
+		// The ReplicaSet of an old rollout is gone; the pod-template-hash
+		// label still attributes the pod to the Deployment.
+		orphaned,
+
+		&appsv1.ReplicaSet{ObjectMeta: meta("legacy", nil), Spec: appsv1.ReplicaSetSpec{Template: template}},
+		pod("legacy-abcde", controlledBy("apps/v1", "ReplicaSet", "legacy")),
+
+		&corev1.ReplicationController{ObjectMeta: meta("old", nil), Spec: corev1.ReplicationControllerSpec{Template: &template}},
+		pod("old-fghij", controlledBy("v1", "ReplicationController", "old")),
+
+		// Argo Rollouts are not listed, so their pods are checked directly.
+		&appsv1.ReplicaSet{ObjectMeta: meta("canary-5f6c7", controlledBy("argoproj.io/v1alpha1", "Rollout", "canary")), Spec: appsv1.ReplicaSetSpec{Template: template}},
+		pod("canary-5f6c7-klmno", controlledBy("apps/v1", "ReplicaSet", "canary-5f6c7")),
+
+		pod("debug", nil),
+	}
+	client := k8s.NewClientFromInterface(fake.NewSimpleClientset(objects...), "test", nil)
+
+	var got []string
+	for _, w := range NewAnalyzer(client, nil).collect(context.Background(), "shop") {
+		got = append(got, w.resource)
+	}
+	sort.Strings(got)
+	want := []string{
+		"Deployment/shop/web",
+		"Pod/shop/canary-5f6c7-klmno",
+		"Pod/shop/debug",
+		"ReplicaSet/shop/legacy",
+		"ReplicationController/shop/old",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collect() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package workload
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// checkResources reports containers without CPU/memory requests (WKL-001)
+// or limits (WKL-002). A missing memory limit lets a container exhaust node
+// memory; a missing CPU limit is reported at low severity, since many
+// clusters deliberately rely on requests alone for CPU.
+func (a *Analyzer) checkResources(w workload, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	all, _ := containers(w.spec)
+	for _, c := range all {
+		var missingRequests, missingLimits []string
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := c.Resources.Requests[name]; !ok {
+				// Limits default the request when it is omitted.
+				if _, ok := c.Resources.Limits[name]; !ok {
+					missingRequests = append(missingRequests, string(name))
+				}
+			}
+			if _, ok := c.Resources.Limits[name]; !ok {
+				missingLimits = append(missingLimits, string(name))
+			}
+		}
+
+		if len(missingRequests) > 0 {
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-001",
+				Title:       "Container without resource requests",
+				Description: fmt.Sprintf("Container %q in %s sets no %s request", c.Name, w.resource, strings.Join(missingRequests, " or ")),
+				Severity:    scanner.SeverityMedium,
+				Status:      scanner.StatusFail,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: "Set resources.requests.cpu and resources.requests.memory from observed usage so the scheduler can place the pod and it is not first to be evicted under node pressure.",
+				Details: map[string]string{
+					"container": c.Name,
+					"missing":   strings.Join(missingRequests, ","),
+				},
+				Timestamp: now,
+			})
+		}
+
+		if len(missingLimits) > 0 {
+			severity, status := scanner.SeverityLow, scanner.StatusWarning
+			if _, ok := c.Resources.Limits[corev1.ResourceMemory]; !ok {
+				severity, status = scanner.SeverityMedium, scanner.StatusFail
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-002",
+				Title:       "Container without resource limits",
+				Description: fmt.Sprintf("Container %q in %s sets no %s limit", c.Name, w.resource, strings.Join(missingLimits, " or ")),
+				Severity:    severity,
+				Status:      status,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: "Set resources.limits.memory to bound the container's memory use. Set resources.limits.cpu where noisy neighbours are a concern, or enforce defaults with a LimitRange.",
+				Details: map[string]string{
+					"container": c.Name,
+					"missing":   strings.Join(missingLimits, ","),
+				},
+				Timestamp: now,
+			})
+		}
+	}
+
+	return findings
+}
+
+// checkProbes reports long-running containers without a liveness (WKL-003)
+// or readiness (WKL-004) probe. Run-to-completion workloads and init
+// containers are skipped; sidecars are checked.
+func (a *Analyzer) checkProbes(w workload, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	if w.runToCompletion {
+		return findings
+	}
+
+	all, longRunning := containers(w.spec)
+	for i, c := range all {
+		if !longRunning[i] {
+			continue
+		}
+		if c.LivenessProbe == nil {
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-003",
+				Title:       "Container without liveness probe",
+				Description: fmt.Sprintf("Container %q in %s has no liveness probe, so a hung process is never restarted", c.Name, w.resource),
+				Severity:    scanner.SeverityLow,
+				Status:      scanner.StatusWarning,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: "Add a livenessProbe that fails only when the process cannot recover on its own. Avoid checking downstream dependencies.",
+				Details:     map[string]string{"container": c.Name},
+				Timestamp:   now,
+			})
+		}
+		if c.ReadinessProbe == nil {
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-004",
+				Title:       "Container without readiness probe",
+				Description: fmt.Sprintf("Container %q in %s has no readiness probe, so it receives traffic before it is ready", c.Name, w.resource),
+				Severity:    scanner.SeverityLow,
+				Status:      scanner.StatusWarning,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: "Add a readinessProbe that succeeds once the container can serve requests.",
+				Details:     map[string]string{"container": c.Name},
+				Timestamp:   now,
+			})
+		}
+	}
+
+	return findings
+}
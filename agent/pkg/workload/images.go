@@ -0,0 +1,197 @@
+package workload
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubecomply/kubecomply/pkg/scanner"
+)
+
+// defaultRegistry is the registry of image references without a registry
+// host, as resolved by container runtimes.
+const defaultRegistry = "docker.io"
+
+// imageRef is a parsed container image reference.
+type imageRef struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+// name returns the fully qualified repository, e.g. "docker.io/library/nginx".
+func (r imageRef) name() string {
+	return r.registry + "/" + r.repository
+}
+
+// parseImage splits an image reference into registry, repository, tag and
+// digest, applying the same defaults as container runtimes: references
+// without a registry host resolve to Docker Hub, and single-component Docker
+// Hub repositories live under "library/".
+func parseImage(image string) imageRef {
+	var ref imageRef
+
+	rest := image
+	if i := strings.Index(rest, "@"); i >= 0 {
+		ref.digest = rest[i+1:]
+		rest = rest[:i]
+	}
+	// A tag follows the last colon of the last path component; earlier
+	// colons belong to a registry port.
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		ref.tag = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	first, remainder, found := strings.Cut(rest, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.registry = strings.ToLower(first)
+		ref.repository = remainder
+	} else {
+		ref.registry = defaultRegistry
+		ref.repository = rest
+	}
+	switch ref.registry {
+	case "index.docker.io", "registry-1.docker.io":
+		ref.registry = defaultRegistry
+	}
+	if ref.registry == defaultRegistry && !strings.Contains(ref.repository, "/") {
+		ref.repository = "library/" + ref.repository
+	}
+	return ref
+}
+
+// mutableTag reports whether the reference resolves to whatever "latest"
+// currently points at.
+func (r imageRef) mutableTag() bool {
+	return r.digest == "" && (r.tag == "" || r.tag == "latest")
+}
+
+// allowed reports whether the image matches an allowlist entry. Entries are
+// registry hosts or repository prefixes matched on path boundaries.
+func (a *Analyzer) allowed(ref imageRef) bool {
+	name := ref.name()
+	for _, entry := range a.allowedRegistries {
+		entry = strings.ToLower(entry)
+		if entry == ref.registry || name == entry || strings.HasPrefix(name, entry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImages reports images using latest or no tag (WKL-005), images not
+// pinned by digest (WKL-006), images from registries outside the allowlist
+// (WKL-007) and pull policies that do not fit the image reference (WKL-008).
+func (a *Analyzer) checkImages(w workload, now time.Time) []scanner.Finding {
+	var findings []scanner.Finding
+
+	all, _ := containers(w.spec)
+	for _, c := range all {
+		if c.Image == "" {
+			continue
+		}
+		ref := parseImage(c.Image)
+		details := func() map[string]string {
+			return map[string]string{
+				"container": c.Name,
+				"image":     c.Image,
+				"registry":  ref.registry,
+			}
+		}
+
+		if ref.mutableTag() {
+			desc := fmt.Sprintf("Container %q in %s uses image %q without a tag, which resolves to latest", c.Name, w.resource, c.Image)
+			if ref.tag != "" {
+				desc = fmt.Sprintf("Container %q in %s uses image %q with the latest tag", c.Name, w.resource, c.Image)
+			}
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-005",
+				Title:       "Image uses latest or no tag",
+				Description: desc,
+				Severity:    scanner.SeverityMedium,
+				Status:      scanner.StatusFail,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: "Reference an explicit version tag or digest so every node runs the same image and rollbacks are reproducible.",
+				Details:     details(),
+				Timestamp:   now,
+			})
+		}
+
+		if ref.digest == "" {
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-006",
+				Title:       "Image not pinned by digest",
+				Description: fmt.Sprintf("Container %q in %s references image %q without a digest, so the content it resolves to can change", c.Name, w.resource, c.Image),
+				Severity:    scanner.SeverityLow,
+				Status:      scanner.StatusWarning,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: fmt.Sprintf("Pin the image by digest, e.g. %s@sha256:<digest>, and let your CI or a bot update the digest.", strings.TrimSuffix(c.Image, ":"+ref.tag)),
+				Details:     details(),
+				Timestamp:   now,
+			})
+		}
+
+		if len(a.allowedRegistries) > 0 && !a.allowed(ref) {
+			d := details()
+			d["allowed_registries"] = strings.Join(a.allowedRegistries, ",")
+			findings = append(findings, scanner.Finding{
+				ID:          "WKL-007",
+				Title:       "Image from registry outside allowlist",
+				Description: fmt.Sprintf("Container %q in %s pulls %q from %s, which is not an allowed registry", c.Name, w.resource, c.Image, ref.registry),
+				Severity:    scanner.SeverityHigh,
+				Status:      scanner.StatusFail,
+				Category:    "workload",
+				Resource:    w.resource,
+				Namespace:   w.namespace,
+				Remediation: "Mirror the image into an approved registry and reference it from there, or extend the allowlist if the registry is trusted. Enforce the allowlist at admission with a policy engine.",
+				Details:     d,
+				Timestamp:   now,
+			})
+		}
+
+		if f, ok := checkPullPolicy(w, c, ref, now); ok {
+			f.Details = details()
+			f.Details["image_pull_policy"] = string(c.ImagePullPolicy)
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
+
+// checkPullPolicy reports an imagePullPolicy that contradicts the image
+// reference. An empty policy is defaulted by the API server to match the
+// reference and is never reported.
+func checkPullPolicy(w workload, c corev1.Container, ref imageRef, now time.Time) (scanner.Finding, bool) {
+	finding := scanner.Finding{
+		ID:        "WKL-008",
+		Title:     "Inconsistent imagePullPolicy",
+		Severity:  scanner.SeverityLow,
+		Status:    scanner.StatusWarning,
+		Category:  "workload",
+		Resource:  w.resource,
+		Namespace: w.namespace,
+		Timestamp: now,
+	}
+
+	switch {
+	case ref.mutableTag() && (c.ImagePullPolicy == corev1.PullIfNotPresent || c.ImagePullPolicy == corev1.PullNever):
+		finding.Severity = scanner.SeverityMedium
+		finding.Description = fmt.Sprintf("Container %q in %s uses the mutable image %q with imagePullPolicy %s, so nodes keep running whichever version they cached first", c.Name, w.resource, c.Image, c.ImagePullPolicy)
+		finding.Remediation = "Use an immutable tag or digest. If the image must stay mutable, set imagePullPolicy: Always."
+	case c.ImagePullPolicy == corev1.PullNever && ref.digest == "":
+		finding.Description = fmt.Sprintf("Container %q in %s uses imagePullPolicy Never with image %q, so pods fail on nodes that do not already have it and the cached content is never verified", c.Name, w.resource, c.Image)
+		finding.Remediation = "Use imagePullPolicy: IfNotPresent, or pin the pre-loaded image by digest."
+	default:
+		return scanner.Finding{}, false
+	}
+	return finding, true
+}
@@ -0,0 +1,77 @@
+package workload
+
+import "testing"
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  imageRef
+	}{
+		{"nginx", imageRef{registry: "docker.io", repository: "library/nginx"}},
+		{"nginx:1.27", imageRef{registry: "docker.io", repository: "library/nginx", tag: "1.27"}},
+		{"bitnami/redis:7.2", imageRef{registry: "docker.io", repository: "bitnami/redis", tag: "7.2"}},
+		{"docker.io/nginx:latest", imageRef{registry: "docker.io", repository: "library/nginx", tag: "latest"}},
+		{"index.docker.io/library/nginx", imageRef{registry: "docker.io", repository: "library/nginx"}},
+		{"ghcr.io/org/app:v1", imageRef{registry: "ghcr.io", repository: "org/app", tag: "v1"}},
+		{"GHCR.IO/org/app", imageRef{registry: "ghcr.io", repository: "org/app"}},
+		{"registry.example.com:5000/team/app", imageRef{registry: "registry.example.com:5000", repository: "team/app"}},
+		{"registry.example.com:5000/team/app:2.0", imageRef{registry: "registry.example.com:5000", repository: "team/app", tag: "2.0"}},
+		{"localhost/app:dev", imageRef{registry: "localhost", repository: "app", tag: "dev"}},
+		{"nginx@sha256:abc123", imageRef{registry: "docker.io", repository: "library/nginx", digest: "sha256:abc123"}},
+		{"ghcr.io/org/app:v1@sha256:abc123", imageRef{registry: "ghcr.io", repository: "org/app", tag: "v1", digest: "sha256:abc123"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := parseImage(tt.image); got != tt.want {
+				t.Errorf("parseImage(%q) = %+v, want %+v", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMutableTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"nginx", true},
+		{"nginx:latest", true},
+		{"nginx:1.27", false},
+		{"nginx@sha256:abc123", false},
+		{"nginx:latest@sha256:abc123", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := parseImage(tt.image).mutableTag(); got != tt.want {
+				t.Errorf("mutableTag(%q) = %t, want %t", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	a := NewAnalyzer(nil, nil, WithAllowedRegistries("ghcr.io", "registry.example.com/platform/", " docker.io/library "))
+
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"ghcr.io/org/app:v1", true},
+		{"registry.example.com/platform/api:1.0", true},
+		{"registry.example.com/platform-tools/api:1.0", false},
+		{"registry.example.com/other/api:1.0", false},
+		{"nginx:1.27", true},
+		{"bitnami/redis:7.2", false},
+		{"quay.io/org/app:v1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := a.allowed(parseImage(tt.image)); got != tt.want {
+				t.Errorf("allowed(%q) = %t, want %t", tt.image, got, tt.want)
+			}
+		})
+	}
+}